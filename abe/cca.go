@@ -0,0 +1,273 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"golang.org/x/crypto/sha3"
+)
+
+// The ABE schemes are only secure against chosen plaintext attacks.
+// EncryptCCA and DecryptCCA make them secure against chosen ciphertext
+// attacks with the transform of Fujisaki and Okamoto:
+// "Secure Integration of Asymmetric and Symmetric Encryption Schemes"
+// https://link.springer.com/article/10.1007/s00145-011-9114-1
+//
+// The randomness of the encapsulation of a random element of GT is
+// derived from the element and the message. After decrypting the
+// message, DecryptCCA encapsulates the recovered element again with
+// the same randomness and rejects the ciphertext unless the result
+// matches the received ciphertext. Hence any modification of the
+// ciphertext is detected, and the decryption of a modified ciphertext
+// reveals nothing but ErrInvalidCipher.
+
+// ErrInvalidCipher is returned by DecryptCCA of the ABE schemes when
+// the ciphertext was not produced by EncryptCCA or was modified, and
+// also when it cannot be decrypted with the given keys, since the two
+// cases cannot be told apart.
+var ErrInvalidCipher = errors.New("abe: invalid ciphertext")
+
+// ccaSampler samples values from [0, max) using the randomness
+// derived by the Fujisaki-Okamoto transform.
+type ccaSampler struct {
+	coins io.Reader
+	max   *big.Int
+}
+
+// newCCASampler returns a sampler for the encapsulation of keyGt in the
+// ciphertext of type info encrypting msg, which derives its randomness
+// from SHAKE256.
+func newCCASampler(keyGt *bn256.GT, info string, msg []byte, max *big.Int) *ccaSampler {
+	h := sha3.NewShake256()
+	h.Write([]byte("gofe CCA " + info))
+	h.Write(keyGt.Marshal())
+	h.Write(msg)
+
+	return &ccaSampler{coins: h, max: max}
+}
+
+// Sample samples a value from [0, max).
+func (s *ccaSampler) Sample() (*big.Int, error) {
+	return rand.Int(s.coins, s.max)
+}
+
+// EncryptCCA is like Encrypt, but the ciphertext can only be decrypted
+// by DecryptCCA, which rejects modified ciphertexts.
+func (a *FAME) EncryptCCA(msg string, msp *MSP, pk *FAMEPubKey) (*FAMECipher, error) {
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sampler := newCCASampler(keyGt, "abe.FAMECipher", []byte(msg), a.P)
+	cipher, err := a.encapsulateWith(msp, pk, keyGt, sampler)
+	if err != nil {
+		return nil, err
+	}
+	cipher.Iv, err = newNonce()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymVersion = SymVersionCCA
+	header, err := cipher.header()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymEnc, err = sealSym(keyGt, "abe.FAMECipher", cipher.Iv, []byte(msg), header)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher, nil
+}
+
+// DecryptCCA decrypts a ciphertext produced by EncryptCCA using the
+// attribute keys key and the public key pk. ErrInvalidCipher is
+// returned if the ciphertext was modified or the keys are not
+// sufficient for the decryption, since a modified policy might not be
+// satisfied by the keys.
+func (a *FAME) DecryptCCA(cipher *FAMECipher, key *FAMEAttribKeys, pk *FAMEPubKey) (string, error) {
+	if cipher == nil || cipher.SymVersion != SymVersionCCA || a.checkCipher(cipher) != nil {
+		return "", ErrInvalidCipher
+	}
+	keyGt, err := a.decapsulate(cipher, key)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+	msg, err := openSym(SymVersionGCM, keyGt, "abe.FAMECipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+
+	// check that the ciphertext is the encapsulation of keyGt
+	// with the randomness derived from the message
+	sampler := newCCASampler(keyGt, "abe.FAMECipher", msg, a.P)
+	check, err := a.encapsulateWith(cipher.Msp, pk, keyGt, sampler)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+	check.Iv, check.SymVersion = cipher.Iv, cipher.SymVersion
+	if err := checkCCA(cipher.header, check.header); err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
+
+// EncryptCCA is like Encrypt, but the ciphertext can only be decrypted
+// by DecryptCCA, which rejects modified ciphertexts.
+func (a *GPSW) EncryptCCA(msg string, gamma interface{}, pk *GPSWPubKey) (*GPSWCipher, error) {
+	gammaI, err := gpswAttribs(gamma)
+	if err != nil {
+		return nil, err
+	}
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sampler := newCCASampler(keyGt, "abe.GPSWCipher", []byte(msg), a.Params.P)
+	cipher, err := a.encapsulateWith(gammaI, pk, keyGt, sampler)
+	if err != nil {
+		return nil, err
+	}
+	cipher.Iv, err = newNonce()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymVersion = SymVersionCCA
+	header, err := cipher.header()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymEnc, err = sealSym(keyGt, "abe.GPSWCipher", cipher.Iv, []byte(msg), header)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher, nil
+}
+
+// DecryptCCA decrypts a ciphertext produced by EncryptCCA using the
+// policy key key and the public key pk. ErrInvalidCipher is returned if
+// the ciphertext was modified or the key is not sufficient for the
+// decryption, since modified attributes might not satisfy the policy
+// of the key.
+func (a *GPSW) DecryptCCA(cipher *GPSWCipher, key *GPSWKey, pk *GPSWPubKey) (string, error) {
+	if cipher == nil || cipher.SymVersion != SymVersionCCA || a.checkCipher(cipher) != nil {
+		return "", ErrInvalidCipher
+	}
+	keyGt, err := a.decapsulate(cipher, key)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+	msg, err := openSym(SymVersionGCM, keyGt, "abe.GPSWCipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+
+	sampler := newCCASampler(keyGt, "abe.GPSWCipher", msg, a.Params.P)
+	check, err := a.encapsulateWith(cipher.Gamma, pk, keyGt, sampler)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+	check.Iv, check.SymVersion = cipher.Iv, cipher.SymVersion
+	if err := checkCCA(cipher.header, check.header); err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
+
+// EncryptCCA is like Encrypt, but the ciphertext can only be decrypted
+// by DecryptCCA, which rejects modified ciphertexts.
+func (d *DIPPE) EncryptCCA(msg string, x data.Vector, pubKeys []*DIPPEPubKey) (*DIPPECipher, error) {
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sampler := newCCASampler(keyGt, "abe.DIPPECipher", []byte(msg), d.P)
+	cipher, err := d.encapsulateWith(x, pubKeys, keyGt, sampler)
+	if err != nil {
+		return nil, err
+	}
+	cipher.Iv, err = newNonce()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymVersion = SymVersionCCA
+	header, err := cipher.header()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymEnc, err = sealSym(keyGt, "abe.DIPPECipher", cipher.Iv, []byte(msg), header)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher, nil
+}
+
+// DecryptCCA decrypts a ciphertext produced by EncryptCCA using the key
+// shares keys of the user with vector v and global identifier gid, and
+// the public keys of the authorities. ErrInvalidCipher is returned if
+// the ciphertext was modified or the keys are not sufficient for the
+// decryption, which cannot be distinguished in DIPPE.
+func (d *DIPPE) DecryptCCA(cipher *DIPPECipher, keys []data.VectorG2, v data.Vector, gid string, pubKeys []*DIPPEPubKey) (string, error) {
+	if cipher == nil || cipher.SymVersion != SymVersionCCA || d.checkCipher(cipher) != nil {
+		return "", ErrInvalidCipher
+	}
+	keyGt, err := d.decapsulate(cipher, keys, v, gid)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+	msg, err := openSym(SymVersionGCM, keyGt, "abe.DIPPECipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+
+	sampler := newCCASampler(keyGt, "abe.DIPPECipher", msg, d.P)
+	check, err := d.encapsulateWith(cipher.X, pubKeys, keyGt, sampler)
+	if err != nil {
+		return "", ErrInvalidCipher
+	}
+	check.Iv, check.SymVersion = cipher.Iv, cipher.SymVersion
+	if err := checkCCA(cipher.header, check.header); err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
+
+// checkCCA compares the header of a received ciphertext with the header
+// of the ciphertext encapsulated again during the decryption.
+func checkCCA(header, check func() ([]byte, error)) error {
+	h, err := header()
+	if err != nil {
+		return ErrInvalidCipher
+	}
+	c, err := check()
+	if err != nil || !bytes.Equal(h, c) {
+		return ErrInvalidCipher
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFAME_CCA(t *testing.T) {
+	a := NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master keys generation: %v", err)
+	}
+	msp, err := BooleanToMSP("a AND (b OR c)", false)
+	if err != nil {
+		t.Fatalf("Error during policy generation: %v", err)
+	}
+	keys, err := a.GenerateAttribKeys([]string{"a", "b"}, secKey)
+	if err != nil {
+		t.Fatalf("Error during keys generation: %v", err)
+	}
+	msg := "Attack at dawn!"
+	ct, err := a.EncryptCCA(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	assert.Equal(t, SymVersionCCA, ct.SymVersion)
+
+	dec, err := a.DecryptCCA(ct, keys, pubKey)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, msg, dec)
+
+	// the ciphertexts of Encrypt and EncryptCCA are not interchangeable
+	_, err = a.Decrypt(ct, keys, pubKey)
+	assert.Error(t, err)
+	ctCPA, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	_, err = a.DecryptCCA(ctCPA, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+
+	// modified ciphertexts are rejected
+	tampered := *ct
+	tampered.Ct = append([][3]*bn256.G1{}, ct.Ct...)
+	tampered.Ct[2][0] = new(bn256.G1).Add(ct.Ct[2][0], new(bn256.G1).ScalarBaseMult(big.NewInt(1)))
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.SymEnc = append([]byte{}, ct.SymEnc...)
+	tampered.SymEnc[0] ^= 1
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.Msp = &MSP{P: ct.Msp.P, Mat: ct.Msp.Mat, RowToAttrib: []string{"a", "b", "b"}}
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered.Msp = &MSP{P: ct.Msp.P, Mat: ct.Msp.Mat, RowToAttrib: []string{"a", "d", "c"}}
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered.Msp = &MSP{P: ct.Msp.P, Mat: ct.Msp.Mat, RowToAttrib: ct.Msp.RowToAttrib[:2]}
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.Ct = ct.Ct[:2]
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.Ct0[1] = nil
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.CtPrime = nil
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.Msp = nil
+	_, err = a.DecryptCCA(&tampered, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+
+	// a ciphertext with valid symmetric encryption that was not
+	// encapsulated with the randomness derived from the message
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	forged, err := a.encapsulateWith(msp, pubKey, keyGt, sample.NewUniform(a.P))
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	forged.Iv, forged.SymVersion = ct.Iv, SymVersionCCA
+	header, err := forged.header()
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	forged.SymEnc, err = sealSym(keyGt, "abe.FAMECipher", forged.Iv, []byte(msg), header)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	_, err = a.DecryptCCA(forged, keys, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+}
+
+func TestGPSW_CCA(t *testing.T) {
+	a := NewGPSW(5)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master keys generation: %v", err)
+	}
+	msp, err := BooleanToMSP("0 AND (1 OR 4)", true)
+	if err != nil {
+		t.Fatalf("Error during policy generation: %v", err)
+	}
+	key, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Error during key generation: %v", err)
+	}
+	msg := "Attack at dawn!"
+	ct, err := a.EncryptCCA(msg, []string{"0", "1", "3"}, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+
+	dec, err := a.DecryptCCA(ct, key, pubKey)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, msg, dec)
+
+	tampered := *ct
+	tampered.E = append(data.VectorG2{}, ct.E...)
+	tampered.E[2] = new(bn256.G2).Add(ct.E[2], new(bn256.G2).ScalarBaseMult(big.NewInt(1)))
+	_, err = a.DecryptCCA(&tampered, key, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.Gamma = []int{0, 1, 4}
+	tampered.AttribToI = map[int]int{0: 0, 1: 1, 4: 2}
+	_, err = a.DecryptCCA(&tampered, key, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered.Gamma = []int{0, 3, 4}
+	tampered.AttribToI = map[int]int{0: 0, 3: 1, 4: 2}
+	_, err = a.DecryptCCA(&tampered, key, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered.Gamma = []int{0, 1, 3, 4}
+	_, err = a.DecryptCCA(&tampered, key, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.E0 = nil
+	_, err = a.DecryptCCA(&tampered, key, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+
+	ct, err = a.EncryptCCA(msg, []int{1, 3, 4}, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	_, err = a.DecryptCCA(ct, key, pubKey)
+	assert.Equal(t, ErrInvalidCipher, err)
+}
+
+func TestDIPPE_CCA(t *testing.T) {
+	d, err := NewDIPPE(2)
+	if err != nil {
+		t.Fatalf("Error during scheme generation: %v", err)
+	}
+	auth := make([]*DIPPEAuth, 2)
+	pubKeys := make([]*DIPPEPubKey, 2)
+	for i := range auth {
+		auth[i], err = d.NewDIPPEAuth(i)
+		if err != nil {
+			t.Fatalf("Error during authority generation: %v", err)
+		}
+		pubKeys[i] = &auth[i].Pk
+	}
+	policyVec := data.Vector{big.NewInt(1), big.NewInt(-1)}
+	userVec := data.Vector{big.NewInt(1), big.NewInt(1)}
+	keys := make([]data.VectorG2, 2)
+	for i := range auth {
+		keys[i], err = auth[i].DeriveKeyShare(userVec, pubKeys, "gid")
+		if err != nil {
+			t.Fatalf("Error during key generation: %v", err)
+		}
+	}
+	msg := "Attack at dawn!"
+	ct, err := d.EncryptCCA(msg, policyVec, pubKeys)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+
+	dec, err := d.DecryptCCA(ct, keys, userVec, "gid", pubKeys)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, msg, dec)
+
+	_, err = d.DecryptCCA(ct, keys, userVec, "other", pubKeys)
+	assert.Equal(t, ErrInvalidCipher, err)
+
+	tampered := *ct
+	tampered.C0 = append(data.VectorG1{}, ct.C0...)
+	tampered.C0[0] = new(bn256.G1).Add(ct.C0[0], new(bn256.G1).ScalarBaseMult(big.NewInt(1)))
+	_, err = d.DecryptCCA(&tampered, keys, userVec, "gid", pubKeys)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.X = data.Vector{big.NewInt(2), big.NewInt(-2)}
+	_, err = d.DecryptCCA(&tampered, keys, userVec, "gid", pubKeys)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered.X = data.Vector{big.NewInt(1), big.NewInt(1)}
+	_, err = d.DecryptCCA(&tampered, keys, userVec, "gid", pubKeys)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered.X = data.Vector{big.NewInt(1), big.NewInt(-1), big.NewInt(0)}
+	_, err = d.DecryptCCA(&tampered, keys, userVec, "gid", pubKeys)
+	assert.Equal(t, ErrInvalidCipher, err)
+	tampered = *ct
+	tampered.C = ct.C[:1]
+	_, err = d.DecryptCCA(&tampered, keys, userVec, "gid", pubKeys)
+	assert.Equal(t, ErrInvalidCipher, err)
+}
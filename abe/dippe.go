@@ -17,16 +17,11 @@
 package abe
 
 import (
-	"crypto/aes"
-	cbc "crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"fmt"
 	"math/big"
 	"strconv"
 
-	"io"
-
 	"github.com/fentec-project/bn256"
 	"github.com/fentec-project/gofe/data"
 	"github.com/fentec-project/gofe/sample"
@@ -66,12 +61,13 @@ type DIPPEAuth struct {
 
 // DIPPECipher represents a ciphertext in DIPPE scheme
 type DIPPECipher struct {
-	C0     data.VectorG1
-	C      data.MatrixG1
-	CPrime *bn256.GT
-	X      data.Vector // policy vector
-	SymEnc []byte      // symmetric encryption of the message
-	Iv     []byte      // initialization vector for symmetric encryption
+	C0         data.VectorG1
+	C          data.MatrixG1
+	CPrime     *bn256.GT
+	X          data.Vector // policy vector
+	SymEnc     []byte      // symmetric encryption of the message
+	Iv         []byte      // initialization vector or nonce for symmetric encryption
+	SymVersion int         // symmetric encryption used, see SymVersionGCM
 }
 
 // NewDIPPE configures a new instance of the scheme. The input parameter
@@ -156,49 +152,59 @@ func (d *DIPPE) NewDIPPEAuth(id int) (*DIPPEAuth, error) {
 // id i. It returns an encryption of msg. In case of a failed procedure an
 // error is returned.
 func (d *DIPPE) Encrypt(msg string, x data.Vector, pubKeys []*DIPPEPubKey) (*DIPPECipher, error) {
-	// msg is encrypted using CBC, with a random key that is encapsulated
-	// with DIPPE
-	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	// msg is encrypted using AES-GCM, with a key derived from a random
+	// element of GT that is encapsulated with DIPPE
+	cipher, keyGt, err := d.encapsulate(x, pubKeys)
 	if err != nil {
 		return nil, err
 	}
-	keyCBC := sha256.Sum256([]byte(keyGt.String()))
-
-	a, err := aes.NewCipher(keyCBC[:])
+	cipher.Iv, err = newNonce()
 	if err != nil {
 		return nil, err
 	}
-
-	iv := make([]byte, a.BlockSize())
-	_, err = io.ReadFull(rand.Reader, iv)
+	cipher.SymVersion = SymVersionGCM
+	header, err := cipher.header()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymEnc, err = sealSym(keyGt, "abe.DIPPECipher", cipher.Iv, []byte(msg), header)
 	if err != nil {
 		return nil, err
 	}
-	encrypterCBC := cbc.NewCBCEncrypter(a, iv)
 
-	msgByte := []byte(msg)
-	// message is padded according to pkcs7 standard
-	padLen := a.BlockSize() - (len(msgByte) % a.BlockSize())
-	msgPad := make([]byte, len(msgByte)+padLen)
-	copy(msgPad, msgByte)
-	for i := len(msgByte); i < len(msgPad); i++ {
-		msgPad[i] = byte(padLen)
+	return cipher, nil
+}
+
+// encapsulate encapsulates a random element of GT with DIPPE under the
+// policy vector x. It returns a ciphertext without the symmetric
+// encryption of a message and the element of GT, from which the key
+// for the symmetric encryption is derived.
+func (d *DIPPE) encapsulate(x data.Vector, pubKeys []*DIPPEPubKey) (*DIPPECipher, *bn256.GT, error) {
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipher, err := d.encapsulateWith(x, pubKeys, keyGt, sample.NewUniform(bn256.Order))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	symEnc := make([]byte, len(msgPad))
-	encrypterCBC.CryptBlocks(symEnc, msgPad)
+	return cipher, keyGt, nil
+}
 
-	// encapsulate the key with DIPPE
-	sampler := sample.NewUniform(bn256.Order)
+// encapsulateWith encapsulates the element keyGt of GT with DIPPE under
+// the policy vector x, taking the randomness of the encapsulation from
+// sampler.
+func (d *DIPPE) encapsulateWith(x data.Vector, pubKeys []*DIPPEPubKey, keyGt *bn256.GT, sampler sample.Sampler) (*DIPPECipher, error) {
+	if len(x) != len(pubKeys) {
+		return nil, fmt.Errorf("the length of the policy vector does not match the number of public keys")
+	}
 	s, err := data.NewRandomVector(d.secLevel, sampler)
 	if err != nil {
 		return nil, err
 	}
 
 	c0 := d.G1ToA.MulVector(s)
-	if err != nil {
-		return nil, err
-	}
 
 	c := make(data.MatrixG1, len(x))
 	for i := range x {
@@ -214,7 +220,7 @@ func (d *DIPPE) Encrypt(msg string, x data.Vector, pubKeys []*DIPPEPubKey) (*DIP
 	}
 	cPrime.Add(keyGt, cPrime)
 
-	return &DIPPECipher{C0: c0, C: c, CPrime: cPrime, X: x.Copy(), SymEnc: symEnc, Iv: iv}, nil
+	return &DIPPECipher{C0: c0, C: c, CPrime: cPrime, X: x.Copy()}, nil
 }
 
 // DeriveKeyShare allows an authority to give a partial decryption key. Collecting all
@@ -272,24 +278,44 @@ func (a *DIPPEAuth) DeriveKeyShare(v data.Vector, pubKeys []*DIPPEPubKey, gid st
 // If the provided keys are correct and the inner product v times x = 0 for the policy
 // x, the message is decrypted, otherwise an error is returned.
 func (d *DIPPE) Decrypt(cipher *DIPPECipher, keys []data.VectorG2, v data.Vector, gid string) (string, error) {
+	keyGt, err := d.decapsulate(cipher, keys, v, gid)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := openSym(cipher.SymVersion, keyGt, "abe.DIPPECipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
+
+// decapsulate recovers the element of GT encapsulated in the cipher,
+// from which the key for the symmetric encryption is derived.
+func (d *DIPPE) decapsulate(cipher *DIPPECipher, keys []data.VectorG2, v data.Vector, gid string) (*bn256.GT, error) {
+	if err := d.checkCipher(cipher); err != nil {
+		return nil, err
+	}
+
 	// check if the decryption is possible
 	prod, err := v.Dot(cipher.X)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if prod.Sign() != 0 {
-		return "", fmt.Errorf("insufficient keys")
+		return nil, fmt.Errorf("insufficient keys")
 	}
 
-	// use DIPPE decryption procedure to get a CBC key
-	// needed for the decryption of the message
+	// use DIPPE decryption procedure to get the element of GT from
+	// which the key for the decryption of the message is derived
 	gTToAlphaAS := new(bn256.GT).ScalarBaseMult(big.NewInt(0))
 
 	ones := data.NewConstantMatrix(1, len(keys), big.NewInt(1))
 	sum, err := ones.MatMulMatG2(data.MatrixG2(keys))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	for i, e := range cipher.C0 {
@@ -301,13 +327,13 @@ func (d *DIPPE) Decrypt(cipher *DIPPECipher, keys []data.VectorG2, v data.Vector
 	vMat[0] = v
 	cSum, err := vMat.MatMulMatG1(cipher.C)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	for j := range cSum[0] {
 		hashed, err := bn256.HashG2(strconv.Itoa(j) + gid + v.String())
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		tmpGT := bn256.Pair(cSum[0][j], hashed)
@@ -317,25 +343,36 @@ func (d *DIPPE) Decrypt(cipher *DIPPECipher, keys []data.VectorG2, v data.Vector
 
 	keyGt := new(bn256.GT).Add(cipher.CPrime, gTToAlphaAS)
 
-	keyCBC := sha256.Sum256([]byte(keyGt.String()))
+	return keyGt, nil
+}
 
-	a, err := aes.NewCipher(keyCBC[:])
-	if err != nil {
-		return "", err
+// checkCipher checks that all the elements of the cipher are present
+// and that their number matches the policy vector and the parameters
+// of the scheme, so that a malformed cipher, possibly decoded from
+// untrusted data, is rejected before it is used in the decryption.
+func (d *DIPPE) checkCipher(cipher *DIPPECipher) error {
+	faulty := fmt.Errorf("the provided cipher is faulty")
+	if cipher == nil || cipher.CPrime == nil || len(cipher.C0) != len(d.G1ToA) ||
+		len(cipher.C) != len(cipher.X) {
+		return faulty
+	}
+	for _, e := range cipher.C0 {
+		if e == nil {
+			return faulty
+		}
 	}
-
-	msgPad := make([]byte, len(cipher.SymEnc))
-	decrypter := cbc.NewCBCDecrypter(a, cipher.Iv)
-	decrypter.CryptBlocks(msgPad, cipher.SymEnc)
-
-	// unpad the message
-	padLen := int(msgPad[len(msgPad)-1])
-	if (len(msgPad) - padLen) < 0 {
-		return "", fmt.Errorf("failed to decrypt")
+	for i, row := range cipher.C {
+		if len(row) != len(cipher.C0) || cipher.X[i] == nil {
+			return faulty
+		}
+		for _, e := range row {
+			if e == nil {
+				return faulty
+			}
+		}
 	}
-	msgByte := msgPad[0:(len(msgPad) - padLen)]
 
-	return string(msgByte), nil
+	return nil
 }
 
 // ExactThresholdPolicyVecInit is used for the transformation of the DIPPE
@@ -21,13 +21,9 @@ import (
 
 	"fmt"
 	"strconv"
+	"strings"
 
-	"crypto/aes"
-	cbc "crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
-
-	"io"
 
 	"github.com/fentec-project/bn256"
 	"github.com/fentec-project/gofe/data"
@@ -49,12 +45,66 @@ import (
 
 // FAME represents a FAME scheme.
 type FAME struct {
-	P *big.Int // order of the elliptic curve
+	P      *big.Int // order of the elliptic curve
+	Copies int      // bound on the rows of a policy with the same attribute
 }
 
-// NewFAME configures a new instance of the scheme.
+// NewFAME configures a new instance of the scheme, in which each
+// attribute can appear in at most one row of a policy.
 func NewFAME() *FAME {
-	return &FAME{P: bn256.Order}
+	return &FAME{P: bn256.Order, Copies: 1}
+}
+
+// NewFAMEWithCopies configures a new instance of the scheme, in which
+// each attribute can appear in up to copies rows of a policy, so that
+// policies like "(A AND B) OR (A AND C)" can be used. The rows mapped
+// to the same attribute are treated as distinct copies of it, and the
+// keys generated for an attribute include a key for each of the
+// copies, thus their size grows linearly with copies.
+func NewFAMEWithCopies(copies int) *FAME {
+	return &FAME{P: bn256.Order, Copies: copies}
+}
+
+// copies returns the number of copies of each attribute.
+func (a *FAME) copies() int {
+	if a.Copies < 1 {
+		return 1
+	}
+
+	return a.Copies
+}
+
+// attribCopy returns the label of the j-th copy of the attribute. The
+// first copy is the attribute itself, so that the scheme with a single
+// copy is unchanged.
+func attribCopy(attrib string, j int) string {
+	if j == 0 {
+		return attrib
+	}
+
+	return attrib + "\x00" + strconv.Itoa(j)
+}
+
+// copyRowToAttrib maps the rows of a policy to the copies of the
+// attributes, the i-th row mapped to an attribute getting its i-th
+// copy. It returns an error if an attribute is mapped to more than
+// copies rows.
+func copyRowToAttrib(rowToAttrib []string, copies int) ([]string, error) {
+	count := make(map[string]int)
+	labels := make([]string, len(rowToAttrib))
+	for i, at := range rowToAttrib {
+		if strings.Contains(at, "\x00") {
+			return nil, fmt.Errorf("attribute %q contains a null character", at)
+		}
+		if count[at] == copies {
+			return nil, fmt.Errorf("attribute %s corresponds to more than %d rows "+
+				"of the MSP struct, the scheme is not secure", at, copies)
+		}
+		labels[i] = attribCopy(at, count[at])
+		count[at]++
+	}
+
+	return labels, nil
 }
 
 // FAMESecKey represents a master secret key of a FAME scheme.
@@ -96,124 +146,150 @@ func (a *FAME) GenerateMasterKeys() (*FAMEPubKey, *FAMESecKey, error) {
 
 // FAMECipher represents a ciphertext of a FAME scheme.
 type FAMECipher struct {
-	Ct0     [3]*bn256.G2
-	Ct      [][3]*bn256.G1
-	CtPrime *bn256.GT
-	Msp     *MSP
-	SymEnc  []byte // symmetric encryption of the message
-	Iv      []byte // initialization vector for symmetric encryption
+	Ct0        [3]*bn256.G2
+	Ct         [][3]*bn256.G1
+	CtPrime    *bn256.GT
+	Msp        *MSP
+	SymEnc     []byte // symmetric encryption of the message
+	Iv         []byte // initialization vector or nonce for symmetric encryption
+	SymVersion int    // symmetric encryption used, see SymVersionGCM
 }
 
 // Encrypt takes as an input a message msg represented as an element of an elliptic
 // curve, a MSP struct representing the decryption policy, and a public key pk. It
 // returns an encryption of the message. In case of a failed procedure an error
-// is returned. Note that safety of the encryption is only proved if the mapping
-// msp.RowToAttrib from the rows of msp.Mat to attributes is injective.
+// is returned. An attribute can be mapped to at most a.Copies rows of msp.Mat,
+// see NewFAMEWithCopies.
 func (a *FAME) Encrypt(msg string, msp *MSP, pk *FAMEPubKey) (*FAMECipher, error) {
-	if len(msp.Mat) == 0 || len(msp.Mat[0]) == 0 {
-		return nil, fmt.Errorf("empty msp matrix")
-	}
-
-	attrib := make(map[string]bool)
-	for _, i := range msp.RowToAttrib {
-		if attrib[i] {
-			return nil, fmt.Errorf("some attributes correspond to" +
-				"multiple rows of the MSP struct, the scheme is not secure")
-		}
-		attrib[i] = true
+	// msg is encrypted using AES-GCM, with a key derived from a random
+	// element of GT that is encapsulated with FAME
+	cipher, keyGt, err := a.encapsulate(msp, pk)
+	if err != nil {
+		return nil, err
 	}
-
-	// msg is encrypted using CBC, with a random key that is encapsulated
-	// with FAME
-	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	cipher.Iv, err = newNonce()
 	if err != nil {
 		return nil, err
 	}
-	keyCBC := sha256.Sum256([]byte(keyGt.String()))
-
-	c, err := aes.NewCipher(keyCBC[:])
+	cipher.SymVersion = SymVersionGCM
+	header, err := cipher.header()
 	if err != nil {
 		return nil, err
 	}
-
-	iv := make([]byte, c.BlockSize())
-	_, err = io.ReadFull(rand.Reader, iv)
+	cipher.SymEnc, err = sealSym(keyGt, "abe.FAMECipher", cipher.Iv, []byte(msg), header)
 	if err != nil {
 		return nil, err
 	}
-	encrypterCBC := cbc.NewCBCEncrypter(c, iv)
 
-	msgByte := []byte(msg)
+	return cipher, nil
+}
 
-	// message is padded according to pkcs7 standard
-	padLen := c.BlockSize() - (len(msgByte) % c.BlockSize())
-	msgPad := make([]byte, len(msgByte)+padLen)
-	copy(msgPad, msgByte)
-	for i := len(msgByte); i < len(msgPad); i++ {
-		msgPad[i] = byte(padLen)
+// encapsulate encapsulates a random element of GT with FAME under the
+// policy msp. It returns a ciphertext without the symmetric encryption
+// of a message and the element of GT, from which the key for the
+// symmetric encryption is derived.
+func (a *FAME) encapsulate(msp *MSP, pk *FAMEPubKey) (*FAMECipher, *bn256.GT, error) {
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipher, err := a.encapsulateWith(msp, pk, keyGt, sample.NewUniform(a.P))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	symEnc := make([]byte, len(msgPad))
-	encrypterCBC.CryptBlocks(symEnc, msgPad)
+	return cipher, keyGt, nil
+}
 
-	// encapsulate the key with FAME
-	sampler := sample.NewUniform(a.P)
+// encapsulateWith encapsulates the element keyGt of GT with FAME under
+// the policy msp, taking the randomness of the encapsulation from
+// sampler.
+func (a *FAME) encapsulateWith(msp *MSP, pk *FAMEPubKey, keyGt *bn256.GT, sampler sample.Sampler) (*FAMECipher, error) {
 	s, err := data.NewRandomVector(2, sampler)
 	if err != nil {
 		return nil, err
 	}
+
+	return a.encapsulateS(msp, pk, keyGt, s)
+}
+
+// encapsulateS encapsulates the element keyGt of GT with FAME under
+// the policy msp, using the randomness s of the encapsulation.
+func (a *FAME) encapsulateS(msp *MSP, pk *FAMEPubKey, keyGt *bn256.GT, s data.Vector) (*FAMECipher, error) {
+	if len(msp.Mat) == 0 || len(msp.Mat[0]) == 0 {
+		return nil, fmt.Errorf("empty msp matrix")
+	}
+
+	// each row is mapped to its own copy of the attribute
+	labels, err := copyRowToAttrib(msp.RowToAttrib, a.copies())
+	if err != nil {
+		return nil, err
+	}
+
+	// encapsulate the key with FAME
 	ct0 := [3]*bn256.G2{new(bn256.G2).ScalarMult(pk.PartG2[0], s[0]),
 		new(bn256.G2).ScalarMult(pk.PartG2[1], s[1]),
 		new(bn256.G2).ScalarBaseMult(new(big.Int).Add(s[0], s[1]))}
 
 	ct := make([][3]*bn256.G1, len(msp.Mat))
-	for i := 0; i < len(msp.Mat); i++ {
-		for l := 0; l < 3; l++ {
-			hs1, err := bn256.HashG1(msp.RowToAttrib[i] + " " + strconv.Itoa(l) + " 0")
+	for i := range msp.Mat {
+		if ct[i], err = fameCtRow(labels[i], msp.Mat[i], s); err != nil {
+			return nil, err
+		}
+	}
+
+	ctPrime := new(bn256.GT).ScalarMult(pk.PartGT[0], s[0])
+	ctPrime.Add(ctPrime, new(bn256.GT).ScalarMult(pk.PartGT[1], s[1]))
+	ctPrime.Add(ctPrime, keyGt)
+
+	return &FAMECipher{Ct0: ct0, Ct: ct, CtPrime: ctPrime, Msp: msp}, nil
+}
+
+// fameCtRow returns the part of a FAME ciphertext with the randomness s
+// for the row of the policy mapped to the attribute label.
+func fameCtRow(label string, row data.Vector, s data.Vector) ([3]*bn256.G1, error) {
+	var ct [3]*bn256.G1
+	for l := 0; l < 3; l++ {
+		hs1, err := bn256.HashG1(label + " " + strconv.Itoa(l) + " 0")
+		if err != nil {
+			return ct, err
+		}
+		hs1.ScalarMult(hs1, s[0])
+
+		hs2, err := bn256.HashG1(label + " " + strconv.Itoa(l) + " 1")
+		if err != nil {
+			return ct, err
+		}
+		hs2.ScalarMult(hs2, s[1])
+
+		ct[l] = new(bn256.G1).Add(hs1, hs2)
+		for j := 0; j < len(row); j++ {
+			hs1, err = bn256.HashG1("0 " + strconv.Itoa(j) + " " + strconv.Itoa(l) + " 0")
 			if err != nil {
-				return nil, err
+				return ct, err
 			}
 			hs1.ScalarMult(hs1, s[0])
 
-			hs2, err := bn256.HashG1(msp.RowToAttrib[i] + " " + strconv.Itoa(l) + " 1")
+			hs2, err = bn256.HashG1("0 " + strconv.Itoa(j) + " " + strconv.Itoa(l) + " 1")
 			if err != nil {
-				return nil, err
+				return ct, err
 			}
 			hs2.ScalarMult(hs2, s[1])
 
-			ct[i][l] = new(bn256.G1).Add(hs1, hs2)
-			for j := 0; j < len(msp.Mat[0]); j++ {
-				hs1, err = bn256.HashG1("0 " + strconv.Itoa(j) + " " + strconv.Itoa(l) + " 0")
-				if err != nil {
-					return nil, err
-				}
-				hs1.ScalarMult(hs1, s[0])
-
-				hs2, err = bn256.HashG1("0 " + strconv.Itoa(j) + " " + strconv.Itoa(l) + " 1")
-				if err != nil {
-					return nil, err
-				}
-				hs2.ScalarMult(hs2, s[1])
-
-				hsToM := new(bn256.G1).Add(hs1, hs2)
-				pow := new(big.Int).Set(msp.Mat[i][j])
-				if pow.Sign() == -1 {
-					pow.Neg(pow)
-					hsToM.ScalarMult(hsToM, pow)
-					hsToM.Neg(hsToM)
-				} else {
-					hsToM.ScalarMult(hsToM, pow)
-				}
-				ct[i][l].Add(ct[i][l], hsToM)
+			hsToM := new(bn256.G1).Add(hs1, hs2)
+			pow := new(big.Int).Set(row[j])
+			if pow.Sign() == -1 {
+				pow.Neg(pow)
+				hsToM.ScalarMult(hsToM, pow)
+				hsToM.Neg(hsToM)
+			} else {
+				hsToM.ScalarMult(hsToM, pow)
 			}
+			ct[l].Add(ct[l], hsToM)
 		}
 	}
 
-	ctPrime := new(bn256.GT).ScalarMult(pk.PartGT[0], s[0])
-	ctPrime.Add(ctPrime, new(bn256.GT).ScalarMult(pk.PartGT[1], s[1]))
-	ctPrime.Add(ctPrime, keyGt)
-
-	return &FAMECipher{Ct0: ct0, Ct: ct, CtPrime: ctPrime, Msp: msp, SymEnc: symEnc, Iv: iv}, nil
+	return ct, nil
 }
 
 // FAMEAttribKeys represents keys corresponding to attributes possessed by
@@ -227,14 +303,39 @@ type FAMEAttribKeys struct {
 
 // GenerateAttribKeys given a set of attributes gamma and the master secret key
 // generates keys that can be used for the decryption of any ciphertext encoded
-// with a policy for which attributes gamma are sufficient.
+// with a policy for which attributes gamma are sufficient. A key is generated
+// for each of the a.Copies copies of the attributes.
 func (a *FAME) GenerateAttribKeys(gamma []string, sk *FAMESecKey) (*FAMEAttribKeys, error) {
+	return a.generateAttribKeys(gamma, sk, sk.PartG1)
+}
+
+// generateAttribKeys generates keys for the copies of the attributes
+// gamma, where the part of the master secret key sk contained in the
+// keys is replaced by master.
+func (a *FAME) generateAttribKeys(gamma []string, sk *FAMESecKey, master [3]*bn256.G1) (*FAMEAttribKeys, error) {
+	labels := make([]string, 0, len(gamma)*a.copies())
+	for _, y := range gamma {
+		if strings.Contains(y, "\x00") {
+			return nil, fmt.Errorf("attribute %q contains a null character", y)
+		}
+		for j := 0; j < a.copies(); j++ {
+			labels = append(labels, attribCopy(y, j))
+		}
+	}
+
+	return a.generateKeys(labels, sk, master)
+}
+
+// generateKeys generates keys for the attributes labels, as used in the
+// hashes of the scheme, where the part of the master secret key sk
+// contained in the keys is replaced by master.
+func (a *FAME) generateKeys(labels []string, sk *FAMESecKey, master [3]*bn256.G1) (*FAMEAttribKeys, error) {
 	sampler := sample.NewUniform(a.P)
 	r, err := data.NewRandomVector(2, sampler)
 	if err != nil {
 		return nil, err
 	}
-	sigma, err := data.NewRandomVector(len(gamma), sampler)
+	sigma, err := data.NewRandomVector(len(labels), sampler)
 	if err != nil {
 		return nil, err
 	}
@@ -254,9 +355,9 @@ func (a *FAME) GenerateAttribKeys(gamma []string, sk *FAMESecKey) (*FAMEAttribKe
 	a1Inv := new(big.Int).ModInverse(sk.PartInt[1], a.P)
 	aInv := [2]*big.Int{a0Inv, a1Inv}
 
-	k := make([][3]*bn256.G1, len(gamma))
+	k := make([][3]*bn256.G1, len(labels))
 	attribToI := make(map[string]int)
-	for i, y := range gamma {
+	for i, y := range labels {
 		k[i] = [3]*bn256.G1{new(bn256.G1), new(bn256.G1), new(bn256.G1)}
 		gSigma := new(bn256.G1).ScalarBaseMult(sigma[i])
 		for t := 0; t < 2; t++ {
@@ -316,12 +417,12 @@ func (a *FAME) GenerateAttribKeys(gamma []string, sk *FAMESecKey) (*FAMEAttribKe
 		k2[t].Add(k2[t], hs2)
 		k2[t].Add(k2[t], gSigmaPrime)
 		k2[t].ScalarMult(k2[t], aInv[t])
-		k2[t].Add(k2[t], sk.PartG1[t])
+		k2[t].Add(k2[t], master[t])
 	}
 
 	k2[2].ScalarBaseMult(sigmaPrime)
 	k2[2].Neg(k2[2])
-	k2[2].Add(k2[2], sk.PartG1[2])
+	k2[2].Add(k2[2], master[2])
 
 	return &FAMEAttribKeys{K0: k0, K: k, KPrime: k2, AttribToI: attribToI}, nil
 }
@@ -331,92 +432,150 @@ func (a *FAME) GenerateAttribKeys(gamma []string, sk *FAMESecKey) (*FAMEAttribKe
 // corresponding keys FAMEAttribKeys) suffices the encryption policy of the
 // cipher. If this is not possible, an error is returned.
 func (a *FAME) Decrypt(cipher *FAMECipher, key *FAMEAttribKeys, pk *FAMEPubKey) (string, error) {
-	// find out which attributes are owned
-	attribMap := make(map[string]bool)
-	for k := range key.AttribToI {
-		attribMap[k] = true
+	keyGt, err := a.decapsulate(cipher, key)
+	if err != nil {
+		return "", err
 	}
 
-	countAttrib := 0
-	for i := 0; i < len(cipher.Msp.Mat); i++ {
-		if attribMap[cipher.Msp.RowToAttrib[i]] {
-			countAttrib++
-		}
+	msg, err := openSym(cipher.SymVersion, keyGt, "abe.FAMECipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", err
 	}
 
-	// create a matrix of needed keys
-	preMatForKey := make([]data.Vector, countAttrib)
-	ctForKey := make([][3]*bn256.G1, countAttrib)
-	rowToAttrib := make([]string, countAttrib)
-	countAttrib = 0
-	for i := 0; i < len(cipher.Msp.Mat); i++ {
-		if attribMap[cipher.Msp.RowToAttrib[i]] {
-			preMatForKey[countAttrib] = cipher.Msp.Mat[i]
-			ctForKey[countAttrib] = cipher.Ct[i]
-			rowToAttrib[countAttrib] = cipher.Msp.RowToAttrib[i]
-			countAttrib++
-		}
+	return string(msg), nil
+}
+
+// decapsulate recovers the element of GT encapsulated in the cipher,
+// from which the key for the symmetric encryption is derived.
+func (a *FAME) decapsulate(cipher *FAMECipher, key *FAMEAttribKeys) (*bn256.GT, error) {
+	if err := a.checkCipher(cipher); err != nil {
+		return nil, err
+	}
+
+	// the rows are mapped to the copies of the attributes as
+	// in the encryption
+	labels, err := copyRowToAttrib(cipher.Msp.RowToAttrib, len(cipher.Msp.RowToAttrib))
+	if err != nil {
+		return nil, err
 	}
+	msp := &MSP{P: cipher.Msp.P, Mat: cipher.Msp.Mat, RowToAttrib: labels}
 
-	matForKey, err := data.NewMatrix(preMatForKey)
+	// find a minimal set of rows of the owned copies of the
+	// attributes and a combination alpha of them needed to decrypt
+	attribs := make([]string, 0, len(key.AttribToI))
+	for k := range key.AttribToI {
+		attribs = append(attribs, k)
+	}
+	rows, alpha, err := msp.Reconstruct(attribs, false)
 	if err != nil {
-		return "", fmt.Errorf("the provided cipher is faulty")
+		return nil, fmt.Errorf("provided key is not sufficient for decryption")
 	}
+	ctForKey := make([][3]*bn256.G1, len(rows))
+	rowToAttrib := make([]string, len(rows))
+	for i, row := range rows {
+		ctForKey[i] = cipher.Ct[row]
+		rowToAttrib[i] = labels[row]
+	}
+
+	// get the element of GT from which the key for the decryption
+	// of msg is derived
+	keyGt := fameUnblind(cipher.Ct0, ctForKey, rowToAttrib, alpha, key)
+	keyGt.Add(keyGt, cipher.CtPrime)
+
+	return keyGt, nil
+}
 
-	// matForKey may have a len of 0 if there is a single condition
-	if len(matForKey) == 0 {
-		return "", fmt.Errorf("provided key is not sufficient for decryption")
+// checkCipher checks that all the elements of the cipher are present
+// and that their number matches the MSP of the cipher, so that a
+// malformed cipher, possibly decoded from untrusted data, is rejected
+// before it is used in the decryption.
+func (a *FAME) checkCipher(cipher *FAMECipher) error {
+	faulty := fmt.Errorf("the provided cipher is faulty")
+	if cipher == nil || cipher.CtPrime == nil || cipher.Msp == nil {
+		return faulty
+	}
+	msp := cipher.Msp
+	if len(msp.Mat) == 0 || len(msp.Mat[0]) == 0 ||
+		len(msp.RowToAttrib) != len(msp.Mat) || len(cipher.Ct) != len(msp.Mat) {
+		return faulty
+	}
+	for _, e := range cipher.Ct0 {
+		if e == nil {
+			return faulty
+		}
+	}
+	for i, row := range msp.Mat {
+		if len(row) != len(msp.Mat[0]) {
+			return faulty
+		}
+		for _, e := range row {
+			if e == nil {
+				return faulty
+			}
+		}
+		for _, e := range cipher.Ct[i] {
+			if e == nil {
+				return faulty
+			}
+		}
 	}
 
-	// get a combination alpha of keys needed to decrypt
-	// matForKey may have a len of 0 if there is a single condition
-	if len(matForKey) == 0 {
-		return "", fmt.Errorf("provided key is not sufficient for decryption")
+	return nil
+}
+
+// checkAttribKeys checks that all the elements of the keys are present
+// and that the attributes are mapped to the present elements, so that
+// malformed keys, possibly decoded from untrusted data, are rejected
+// before they are used in the decryption.
+func checkAttribKeys(key *FAMEAttribKeys) error {
+	faulty := fmt.Errorf("the provided key is faulty")
+	if key == nil {
+		return faulty
 	}
-	oneVec := data.NewConstantVector(len(matForKey[0]), big.NewInt(0))
-	oneVec[0].SetInt64(1)
-	alpha, err := data.GaussianEliminationSolver(matForKey.Transpose(), oneVec, a.P)
-	if err != nil {
-		return "", fmt.Errorf("provided key is not sufficient for decryption")
+	for j := 0; j < 3; j++ {
+		if key.K0[j] == nil || key.KPrime[j] == nil {
+			return faulty
+		}
+	}
+	for _, k := range key.K {
+		for _, e := range k {
+			if e == nil {
+				return faulty
+			}
+		}
+	}
+	for _, i := range key.AttribToI {
+		if i < 0 || i >= len(key.K) {
+			return faulty
+		}
 	}
 
-	// get a CBC key needed for the decryption of msg
-	keyGt := new(bn256.GT).Set(cipher.CtPrime)
+	return nil
+}
 
+// fameUnblind combines the rows ct of a ciphertext with the first part
+// ct0, mapped to the attributes rowToAttrib, by coefficients alpha
+// with the keys for the attributes. The result cancels the blinding
+// of the encapsulated element of GT that corresponds to the part of
+// the master secret key contained in the keys.
+func fameUnblind(ct0 [3]*bn256.G2, ct [][3]*bn256.G1, rowToAttrib []string, alpha data.Vector, key *FAMEAttribKeys) *bn256.GT {
+	keyGt := new(bn256.GT).ScalarBaseMult(big.NewInt(0))
 	ctProd := new([3]*bn256.G1)
 	keyProd := new([3]*bn256.G1)
 	for j := 0; j < 3; j++ {
 		ctProd[j] = new(bn256.G1).ScalarBaseMult(big.NewInt(0))
 		keyProd[j] = new(bn256.G1).ScalarBaseMult(big.NewInt(0))
 		for i, e := range rowToAttrib {
-			ctProd[j].Add(ctProd[j], new(bn256.G1).ScalarMult(ctForKey[i][j], alpha[i]))
+			ctProd[j].Add(ctProd[j], new(bn256.G1).ScalarMult(ct[i][j], alpha[i]))
 			keyProd[j].Add(keyProd[j], new(bn256.G1).ScalarMult(key.K[key.AttribToI[e]][j], alpha[i]))
 		}
 		keyProd[j].Add(keyProd[j], key.KPrime[j])
 		ctPairing := bn256.Pair(ctProd[j], key.K0[j])
-		keyPairing := bn256.Pair(keyProd[j], cipher.Ct0[j])
+		keyPairing := bn256.Pair(keyProd[j], ct0[j])
 		keyPairing.Neg(keyPairing)
 		keyGt.Add(keyGt, ctPairing)
 		keyGt.Add(keyGt, keyPairing)
 	}
 
-	keyCBC := sha256.Sum256([]byte(keyGt.String()))
-
-	c, err := aes.NewCipher(keyCBC[:])
-	if err != nil {
-		return "", err
-	}
-
-	msgPad := make([]byte, len(cipher.SymEnc))
-	decrypter := cbc.NewCBCDecrypter(c, cipher.Iv)
-	decrypter.CryptBlocks(msgPad, cipher.SymEnc)
-
-	// unpad the message
-	padLen := int(msgPad[len(msgPad)-1])
-	if (len(msgPad) - padLen) < 0 {
-		return "", fmt.Errorf("failed to decrypt")
-	}
-	msgByte := msgPad[0:(len(msgPad) - padLen)]
-
-	return string(msgByte), nil
+	return keyGt
 }
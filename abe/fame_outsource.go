@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/sample"
+)
+
+// The decryption of FAME ciphertexts can be outsourced following
+// Green, Hohenberger, Waters:
+// "Outsourcing the Decryption of ABE Ciphertexts"
+// https://www.usenix.org/legacy/event/sec11/tech/full_papers/Green.pdf
+//
+// A user blinds the attribute keys with a random retrieval key z into
+// a transformation key, which is a valid FAME key for the master secret
+// divided by z. An untrusted server holding the transformation key
+// performs all the pairings of the decryption and transforms a
+// ciphertext into a short ElGamal-like ciphertext, from which the user
+// recovers the key of the symmetric encryption with a single
+// exponentiation. The server learns nothing about the message. Since
+// the message is encrypted with AES-GCM preceded by a commitment to the
+// encapsulated element of GT, the user verifies that the element
+// recovered from the transformed ciphertext is the encapsulated one,
+// thus a wrongly transformed ciphertext is detected.
+
+// FAMETransformKey represents a transformation key, derived from the
+// attribute keys of a user, that allows a server to transform the
+// ciphertexts the user can decrypt.
+type FAMETransformKey FAMEAttribKeys
+
+// FAMETransformedCipher represents a ciphertext transformed by a server
+// with a transformation key. The element of GT encapsulated in the
+// original ciphertext is CtPrime + z * CtBlind, where z is the
+// retrieval key.
+type FAMETransformedCipher struct {
+	CtPrime *bn256.GT
+	CtBlind *bn256.GT
+}
+
+// GenerateTransformKey blinds the attribute keys key of a user into a
+// transformation key, that can be given to a server, and a retrieval
+// key that the user keeps secret.
+func (a *FAME) GenerateTransformKey(key *FAMEAttribKeys) (*FAMETransformKey, *big.Int, error) {
+	sampler := sample.NewUniformRange(big.NewInt(1), a.P)
+	z, err := sampler.Sample()
+	if err != nil {
+		return nil, nil, err
+	}
+	zInv := new(big.Int).ModInverse(z, a.P)
+
+	tk := &FAMETransformKey{
+		K:         make([][3]*bn256.G1, len(key.K)),
+		AttribToI: make(map[string]int, len(key.AttribToI)),
+	}
+	for j := 0; j < 3; j++ {
+		tk.K0[j] = new(bn256.G2).ScalarMult(key.K0[j], zInv)
+		tk.KPrime[j] = new(bn256.G1).ScalarMult(key.KPrime[j], zInv)
+	}
+	for i, row := range key.K {
+		for j := 0; j < 3; j++ {
+			tk.K[i][j] = new(bn256.G1).ScalarMult(row[j], zInv)
+		}
+	}
+	for k, v := range key.AttribToI {
+		tk.AttribToI[k] = v
+	}
+
+	return tk, z, nil
+}
+
+// Transform is run by a server holding the transformation key tk of a
+// user. It performs the pairings needed for the decryption of cipher
+// and returns a transformed ciphertext, which the user decrypts with
+// DecryptTransformed. An error is returned if the attributes of the
+// key do not satisfy the policy of cipher.
+func (a *FAME) Transform(cipher *FAMECipher, tk *FAMETransformKey) (*FAMETransformedCipher, error) {
+	if err := checkAttribKeys((*FAMEAttribKeys)(tk)); err != nil {
+		return nil, err
+	}
+	blind, err := a.decapsulate(cipher, (*FAMEAttribKeys)(tk))
+	if err != nil {
+		return nil, err
+	}
+	blind.Add(blind, new(bn256.GT).Neg(cipher.CtPrime))
+
+	return &FAMETransformedCipher{
+		CtPrime: new(bn256.GT).Set(cipher.CtPrime),
+		CtBlind: blind,
+	}, nil
+}
+
+// DecryptTransformed decrypts the ciphertext cipher, given the
+// ciphertext transformed by a server and the retrieval key z of the
+// user, without computing any pairings. An error is returned if the
+// transformed ciphertext does not belong to cipher or the server did
+// not transform it correctly, which is verified with the commitment
+// to the encapsulated element of GT. Since legacy ciphertexts encrypted
+// with AES-CBC do not contain it, they are not accepted.
+func (a *FAME) DecryptTransformed(cipher *FAMECipher, tc *FAMETransformedCipher, z *big.Int) (string, error) {
+	if cipher == nil || cipher.CtPrime == nil || z == nil {
+		return "", fmt.Errorf("the provided cipher is faulty")
+	}
+	if cipher.SymVersion != SymVersionGCM {
+		return "", fmt.Errorf("the decryption of the cipher cannot be verified")
+	}
+	if tc == nil || tc.CtPrime == nil || tc.CtBlind == nil ||
+		!bytes.Equal(tc.CtPrime.Marshal(), cipher.CtPrime.Marshal()) {
+		return "", fmt.Errorf("the transformed cipher does not belong to the cipher")
+	}
+
+	keyGt := new(bn256.GT).ScalarMult(tc.CtBlind, z)
+	keyGt.Add(keyGt, tc.CtPrime)
+
+	msg, err := openSym(cipher.SymVersion, keyGt, "abe.FAMECipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abe_test
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/abe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFAME_Transform(t *testing.T) {
+	a := abe.NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("(0 AND 1) OR (2 AND 3 AND 4)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	msg := "Attack at dawn!"
+	cipher, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	keys, err := a.GenerateAttribKeys([]string{"2", "3", "4"}, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+
+	// the user blinds the keys and gives the transformation key
+	// to a server, keeping the retrieval key
+	tk, z, err := a.GenerateTransformKey(keys)
+	if err != nil {
+		t.Fatalf("Failed to generate the transformation key: %v", err)
+	}
+	decodedTk := new(abe.FAMETransformKey)
+	roundTrip(t, tk, decodedTk)
+	tkJSON, err := json.Marshal(tk)
+	if err != nil {
+		t.Fatalf("Failed to encode the transformation key: %v", err)
+	}
+	assert.Error(t, json.Unmarshal(tkJSON, new(abe.FAMEAttribKeys)))
+
+	// the server transforms the ciphertext
+	tc, err := a.Transform(cipher, decodedTk)
+	if err != nil {
+		t.Fatalf("Failed to transform: %v", err)
+	}
+	decodedTc := new(abe.FAMETransformedCipher)
+	roundTrip(t, tc, decodedTc)
+
+	// and the user decrypts it without pairings
+	msgCheck, err := a.DecryptTransformed(cipher, decodedTc, z)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	// the transformation key is not a decryption key
+	_, err = a.Decrypt(cipher, (*abe.FAMEAttribKeys)(tk), pubKey)
+	assert.Error(t, err)
+
+	// a wrong result of the server is detected
+	_, randGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to sample: %v", err)
+	}
+	_, err = a.DecryptTransformed(cipher, &abe.FAMETransformedCipher{CtPrime: tc.CtPrime, CtBlind: randGt}, z)
+	assert.Error(t, err)
+
+	// as well as a result for another ciphertext
+	cipher2, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	tc2, err := a.Transform(cipher2, tk)
+	if err != nil {
+		t.Fatalf("Failed to transform: %v", err)
+	}
+	_, err = a.DecryptTransformed(cipher, tc2, z)
+	assert.Error(t, err)
+
+	// malformed inputs are rejected
+	_, err = a.DecryptTransformed(nil, tc, z)
+	assert.Error(t, err)
+	_, err = a.DecryptTransformed(&abe.FAMECipher{SymVersion: abe.SymVersionGCM}, tc, z)
+	assert.Error(t, err)
+	_, err = a.DecryptTransformed(cipher, nil, z)
+	assert.Error(t, err)
+	_, err = a.DecryptTransformed(cipher, tc, nil)
+	assert.Error(t, err)
+	_, err = a.Transform(nil, tk)
+	assert.Error(t, err)
+	_, err = a.Transform(cipher, nil)
+	assert.Error(t, err)
+	_, err = a.Transform(cipher, &abe.FAMETransformKey{})
+	assert.Error(t, err)
+
+	// the server cannot transform ciphertexts whose policy
+	// is not satisfied by the attributes of the user
+	keysInsuff, err := a.GenerateAttribKeys([]string{"0", "2", "3"}, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	tkInsuff, _, err := a.GenerateTransformKey(keysInsuff)
+	if err != nil {
+		t.Fatalf("Failed to generate the transformation key: %v", err)
+	}
+	_, err = a.Transform(cipher, tkInsuff)
+	assert.Error(t, err)
+}
@@ -0,0 +1,345 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/sample"
+)
+
+// This is a revocable variant of the FAME scheme, based on the indirect
+// revocation with a binary tree of Alexandra Boldyreva, Vipul Goyal,
+// Virendra Kumar: "Identity-based Encryption with Efficient Revocation",
+// see https://eprint.iacr.org/2012/052.pdf.
+//
+// Each user is assigned a leaf of a binary tree. The part of the master
+// secret key contained in the FAME keys is split for each node of the
+// tree into a part given to the users in the subtree of the node and
+// a part the authority publishes for a time epoch, if no user in the
+// subtree is revoked. A ciphertext is bound to an epoch, and decrypting
+// it needs both parts for some node, thus the users that are revoked
+// before the epoch cannot decrypt it, regardless of their attributes.
+// The keys of a user consist of FAME keys for each node on the path
+// from the leaf to the root, while the update for an epoch consists
+// of FAME keys for a single attribute, one for each node covering the
+// leaves of the users that are not revoked.
+//
+
+// RevocableFAME represents a revocable FAME scheme.
+type RevocableFAME struct {
+	FAME  *FAME // underlying FAME scheme
+	Depth int   // depth of the binary tree, which has 2^Depth leaves
+}
+
+// NewRevocableFAME configures a new instance of the scheme with a
+// binary tree of the given depth, supporting up to 2^depth users.
+// To allow repeated attributes in the policies, FAME can be replaced
+// by an instance created with NewFAMEWithCopies.
+func NewRevocableFAME(depth int) *RevocableFAME {
+	return &RevocableFAME{FAME: NewFAME(), Depth: depth}
+}
+
+// RevocableFAMESecKey represents a master secret key of a revocable FAME
+// scheme. The parts of the master secret key of the nodes of the tree
+// are derived from Seed.
+type RevocableFAMESecKey struct {
+	Sk   *FAMESecKey
+	Seed []byte
+}
+
+// GenerateMasterKeys generates a new set of public keys, needed
+// for encrypting data, and master secret keys needed for generating
+// keys for decrypting and their updates.
+func (r *RevocableFAME) GenerateMasterKeys() (*FAMEPubKey, *RevocableFAMESecKey, error) {
+	pk, sk, err := r.FAME.GenerateMasterKeys()
+	if err != nil {
+		return nil, nil, err
+	}
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, nil, err
+	}
+
+	return pk, &RevocableFAMESecKey{Sk: sk, Seed: seed}, nil
+}
+
+// leaves returns the number of the leaves of the tree.
+func (r *RevocableFAME) leaves() (int, error) {
+	if r.Depth < 0 || r.Depth > 30 {
+		return 0, fmt.Errorf("depth of the tree should be between 0 and 30")
+	}
+
+	return 1 << uint(r.Depth), nil
+}
+
+// nodePart returns the part of the master secret key given to the
+// users in the subtree of the node, and the part published in the
+// updates of the keys, which sum up to the part of the master secret
+// key contained in FAME keys. The nodes are numbered as in a heap,
+// i.e. the root is 1 and the children of node n are 2n and 2n+1.
+func (sk *RevocableFAMESecKey) nodePart(node int) ([3]*bn256.G1, [3]*bn256.G1, error) {
+	var userPart, updatePart [3]*bn256.G1
+	for t := range updatePart {
+		u, err := bn256.HashG1("gofe revocation " + hex.EncodeToString(sk.Seed) + " " +
+			strconv.Itoa(node) + " " + strconv.Itoa(t))
+		if err != nil {
+			return userPart, updatePart, err
+		}
+		updatePart[t] = u
+		userPart[t] = new(bn256.G1).Neg(u)
+		userPart[t].Add(userPart[t], sk.Sk.PartG1[t])
+	}
+
+	return userPart, updatePart, nil
+}
+
+// epochAttrib returns the attribute to which the part of a ciphertext
+// binding it to the epoch is mapped. It contains a null character, so
+// it differs from the attributes of the users.
+func epochAttrib(epoch int) string {
+	return "\x00epoch " + strconv.Itoa(epoch)
+}
+
+// RevocableFAMEKey represents keys of a user in a revocable FAME scheme:
+// FAME keys for the attributes of the user, one for each node on the
+// path from the leaf of the user to the root of the tree.
+type RevocableFAMEKey struct {
+	Leaf  int
+	Nodes []int
+	Keys  []*FAMEAttribKeys
+}
+
+// GenerateAttribKeys given a set of attributes gamma, a leaf of the tree
+// assigned to the user, and the master secret key generates keys that
+// can be used, together with the update of the keys for an epoch, for
+// the decryption of any ciphertext of the epoch encoded with a policy
+// for which attributes gamma are sufficient. Each user should be
+// assigned a different leaf.
+func (r *RevocableFAME) GenerateAttribKeys(gamma []string, leaf int, sk *RevocableFAMESecKey) (*RevocableFAMEKey, error) {
+	n, err := r.leaves()
+	if err != nil {
+		return nil, err
+	}
+	if leaf < 0 || leaf >= n {
+		return nil, fmt.Errorf("leaf should be between 0 and %d", n-1)
+	}
+
+	key := &RevocableFAMEKey{Leaf: leaf}
+	for node := n + leaf; node >= 1; node /= 2 {
+		userPart, _, err := sk.nodePart(node)
+		if err != nil {
+			return nil, err
+		}
+		k, err := r.FAME.generateAttribKeys(gamma, sk.Sk, userPart)
+		if err != nil {
+			return nil, err
+		}
+		key.Nodes = append(key.Nodes, node)
+		key.Keys = append(key.Keys, k)
+	}
+
+	return key, nil
+}
+
+// RevocableFAMEUpdate represents an update of the keys for an epoch in
+// a revocable FAME scheme: FAME keys for the attribute of the epoch,
+// one for each node covering the leaves that are not revoked.
+type RevocableFAMEUpdate struct {
+	Epoch int
+	Nodes []int
+	Keys  []*FAMEAttribKeys
+}
+
+// UpdateKeys generates an update of the keys for a non-negative epoch,
+// with which the users whose leaves are not in revoked can decrypt the
+// ciphertexts of the epoch. The update is public, and should be
+// published by the authority for each epoch.
+func (r *RevocableFAME) UpdateKeys(epoch int, revoked []int, sk *RevocableFAMESecKey) (*RevocableFAMEUpdate, error) {
+	if epoch < 0 {
+		return nil, fmt.Errorf("epoch should be non-negative")
+	}
+	n, err := r.leaves()
+	if err != nil {
+		return nil, err
+	}
+
+	// mark the nodes on the paths from the revoked leaves to the root,
+	// the unmarked children of the marked nodes cover the other leaves
+	marked := make(map[int]bool)
+	for _, leaf := range revoked {
+		if leaf < 0 || leaf >= n {
+			return nil, fmt.Errorf("leaf should be between 0 and %d", n-1)
+		}
+		for node := n + leaf; node >= 1; node /= 2 {
+			marked[node] = true
+		}
+	}
+	cover := make([]int, 0)
+	if len(marked) == 0 {
+		cover = append(cover, 1)
+	}
+	for node := range marked {
+		for _, child := range []int{2 * node, 2*node + 1} {
+			if child < 2*n && !marked[child] {
+				cover = append(cover, child)
+			}
+		}
+	}
+	sort.Ints(cover)
+
+	update := &RevocableFAMEUpdate{Epoch: epoch, Nodes: cover}
+	for _, node := range cover {
+		_, updatePart, err := sk.nodePart(node)
+		if err != nil {
+			return nil, err
+		}
+		k, err := r.FAME.generateKeys([]string{epochAttrib(epoch)}, sk.Sk, updatePart)
+		if err != nil {
+			return nil, err
+		}
+		update.Keys = append(update.Keys, k)
+	}
+
+	return update, nil
+}
+
+// RevocableFAMECipher represents a ciphertext of a revocable FAME scheme:
+// a FAME ciphertext and its part binding it to an epoch.
+type RevocableFAMECipher struct {
+	Cipher  *FAMECipher
+	Epoch   int
+	CtEpoch [3]*bn256.G1
+}
+
+// Encrypt takes as an input a message msg, a MSP struct representing the
+// decryption policy, a non-negative epoch and a public key pk. It returns
+// an encryption of the message, which can be decrypted only with the
+// update of the keys for the epoch. In case of a failed procedure an
+// error is returned.
+func (r *RevocableFAME) Encrypt(msg string, msp *MSP, epoch int, pk *FAMEPubKey) (*RevocableFAMECipher, error) {
+	if epoch < 0 {
+		return nil, fmt.Errorf("epoch should be non-negative")
+	}
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// the part of the ciphertext for the epoch is a row of FAME
+	// ciphertext for the policy with a single attribute, using the
+	// same randomness as the ciphertext for msp
+	s, err := data.NewRandomVector(2, sample.NewUniform(r.FAME.P))
+	if err != nil {
+		return nil, err
+	}
+	fameCipher, err := r.FAME.encapsulateS(msp, pk, keyGt, s)
+	if err != nil {
+		return nil, err
+	}
+	ctEpoch, err := fameCtRow(epochAttrib(epoch), data.Vector{big.NewInt(1)}, s)
+	if err != nil {
+		return nil, err
+	}
+	cipher := &RevocableFAMECipher{Cipher: fameCipher, Epoch: epoch, CtEpoch: ctEpoch}
+
+	// msg is encrypted using AES-GCM, with a key derived from keyGt
+	fameCipher.Iv, err = newNonce()
+	if err != nil {
+		return nil, err
+	}
+	fameCipher.SymVersion = SymVersionGCM
+	header, err := cipher.header()
+	if err != nil {
+		return nil, err
+	}
+	fameCipher.SymEnc, err = sealSym(keyGt, "abe.RevocableFAMECipher", fameCipher.Iv, []byte(msg), header)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher, nil
+}
+
+// Decrypt takes as an input a cipher, keys of a user and the update of
+// the keys for the epoch of the cipher, and tries to decrypt the cipher.
+// This is possible only if the attributes of the user suffice the
+// encryption policy of the cipher and the user is not revoked in the
+// update. If this is not possible, an error is returned.
+func (r *RevocableFAME) Decrypt(cipher *RevocableFAMECipher, key *RevocableFAMEKey, update *RevocableFAMEUpdate) (string, error) {
+	if cipher == nil || cipher.Cipher == nil || cipher.Cipher.SymVersion != SymVersionGCM {
+		return "", fmt.Errorf("the provided cipher is faulty")
+	}
+	for _, e := range cipher.CtEpoch {
+		if e == nil {
+			return "", fmt.Errorf("the provided cipher is faulty")
+		}
+	}
+	if update == nil || update.Epoch != cipher.Epoch || len(update.Nodes) != len(update.Keys) {
+		return "", fmt.Errorf("the update is not for the epoch of the cipher")
+	}
+	if key == nil || len(key.Nodes) != len(key.Keys) {
+		return "", fmt.Errorf("the provided key is faulty")
+	}
+
+	// find a node on the path of the user covered by the update
+	var userKey, updateKey *FAMEAttribKeys
+	found := false
+	for i, node := range key.Nodes {
+		for j, covered := range update.Nodes {
+			if node == covered {
+				userKey, updateKey = key.Keys[i], update.Keys[j]
+				found = true
+			}
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("provided key is revoked for the epoch of the cipher")
+	}
+	if checkAttribKeys(userKey) != nil {
+		return "", fmt.Errorf("the provided key is faulty")
+	}
+	if checkAttribKeys(updateKey) != nil {
+		return "", fmt.Errorf("the update is not for the epoch of the cipher")
+	}
+	if _, ok := updateKey.AttribToI[epochAttrib(cipher.Epoch)]; !ok {
+		return "", fmt.Errorf("the update is not for the epoch of the cipher")
+	}
+
+	// the keys of the user and the update each cancel the blinding
+	// corresponding to their part of the master secret key
+	keyGt, err := r.FAME.decapsulate(cipher.Cipher, userKey)
+	if err != nil {
+		return "", err
+	}
+	keyGt.Add(keyGt, fameUnblind(cipher.Cipher.Ct0, [][3]*bn256.G1{cipher.CtEpoch},
+		[]string{epochAttrib(cipher.Epoch)}, data.Vector{big.NewInt(1)}, updateKey))
+
+	msg, err := openSym(cipher.Cipher.SymVersion, keyGt, "abe.RevocableFAMECipher",
+		cipher.Cipher.Iv, cipher.Cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
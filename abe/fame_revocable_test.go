@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe_test
+
+import (
+	"testing"
+
+	"github.com/fentec-project/gofe/abe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevocableFAME(t *testing.T) {
+	// a tree with 4 leaves
+	a := abe.NewRevocableFAME(2)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msg := "Attack at dawn!"
+	msp, err := abe.BooleanToMSP("doctor AND (cardiology OR surgery)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	gammas := [][]string{
+		{"doctor", "cardiology"},
+		{"doctor", "surgery"},
+		{"doctor", "cardiology", "surgery"},
+		{"nurse", "cardiology"},
+	}
+	keys := make([]*abe.RevocableFAMEKey, len(gammas))
+	for i, gamma := range gammas {
+		keys[i], err = a.GenerateAttribKeys(gamma, i, secKey)
+		if err != nil {
+			t.Fatalf("Failed to generate keys: %v", err)
+		}
+	}
+
+	// in epoch 1 no user is revoked
+	update1, err := a.UpdateKeys(1, nil, secKey)
+	if err != nil {
+		t.Fatalf("Failed to update keys: %v", err)
+	}
+	assert.Equal(t, []int{1}, update1.Nodes)
+	cipher1, err := a.Encrypt(msg, msp, 1, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	for _, key := range keys[:3] {
+		msgCheck, err := a.Decrypt(cipher1, key, update1)
+		if err != nil {
+			t.Fatalf("Failed to decrypt: %v", err)
+		}
+		assert.Equal(t, msg, msgCheck)
+	}
+	_, err = a.Decrypt(cipher1, keys[3], update1)
+	assert.Error(t, err)
+
+	// in epoch 2 the user with the leaf 1 is revoked
+	update2, err := a.UpdateKeys(2, []int{1}, secKey)
+	if err != nil {
+		t.Fatalf("Failed to update keys: %v", err)
+	}
+	assert.Equal(t, []int{3, 4}, update2.Nodes)
+	cipher2, err := a.Encrypt(msg, msp, 2, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	for _, i := range []int{0, 2} {
+		msgCheck, err := a.Decrypt(cipher2, keys[i], update2)
+		if err != nil {
+			t.Fatalf("Failed to decrypt: %v", err)
+		}
+		assert.Equal(t, msg, msgCheck)
+	}
+	_, err = a.Decrypt(cipher2, keys[1], update2)
+	assert.Error(t, err)
+	_, err = a.Decrypt(cipher2, keys[3], update2)
+	assert.Error(t, err)
+
+	// the revoked user can still decrypt the ciphertexts of the
+	// earlier epochs, but not use their updates for the later ones
+	msgCheck, err := a.Decrypt(cipher1, keys[1], update1)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+	_, err = a.Decrypt(cipher2, keys[1], update1)
+	assert.Error(t, err)
+	forged := *update1
+	forged.Epoch = 2
+	_, err = a.Decrypt(cipher2, keys[1], &forged)
+	assert.Error(t, err)
+
+	// a cipher bound to another epoch does not decrypt
+	cipher2.Epoch = 1
+	_, err = a.Decrypt(cipher2, keys[0], update1)
+	assert.Error(t, err)
+
+	// if all the users are revoked, the update is empty
+	update3, err := a.UpdateKeys(3, []int{0, 1, 2, 3}, secKey)
+	if err != nil {
+		t.Fatalf("Failed to update keys: %v", err)
+	}
+	assert.Empty(t, update3.Nodes)
+
+	_, err = a.GenerateAttribKeys(gammas[0], 4, secKey)
+	assert.Error(t, err)
+	_, err = a.UpdateKeys(4, []int{-1}, secKey)
+	assert.Error(t, err)
+	_, err = a.Encrypt(msg, msp, -1, pubKey)
+	assert.Error(t, err)
+}
+
+func TestRevocableFAME_Malformed(t *testing.T) {
+	a := abe.NewRevocableFAME(1)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("doctor AND cardiology", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	key, err := a.GenerateAttribKeys([]string{"doctor", "cardiology"}, 0, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	update, err := a.UpdateKeys(1, nil, secKey)
+	if err != nil {
+		t.Fatalf("Failed to update keys: %v", err)
+	}
+	cipher, err := a.Encrypt("Attack at dawn!", msp, 1, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// zeroed or truncated inputs are rejected with an error
+	noEpoch := *cipher
+	noEpoch.CtEpoch[1] = nil
+	noCipher := *cipher
+	noCipher.Cipher = nil
+	ciphers := []*abe.RevocableFAMECipher{nil, {}, &noEpoch, &noCipher}
+	for _, c := range ciphers {
+		_, err = a.Decrypt(c, key, update)
+		assert.Error(t, err)
+	}
+
+	zeroedKey := *key
+	zeroedKey.Keys = make([]*abe.FAMEAttribKeys, len(key.Keys))
+	zeroedKey.Keys[len(key.Keys)-1] = &abe.FAMEAttribKeys{}
+	truncatedKey := *key
+	truncatedKey.Keys = key.Keys[:1]
+	for _, k := range []*abe.RevocableFAMEKey{nil, {}, &zeroedKey, &truncatedKey} {
+		_, err = a.Decrypt(cipher, k, update)
+		assert.Error(t, err)
+	}
+
+	zeroedUpdate := *update
+	zeroedUpdate.Keys = []*abe.FAMEAttribKeys{nil}
+	for _, u := range []*abe.RevocableFAMEUpdate{nil, {Epoch: 1}, &zeroedUpdate} {
+		_, err = a.Decrypt(cipher, key, u)
+		assert.Error(t, err)
+	}
+
+	_, err = a.Decrypt(cipher, key, update)
+	assert.NoError(t, err)
+}
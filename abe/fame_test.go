@@ -39,9 +39,9 @@ func TestFAME(t *testing.T) {
 
 	// create a msp struct out of a boolean expression representing the
 	// policy specifying which attributes are needed to decrypt the ciphertext;
-	// the boolean expression is a string of attributes joined by AND and OR
-	// hence the names of the attributes should not include "AND" or "OR"
-	// as a substring and '(' or ')' as a character
+	// the boolean expression is a string of attributes joined by AND and OR,
+	// where the names of the attributes with spaces, brackets, commas
+	// or quotes should be put in double quotes
 
 	// note that safety of the encryption is only proved if the mapping
 	// msp.RowToAttrib from the rows of msp.Mat to attributes is injective, i.e.
@@ -188,3 +188,186 @@ func TestFAME(t *testing.T) {
 	_, err = a.Decrypt(cipherMultiUUID, keysInsuffUUID, pubKey)
 	assert.Error(t, err)
 }
+
+func TestFAME_Threshold(t *testing.T) {
+	a := abe.NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msg := "Attack at dawn!"
+
+	// any two of doctor, nurse and admin, together with hospital
+	msp, err := abe.BooleanToMSP("hospital AND 2OF(doctor, nurse, admin)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	cipher, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	for _, gamma := range [][]string{
+		{"hospital", "doctor", "nurse"},
+		{"hospital", "nurse", "admin"},
+		{"hospital", "doctor", "nurse", "admin"},
+	} {
+		keys, err := a.GenerateAttribKeys(gamma, secKey)
+		if err != nil {
+			t.Fatalf("Failed to generate keys: %v", err)
+		}
+		msgCheck, err := a.Decrypt(cipher, keys, pubKey)
+		if err != nil {
+			t.Fatalf("Failed to decrypt: %v", err)
+		}
+		assert.Equal(t, msg, msgCheck)
+	}
+
+	for _, gamma := range [][]string{
+		{"hospital", "doctor"},
+		{"doctor", "nurse", "admin"},
+	} {
+		keys, err := a.GenerateAttribKeys(gamma, secKey)
+		if err != nil {
+			t.Fatalf("Failed to generate keys: %v", err)
+		}
+		_, err = a.Decrypt(cipher, keys, pubKey)
+		assert.Error(t, err)
+	}
+}
+
+func TestFAME_Copies(t *testing.T) {
+	a := abe.NewFAMEWithCopies(2)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msg := "Attack at dawn!"
+
+	// attribute A appears in two rows of the policy
+	msp, err := abe.BooleanToMSP("(A AND B) OR (A AND C)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	_, err = abe.NewFAME().Encrypt(msg, msp, pubKey)
+	assert.Error(t, err)
+	cipher, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	for _, gamma := range [][]string{{"A", "B"}, {"A", "C"}, {"A", "B", "C"}} {
+		keys, err := a.GenerateAttribKeys(gamma, secKey)
+		if err != nil {
+			t.Fatalf("Failed to generate keys: %v", err)
+		}
+		msgCheck, err := a.Decrypt(cipher, keys, pubKey)
+		if err != nil {
+			t.Fatalf("Failed to decrypt: %v", err)
+		}
+		assert.Equal(t, msg, msgCheck)
+	}
+
+	for _, gamma := range [][]string{{"A"}, {"B", "C"}} {
+		keys, err := a.GenerateAttribKeys(gamma, secKey)
+		if err != nil {
+			t.Fatalf("Failed to generate keys: %v", err)
+		}
+		_, err = a.Decrypt(cipher, keys, pubKey)
+		assert.Error(t, err)
+	}
+
+	// keys with a single copy of A only cover its first row
+	keys, err := abe.NewFAME().GenerateAttribKeys([]string{"A", "C"}, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	_, err = a.Decrypt(cipher, keys, pubKey)
+	assert.Error(t, err)
+
+	// more rows than copies of an attribute
+	msp, err = abe.BooleanToMSP("(A AND B) OR (A AND C) OR (A AND D)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	_, err = a.Encrypt(msg, msp, pubKey)
+	assert.Error(t, err)
+}
+
+func TestFAME_Numeric(t *testing.T) {
+	a := abe.NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msg := "Attack at dawn!"
+
+	msp, err := abe.BooleanToMSP("staff AND clearance >= 3", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	cipher, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// the numeric attribute is expanded into the attributes of its bits
+	gamma := append([]string{"staff"}, abe.NumericAttribs("clearance", 5)...)
+	keys, err := a.GenerateAttribKeys(gamma, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	msgCheck, err := a.Decrypt(cipher, keys, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	gammaInsuff := append([]string{"staff"}, abe.NumericAttribs("clearance", 2)...)
+	keysInsuff, err := a.GenerateAttribKeys(gammaInsuff, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	_, err = a.Decrypt(cipher, keysInsuff, pubKey)
+	assert.Error(t, err)
+}
+
+func TestFAME_Not(t *testing.T) {
+	a := abe.NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msg := "Attack at dawn!"
+
+	msp, err := abe.BooleanToMSP("employee AND NOT contractor", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	cipher, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// the keys of the attributes that an entity does not own are
+	// given as negated attributes
+	universe := []string{"employee", "contractor", "manager"}
+	gamma := []string{"employee", "manager"}
+	keys, err := a.GenerateAttribKeys(append(gamma, abe.NegatedAttribs(gamma, universe)...), secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	msgCheck, err := a.Decrypt(cipher, keys, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	gammaInsuff := []string{"employee", "contractor"}
+	keysInsuff, err := a.GenerateAttribKeys(append(gammaInsuff, abe.NegatedAttribs(gammaInsuff, universe)...), secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	_, err = a.Decrypt(cipher, keysInsuff, pubKey)
+	assert.Error(t, err)
+}
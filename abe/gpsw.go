@@ -17,15 +17,10 @@
 package abe
 
 import (
-	"crypto/aes"
-	cbc "crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"fmt"
 	"math/big"
 
-	"io"
-
 	"strconv"
 
 	"github.com/fentec-project/bn256"
@@ -92,12 +87,13 @@ func (a *GPSW) GenerateMasterKeys() (*GPSWPubKey, data.Vector, error) {
 
 // GPSWCipher represents a ciphertext of the GPSW ABE-scheme.
 type GPSWCipher struct {
-	Gamma     []int         // the set of attributes that can be used for policy of decryption
-	AttribToI map[int]int   // a map that connects the attributes in gamma with elements of e
-	E0        *bn256.GT     // the first part of the encryption
-	E         data.VectorG2 // the second part of the encryption
-	SymEnc    []byte        // symmetric encryption of the message
-	Iv        []byte        // initialization vector for symmetric encryption
+	Gamma      []int         // the set of attributes that can be used for policy of decryption
+	AttribToI  map[int]int   // a map that connects the attributes in gamma with elements of e
+	E0         *bn256.GT     // the first part of the encryption
+	E          data.VectorG2 // the second part of the encryption
+	SymEnc     []byte        // symmetric encryption of the message
+	Iv         []byte        // initialization vector or nonce for symmetric encryption
+	SymVersion int           // symmetric encryption used, see SymVersionGCM
 }
 
 // Encrypt takes as an input a message msg given as a string, gamma a set (slice)
@@ -105,76 +101,93 @@ type GPSWCipher struct {
 // key pk. It returns an encryption of msg. In case of a failed procedure an
 // error is returned.
 func (a *GPSW) Encrypt(msg string, gamma interface{}, pk *GPSWPubKey) (*GPSWCipher, error) {
-	var gammaI []int
-	switch gamma.(type) {
-	default:
-		return nil, fmt.Errorf("attributes should be of type []int or []string of integers")
-	case []int:
-		gammaI = gamma.([]int)
-	case []string:
-		gammaI = make([]int, len(gamma.([]string)))
-		for i, e := range gamma.([]string) {
-			att, err := strconv.Atoi(e)
-			if err != nil {
-				return nil, err
-			}
-			gammaI[i] = att
-		}
+	// msg is encrypted using AES-GCM, with a key derived from a random
+	// element of GT that is encapsulated with GPSW
+	cipher, keyGt, err := a.encapsulate(gamma, pk)
+	if err != nil {
+		return nil, err
 	}
-
-	// msg is encrypted using CBC, with a random key that is encapsulated
-	// with GPSW
-	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	cipher.Iv, err = newNonce()
 	if err != nil {
 		return nil, err
 	}
-	keyCBC := sha256.Sum256([]byte(keyGt.String()))
-
-	c, err := aes.NewCipher(keyCBC[:])
+	cipher.SymVersion = SymVersionGCM
+	header, err := cipher.header()
 	if err != nil {
 		return nil, err
 	}
-
-	iv := make([]byte, c.BlockSize())
-	_, err = io.ReadFull(rand.Reader, iv)
+	cipher.SymEnc, err = sealSym(keyGt, "abe.GPSWCipher", cipher.Iv, []byte(msg), header)
 	if err != nil {
 		return nil, err
 	}
-	encrypterCBC := cbc.NewCBCEncrypter(c, iv)
-
-	msgByte := []byte(msg)
-	// message is padded according to pkcs7 standard
-	padLen := c.BlockSize() - (len(msgByte) % c.BlockSize())
-	msgPad := make([]byte, len(msgByte)+padLen)
-	copy(msgPad, msgByte)
-	for i := len(msgByte); i < len(msgPad); i++ {
-		msgPad[i] = byte(padLen)
+
+	return cipher, nil
+}
+
+// encapsulate encapsulates a random element of GT with GPSW under the
+// attributes gamma. It returns a ciphertext without the symmetric
+// encryption of a message and the element of GT, from which the key
+// for the symmetric encryption is derived.
+func (a *GPSW) encapsulate(gamma interface{}, pk *GPSWPubKey) (*GPSWCipher, *bn256.GT, error) {
+	gammaI, err := gpswAttribs(gamma)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipher, err := a.encapsulateWith(gammaI, pk, keyGt, sample.NewUniform(a.Params.P))
+	if err != nil {
+		return nil, nil, err
 	}
 
-	symEnc := make([]byte, len(msgPad))
-	encrypterCBC.CryptBlocks(symEnc, msgPad)
+	return cipher, keyGt, nil
+}
 
-	// encapsulate the key with GPSW
-	sampler := sample.NewUniform(a.Params.P)
+// gpswAttribs converts the attributes gamma, given as in Encrypt,
+// to integers.
+func gpswAttribs(gamma interface{}) ([]int, error) {
+	switch gamma := gamma.(type) {
+	case []int:
+		return gamma, nil
+	case []string:
+		gammaI := make([]int, len(gamma))
+		for i, e := range gamma {
+			att, err := strconv.Atoi(e)
+			if err != nil {
+				return nil, err
+			}
+			gammaI[i] = att
+		}
+		return gammaI, nil
+	default:
+		return nil, fmt.Errorf("attributes should be of type []int or []string of integers")
+	}
+}
+
+// encapsulateWith encapsulates the element keyGt of GT with GPSW under
+// the attributes gamma, taking the randomness of the encapsulation from
+// sampler.
+func (a *GPSW) encapsulateWith(gamma []int, pk *GPSWPubKey, keyGt *bn256.GT, sampler sample.Sampler) (*GPSWCipher, error) {
 	s, err := sampler.Sample()
 	if err != nil {
 		return nil, err
 	}
 
 	e0 := new(bn256.GT).Add(keyGt, new(bn256.GT).ScalarMult(pk.Y, s))
-	e := make(data.VectorG2, len(gammaI))
+	e := make(data.VectorG2, len(gamma))
 	attribToI := make(map[int]int)
-	for i, el := range gammaI {
+	for i, el := range gamma {
+		if el < 0 || el >= len(pk.T) {
+			return nil, fmt.Errorf("attributes not in the universe of a")
+		}
 		e[i] = new(bn256.G2).ScalarMult(pk.T[el], s)
 		attribToI[el] = i
 	}
 
-	return &GPSWCipher{Gamma: gammaI,
-		AttribToI: attribToI,
-		E0:        e0,
-		E:         e,
-		SymEnc:    symEnc,
-		Iv:        iv}, nil
+	return &GPSWCipher{Gamma: gamma, AttribToI: attribToI, E0: e0, E: e}, nil
 }
 
 // GPSWKey represents a key structure for decrypting a ciphertext. It includes
@@ -253,34 +266,62 @@ func getSum(y *big.Int, p *big.Int, d int) (data.Vector, error) {
 // ciphertext span the vector [1, 1,..., 1]. If this is not possible, an
 //error is returned.
 func (a *GPSW) Decrypt(cipher *GPSWCipher, key *GPSWKey) (string, error) {
-	// get intersection of gamma and attributes used in the key policy
+	keyGt, err := a.decapsulate(cipher, key)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := openSym(cipher.SymVersion, keyGt, "abe.GPSWCipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
+
+// decapsulate recovers the element of GT encapsulated in the cipher,
+// from which the key for the symmetric encryption is derived.
+func (a *GPSW) decapsulate(cipher *GPSWCipher, key *GPSWKey) (*bn256.GT, error) {
+	if err := a.checkCipher(cipher); err != nil {
+		return nil, err
+	}
+
+	// find a minimal set of rows of the key policy mapped to the
+	// attributes of gamma and a combination alpha of them needed
+	// to decrypt
 	gammaMap := make(map[int]bool)
 	for _, e := range cipher.Gamma {
 		gammaMap[e] = true
 	}
-	intersection := make([]int, 0)
-	mat := make(data.Matrix, 0)
-	d := make(data.VectorG1, 0)
-	for i := 0; i < len(key.Msp.Mat); i++ {
-		attrib, err := strconv.Atoi(key.Msp.RowToAttrib[i])
+	owned := make([]string, 0)
+	for _, at := range key.Msp.RowToAttrib {
+		attrib, err := strconv.Atoi(at)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if gammaMap[attrib] {
-			intersection = append(intersection, attrib)
-			mat = append(mat, key.Msp.Mat[i])
-			d = append(d, key.D[i])
+			owned = append(owned, at)
 		}
 	}
-
-	// get a combination alpha of keys needed to decrypt
-	ones := data.NewConstantVector(len(mat[0]), big.NewInt(1))
-	alpha, err := data.GaussianEliminationSolver(mat.Transpose(), ones, a.Params.P)
+	rows, alpha, err := key.Msp.Reconstruct(owned, true)
 	if err != nil {
-		return "", fmt.Errorf("the provided key is not sufficient for the decryption")
+		return nil, fmt.Errorf("the provided key is not sufficient for the decryption")
+	}
+	intersection := make([]int, len(rows))
+	d := make(data.VectorG1, len(rows))
+	for i, row := range rows {
+		intersection[i], err = strconv.Atoi(key.Msp.RowToAttrib[row])
+		if err != nil {
+			return nil, err
+		}
+		if j, ok := cipher.AttribToI[intersection[i]]; !ok || j < 0 || j >= len(cipher.E) {
+			return nil, fmt.Errorf("the provided cipher is faulty")
+		}
+		d[i] = key.D[row]
 	}
 
-	// get a CBC key needed for the decryption of msg
+	// get the element of GT from which the key for the decryption
+	// of msg is derived
 	keyGt := new(bn256.GT).Set(cipher.E0)
 	for i := 0; i < len(alpha); i++ {
 		pair := bn256.Pair(d[i], cipher.E[cipher.AttribToI[intersection[i]]])
@@ -289,23 +330,28 @@ func (a *GPSW) Decrypt(cipher *GPSWCipher, key *GPSWKey) (string, error) {
 		keyGt.Add(keyGt, pair)
 	}
 
-	keyCBC := sha256.Sum256([]byte(keyGt.String()))
+	return keyGt, nil
+}
 
-	c, err := aes.NewCipher(keyCBC[:])
-	if err != nil {
-		return "", err
+// checkCipher checks that all the elements of the cipher are present
+// and that each attribute of the cipher is mapped to one of them, so
+// that a malformed cipher, possibly decoded from untrusted data, is
+// rejected before it is used in the decryption.
+func (a *GPSW) checkCipher(cipher *GPSWCipher) error {
+	faulty := fmt.Errorf("the provided cipher is faulty")
+	if cipher == nil || cipher.E0 == nil || len(cipher.E) != len(cipher.Gamma) {
+		return faulty
 	}
-
-	msgPad := make([]byte, len(cipher.SymEnc))
-	decrypter := cbc.NewCBCDecrypter(c, cipher.Iv)
-	decrypter.CryptBlocks(msgPad, cipher.SymEnc)
-
-	// unpad the message
-	padLen := int(msgPad[len(msgPad)-1])
-	if (len(msgPad) - padLen) < 0 {
-		return "", fmt.Errorf("failed to decrypt")
+	for _, e := range cipher.E {
+		if e == nil {
+			return faulty
+		}
+	}
+	for _, at := range cipher.Gamma {
+		if j, ok := cipher.AttribToI[at]; !ok || j < 0 || j >= len(cipher.E) {
+			return faulty
+		}
 	}
-	msgByte := msgPad[0:(len(msgPad) - padLen)]
 
-	return string(msgByte), nil
+	return nil
 }
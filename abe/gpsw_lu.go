@@ -0,0 +1,249 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/sample"
+)
+
+// This is the large universe variant of the key policy (KP) attribute
+// based (ABE) scheme of Goyal, Pandey, Sahai, Waters:
+// "Attribute-Based Encryption for Fine-Grained Access Control of
+// Encrypted Data"
+//
+// Unlike GPSW, attributes can be arbitrary strings that need not be
+// fixed in advance, and the public key does not depend on the number
+// of attributes. Following the random oracle variant of the large
+// universe construction, the element of the group assigned to an
+// attribute is obtained by hashing the attribute to G2. The security
+// is proved in the random oracle model.
+
+// GPSWLU represents a large universe GPSW ABE-scheme.
+type GPSWLU struct {
+	P *big.Int // order of the elliptic curve
+}
+
+// NewGPSWLU configures a new instance of the scheme.
+func NewGPSWLU() *GPSWLU {
+	return &GPSWLU{P: bn256.Order}
+}
+
+// GPSWLUPubKey represents a public key of the large universe GPSW
+// ABE-scheme.
+type GPSWLUPubKey struct {
+	Y *bn256.GT
+}
+
+// GenerateMasterKeys generates a new public key, needed for encrypting
+// data, and a secret key needed for generating keys for decryption.
+func (a *GPSWLU) GenerateMasterKeys() (*GPSWLUPubKey, *big.Int, error) {
+	sampler := sample.NewUniform(a.P)
+	sk, err := sampler.Sample()
+	if err != nil {
+		return nil, nil, err
+	}
+	y := new(bn256.GT).ScalarBaseMult(sk)
+
+	return &GPSWLUPubKey{Y: y}, sk, nil
+}
+
+// GPSWLUCipher represents a ciphertext of the large universe GPSW
+// ABE-scheme.
+type GPSWLUCipher struct {
+	Gamma      []string       // the set of attributes that can be used for policy of decryption
+	AttribToI  map[string]int // a map that connects the attributes in gamma with elements of e
+	E0         *bn256.GT      // the first part of the encryption
+	EPrime     *bn256.G1      // the randomness of the encryption in the exponent
+	E          data.VectorG2  // the hashes of the attributes raised to the randomness
+	SymEnc     []byte         // symmetric encryption of the message
+	Iv         []byte         // initialization vector or nonce for symmetric encryption
+	SymVersion int            // symmetric encryption used, see SymVersionGCM
+}
+
+// Encrypt takes as an input a message msg given as a string, gamma a set
+// (slice) of attributes that will be associated with the encryption and
+// a public key pk. It returns an encryption of msg. In case of a failed
+// procedure an error is returned.
+func (a *GPSWLU) Encrypt(msg string, gamma []string, pk *GPSWLUPubKey) (*GPSWLUCipher, error) {
+	// msg is encrypted using AES-GCM, with a key derived from a random
+	// element of GT that is encapsulated with the scheme
+	cipher, keyGt, err := a.encapsulate(gamma, pk)
+	if err != nil {
+		return nil, err
+	}
+	cipher.Iv, err = newNonce()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymVersion = SymVersionGCM
+	header, err := cipher.header()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymEnc, err = sealSym(keyGt, "abe.GPSWLUCipher", cipher.Iv, []byte(msg), header)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher, nil
+}
+
+// encapsulate encapsulates a random element of GT under the attributes
+// gamma. It returns a ciphertext without the symmetric encryption of a
+// message and the element of GT, from which the key for the symmetric
+// encryption is derived.
+func (a *GPSWLU) encapsulate(gamma []string, pk *GPSWLUPubKey) (*GPSWLUCipher, *bn256.GT, error) {
+	if len(gamma) == 0 {
+		return nil, nil, fmt.Errorf("at least one attribute is needed")
+	}
+
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sampler := sample.NewUniform(a.P)
+	s, err := sampler.Sample()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e0 := new(bn256.GT).Add(keyGt, new(bn256.GT).ScalarMult(pk.Y, s))
+	ePrime := new(bn256.G1).ScalarBaseMult(s)
+	e := make(data.VectorG2, len(gamma))
+	attribToI := make(map[string]int)
+	for i, el := range gamma {
+		if _, ok := attribToI[el]; ok {
+			return nil, nil, fmt.Errorf("attribute %q is repeated", el)
+		}
+		h, err := bn256.HashG2(el)
+		if err != nil {
+			return nil, nil, err
+		}
+		e[i] = h.ScalarMult(h, s)
+		attribToI[el] = i
+	}
+
+	return &GPSWLUCipher{Gamma: gamma, AttribToI: attribToI, E0: e0, EPrime: ePrime, E: e}, keyGt, nil
+}
+
+// GPSWLUKey represents a key structure for decrypting a ciphertext. It
+// includes a msp structure (policy) associated with the key and vectors
+// D and R representing the main part of the key.
+type GPSWLUKey struct {
+	Msp *MSP
+	D   data.VectorG2
+	R   data.VectorG1
+}
+
+// GeneratePolicyKey given a monotone span program (MSP) msp and the
+// secret key produces an ABE key associated with the policy given by
+// MSP. The rows of msp can be labeled by arbitrary strings. The key can
+// be used to decrypt any ciphertext associated with attributes that
+// satisfy the policy.
+func (a *GPSWLU) GeneratePolicyKey(msp *MSP, sk *big.Int) (*GPSWLUKey, error) {
+	if len(msp.Mat) == 0 || len(msp.Mat[0]) == 0 {
+		return nil, fmt.Errorf("empty msp matrix")
+	}
+	if len(msp.RowToAttrib) != len(msp.Mat) {
+		return nil, fmt.Errorf("the msp does not label all the rows")
+	}
+
+	u, err := getSum(sk, a.P, len(msp.Mat[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	sampler := sample.NewUniform(a.P)
+	r, err := data.NewRandomVector(len(msp.Mat), sampler)
+	if err != nil {
+		return nil, err
+	}
+
+	d := make(data.VectorG2, len(msp.Mat))
+	for i := 0; i < len(msp.Mat); i++ {
+		matTimesU, err := msp.Mat[i].Dot(u)
+		if err != nil {
+			return nil, err
+		}
+		matTimesU.Mod(matTimesU, a.P)
+		h, err := bn256.HashG2(msp.RowToAttrib[i])
+		if err != nil {
+			return nil, err
+		}
+		h.ScalarMult(h, r[i])
+		d[i] = new(bn256.G2).ScalarBaseMult(matTimesU)
+		d[i].Add(d[i], h)
+	}
+
+	return &GPSWLUKey{Msp: msp, D: d, R: r.MulG1()}, nil
+}
+
+// Decrypt takes as an input a cipher and a GPSWLUKey key and tries to
+// decrypt the cipher. This is possible if and only if the set of
+// attributes associated with the ciphertext satisfies the policy of
+// the key, otherwise an error is returned.
+func (a *GPSWLU) Decrypt(cipher *GPSWLUCipher, key *GPSWLUKey) (string, error) {
+	keyGt, err := a.decapsulate(cipher, key)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := openSym(cipher.SymVersion, keyGt, "abe.GPSWLUCipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
+
+// decapsulate recovers the element of GT encapsulated in the cipher,
+// from which the key for the symmetric encryption is derived.
+func (a *GPSWLU) decapsulate(cipher *GPSWLUCipher, key *GPSWLUKey) (*bn256.GT, error) {
+	// find a minimal set of rows of the key policy labeled by the
+	// attributes of the ciphertext and a combination alpha of them
+	// needed to decrypt
+	rows, alpha, err := key.Msp.Reconstruct(cipher.Gamma, true)
+	if err != nil {
+		return nil, fmt.Errorf("the provided key is not sufficient for the decryption")
+	}
+	for _, i := range rows {
+		j, ok := cipher.AttribToI[key.Msp.RowToAttrib[i]]
+		if !ok || j < 0 || j >= len(cipher.E) {
+			return nil, fmt.Errorf("the provided cipher is faulty")
+		}
+	}
+
+	// get the element of GT from which the key for the decryption
+	// of msg is derived
+	keyGt := new(bn256.GT).Set(cipher.E0)
+	for k, i := range rows {
+		e := cipher.E[cipher.AttribToI[key.Msp.RowToAttrib[i]]]
+		pair := bn256.Pair(cipher.EPrime, key.D[i])
+		pair.Add(pair, new(bn256.GT).Neg(bn256.Pair(key.R[i], e)))
+		pair.ScalarMult(pair, alpha[k])
+		pair.Neg(pair)
+		keyGt.Add(keyGt, pair)
+	}
+
+	return keyGt, nil
+}
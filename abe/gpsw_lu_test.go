@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abe_test
+
+import (
+	"testing"
+
+	"github.com/fentec-project/gofe/abe"
+	"github.com/fentec-project/gofe/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGPSWLU(t *testing.T) {
+	// create a new large universe GPSW struct, where attributes
+	// can be arbitrary strings
+	a := abe.NewGPSWLU()
+
+	// generate a public key and a secret key for the scheme
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+
+	// encrypt two messages with different sets of attributes
+	msg1 := "Attack at dawn!"
+	msg2 := "More chocolate!"
+	gamma1 := []string{"department:sales", "region:eu", "clearance:secret"}
+	gamma2 := []string{"department:sales", "region:us"}
+	cipher1, err := a.Encrypt(msg1, gamma1, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	cipher2, err := a.Encrypt(msg2, gamma2, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// generate a key for a policy over the attributes
+	msp, err := abe.BooleanToMSP("\"department:sales\" AND (\"region:eu\" OR \"clearance:top\")", true)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	abeKey, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+
+	emptyMsp := &abe.MSP{Mat: make(data.Matrix, 0), RowToAttrib: make([]string, 0)}
+	_, err = a.GeneratePolicyKey(emptyMsp, secKey)
+	assert.Error(t, err)
+	_, err = a.Encrypt(msg1, []string{}, pubKey)
+	assert.Error(t, err)
+	_, err = a.Encrypt(msg1, []string{"a", "a"}, pubKey)
+	assert.Error(t, err)
+
+	msgCheck, err := a.Decrypt(cipher1, abeKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg1, msgCheck)
+
+	_, err = a.Decrypt(cipher2, abeKey)
+	assert.Error(t, err)
+
+	// the attributes are bound to the ciphertext
+	tampered := *cipher2
+	tampered.Gamma = append(tampered.Gamma, "region:eu")
+	tampered.AttribToI = map[string]int{"department:sales": 0, "region:eu": 1}
+	_, err = a.Decrypt(&tampered, abeKey)
+	assert.Error(t, err)
+}
+
+func TestGPSWLU_Threshold(t *testing.T) {
+	a := abe.NewGPSWLU()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+
+	msp, err := abe.BooleanToMSP("admin OR 2OF(x, y, z)", true)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	abeKey, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+
+	msg := "Attack at dawn!"
+	for _, gamma := range [][]string{{"admin"}, {"x", "z"}, {"y", "z", "w"}} {
+		cipher, err := a.Encrypt(msg, gamma, pubKey)
+		if err != nil {
+			t.Fatalf("Failed to encrypt: %v", err)
+		}
+		msgCheck, err := a.Decrypt(cipher, abeKey)
+		if err != nil {
+			t.Fatalf("Failed to decrypt: %v", err)
+		}
+		assert.Equal(t, msg, msgCheck)
+	}
+
+	for _, gamma := range [][]string{{"x"}, {"w", "y"}, {"Admin"}} {
+		cipher, err := a.Encrypt(msg, gamma, pubKey)
+		if err != nil {
+			t.Fatalf("Failed to encrypt: %v", err)
+		}
+		_, err = a.Decrypt(cipher, abeKey)
+		assert.Error(t, err)
+	}
+}
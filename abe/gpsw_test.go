@@ -96,3 +96,44 @@ func TestGPSW(t *testing.T) {
 	_, err = a.Decrypt(cipher2, abeKey)
 	assert.Error(t, err)
 }
+
+func TestGPSW_Threshold(t *testing.T) {
+	a := abe.NewGPSW(10)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+
+	// a key for ciphertexts with attribute 0 and at least
+	// three of the attributes 1, 2, 3 and 4
+	msp, err := abe.BooleanToMSP("0 AND 3OF(1, 2, 3, 4)", true)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	abeKey, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+
+	msg := "Attack at dawn!"
+	for _, gamma := range [][]int{{0, 1, 2, 3}, {0, 2, 3, 4, 5}} {
+		cipher, err := a.Encrypt(msg, gamma, pubKey)
+		if err != nil {
+			t.Fatalf("Failed to encrypt: %v", err)
+		}
+		msgCheck, err := a.Decrypt(cipher, abeKey)
+		if err != nil {
+			t.Fatalf("Failed to decrypt: %v", err)
+		}
+		assert.Equal(t, msg, msgCheck)
+	}
+
+	for _, gamma := range [][]int{{0, 1, 2, 5}, {1, 2, 3, 4}} {
+		cipher, err := a.Encrypt(msg, gamma, pubKey)
+		if err != nil {
+			t.Fatalf("Failed to encrypt: %v", err)
+		}
+		_, err = a.Decrypt(cipher, abeKey)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,1065 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abe
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+func toJSONInts4(v [4]*big.Int) [4]*serial.BigInt {
+	var res [4]*serial.BigInt
+	for i, x := range v {
+		res[i] = (*serial.BigInt)(x)
+	}
+
+	return res
+}
+
+func fromJSONInts4(v [4]*serial.BigInt) [4]*big.Int {
+	var res [4]*big.Int
+	for i, x := range v {
+		res[i] = (*big.Int)(x)
+	}
+
+	return res
+}
+
+func toJSONG1s(v [3]*bn256.G1) [3]*serial.G1 {
+	var res [3]*serial.G1
+	for i, p := range v {
+		res[i] = (*serial.G1)(p)
+	}
+
+	return res
+}
+
+func fromJSONG1s(v [3]*serial.G1) [3]*bn256.G1 {
+	var res [3]*bn256.G1
+	for i, p := range v {
+		res[i] = (*bn256.G1)(p)
+	}
+
+	return res
+}
+
+func toJSONG2s(v [3]*bn256.G2) [3]*serial.G2 {
+	var res [3]*serial.G2
+	for i, p := range v {
+		res[i] = (*serial.G2)(p)
+	}
+
+	return res
+}
+
+func fromJSONG2s(v [3]*serial.G2) [3]*bn256.G2 {
+	var res [3]*bn256.G2
+	for i, p := range v {
+		res[i] = (*bn256.G2)(p)
+	}
+
+	return res
+}
+
+func toJSONG1Rows(v [][3]*bn256.G1) [][3]*serial.G1 {
+	if v == nil {
+		return nil
+	}
+	res := make([][3]*serial.G1, len(v))
+	for i, row := range v {
+		res[i] = toJSONG1s(row)
+	}
+
+	return res
+}
+
+func fromJSONG1Rows(v [][3]*serial.G1) [][3]*bn256.G1 {
+	if v == nil {
+		return nil
+	}
+	res := make([][3]*bn256.G1, len(v))
+	for i, row := range v {
+		res[i] = fromJSONG1s(row)
+	}
+
+	return res
+}
+
+type mspJSON struct {
+	serial.Header
+	P           *serial.BigInt `json:"p"`
+	Mat         data.Matrix    `json:"mat"`
+	RowToAttrib []string       `json:"rowToAttrib"`
+}
+
+// MarshalJSON encodes the monotone span program as a JSON object.
+func (m *MSP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(mspJSON{
+		Header:      serial.NewHeader("abe.MSP"),
+		P:           (*serial.BigInt)(m.P),
+		Mat:         m.Mat,
+		RowToAttrib: m.RowToAttrib,
+	})
+}
+
+// UnmarshalJSON decodes the monotone span program encoded with MarshalJSON into m.
+func (m *MSP) UnmarshalJSON(b []byte) error {
+	var enc mspJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.MSP"); err != nil {
+		return err
+	}
+	*m = MSP{
+		P:           (*big.Int)(enc.P),
+		Mat:         enc.Mat,
+		RowToAttrib: enc.RowToAttrib,
+	}
+
+	return nil
+}
+
+type fameSecKeyJSON struct {
+	serial.Header
+	PartInt [4]*serial.BigInt `json:"partInt"`
+	PartG1  [3]*serial.G1     `json:"partG1"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *FAMESecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fameSecKeyJSON{
+		Header:  serial.NewHeader("abe.FAMESecKey"),
+		PartInt: toJSONInts4(k.PartInt),
+		PartG1:  toJSONG1s(k.PartG1),
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *FAMESecKey) UnmarshalJSON(b []byte) error {
+	var enc fameSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.FAMESecKey"); err != nil {
+		return err
+	}
+	*k = FAMESecKey{
+		PartInt: fromJSONInts4(enc.PartInt),
+		PartG1:  fromJSONG1s(enc.PartG1),
+	}
+
+	return nil
+}
+
+type famePubKeyJSON struct {
+	serial.Header
+	PartG2 [2]*serial.G2 `json:"partG2"`
+	PartGT [2]*serial.GT `json:"partGT"`
+}
+
+// MarshalJSON encodes the public key as a JSON object.
+func (k *FAMEPubKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(famePubKeyJSON{
+		Header: serial.NewHeader("abe.FAMEPubKey"),
+		PartG2: [2]*serial.G2{(*serial.G2)(k.PartG2[0]), (*serial.G2)(k.PartG2[1])},
+		PartGT: [2]*serial.GT{(*serial.GT)(k.PartGT[0]), (*serial.GT)(k.PartGT[1])},
+	})
+}
+
+// UnmarshalJSON decodes the public key encoded with MarshalJSON into k.
+func (k *FAMEPubKey) UnmarshalJSON(b []byte) error {
+	var enc famePubKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.FAMEPubKey"); err != nil {
+		return err
+	}
+	*k = FAMEPubKey{
+		PartG2: [2]*bn256.G2{(*bn256.G2)(enc.PartG2[0]), (*bn256.G2)(enc.PartG2[1])},
+		PartGT: [2]*bn256.GT{(*bn256.GT)(enc.PartGT[0]), (*bn256.GT)(enc.PartGT[1])},
+	}
+
+	return nil
+}
+
+type fameCipherJSON struct {
+	serial.Header
+	Ct0        [3]*serial.G2   `json:"ct0"`
+	Ct         [][3]*serial.G1 `json:"ct"`
+	CtPrime    *serial.GT      `json:"ctPrime"`
+	Msp        *MSP            `json:"msp"`
+	SymEnc     []byte          `json:"symEnc"`
+	Iv         []byte          `json:"iv"`
+	SymVersion int             `json:"symVersion,omitempty"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *FAMECipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fameCipherJSON{
+		Header:     serial.NewHeader("abe.FAMECipher"),
+		Ct0:        toJSONG2s(c.Ct0),
+		Ct:         toJSONG1Rows(c.Ct),
+		CtPrime:    (*serial.GT)(c.CtPrime),
+		Msp:        c.Msp,
+		SymEnc:     c.SymEnc,
+		Iv:         c.Iv,
+		SymVersion: c.SymVersion,
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *FAMECipher) UnmarshalJSON(b []byte) error {
+	var enc fameCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.FAMECipher"); err != nil {
+		return err
+	}
+	*c = FAMECipher{
+		Ct0:        fromJSONG2s(enc.Ct0),
+		Ct:         fromJSONG1Rows(enc.Ct),
+		CtPrime:    (*bn256.GT)(enc.CtPrime),
+		Msp:        enc.Msp,
+		SymEnc:     enc.SymEnc,
+		Iv:         enc.Iv,
+		SymVersion: enc.SymVersion,
+	}
+
+	return nil
+}
+
+type fameAttribKeysJSON struct {
+	serial.Header
+	K0        [3]*serial.G2   `json:"k0"`
+	K         [][3]*serial.G1 `json:"k"`
+	KPrime    [3]*serial.G1   `json:"kPrime"`
+	AttribToI map[string]int  `json:"attribToI"`
+}
+
+// MarshalJSON encodes the attribute keys as a JSON object.
+func (k *FAMEAttribKeys) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fameAttribKeysJSON{
+		Header:    serial.NewHeader("abe.FAMEAttribKeys"),
+		K0:        toJSONG2s(k.K0),
+		K:         toJSONG1Rows(k.K),
+		KPrime:    toJSONG1s(k.KPrime),
+		AttribToI: k.AttribToI,
+	})
+}
+
+// UnmarshalJSON decodes the attribute keys encoded with MarshalJSON into k.
+func (k *FAMEAttribKeys) UnmarshalJSON(b []byte) error {
+	var enc fameAttribKeysJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.FAMEAttribKeys"); err != nil {
+		return err
+	}
+	*k = FAMEAttribKeys{
+		K0:        fromJSONG2s(enc.K0),
+		K:         fromJSONG1Rows(enc.K),
+		KPrime:    fromJSONG1s(enc.KPrime),
+		AttribToI: enc.AttribToI,
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the transformation key as a JSON object.
+func (k *FAMETransformKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fameAttribKeysJSON{
+		Header:    serial.NewHeader("abe.FAMETransformKey"),
+		K0:        toJSONG2s(k.K0),
+		K:         toJSONG1Rows(k.K),
+		KPrime:    toJSONG1s(k.KPrime),
+		AttribToI: k.AttribToI,
+	})
+}
+
+// UnmarshalJSON decodes the transformation key encoded with MarshalJSON
+// into k.
+func (k *FAMETransformKey) UnmarshalJSON(b []byte) error {
+	var enc fameAttribKeysJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.FAMETransformKey"); err != nil {
+		return err
+	}
+	*k = FAMETransformKey{
+		K0:        fromJSONG2s(enc.K0),
+		K:         fromJSONG1Rows(enc.K),
+		KPrime:    fromJSONG1s(enc.KPrime),
+		AttribToI: enc.AttribToI,
+	}
+
+	return nil
+}
+
+type fameTransformedCipherJSON struct {
+	serial.Header
+	CtPrime *serial.GT `json:"ctPrime"`
+	CtBlind *serial.GT `json:"ctBlind"`
+}
+
+// MarshalJSON encodes the transformed ciphertext as a JSON object.
+func (c *FAMETransformedCipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fameTransformedCipherJSON{
+		Header:  serial.NewHeader("abe.FAMETransformedCipher"),
+		CtPrime: (*serial.GT)(c.CtPrime),
+		CtBlind: (*serial.GT)(c.CtBlind),
+	})
+}
+
+// UnmarshalJSON decodes the transformed ciphertext encoded with
+// MarshalJSON into c.
+func (c *FAMETransformedCipher) UnmarshalJSON(b []byte) error {
+	var enc fameTransformedCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.FAMETransformedCipher"); err != nil {
+		return err
+	}
+	*c = FAMETransformedCipher{
+		CtPrime: (*bn256.GT)(enc.CtPrime),
+		CtBlind: (*bn256.GT)(enc.CtBlind),
+	}
+
+	return nil
+}
+
+type gpswPubKeyJSON struct {
+	serial.Header
+	T data.VectorG2 `json:"t"`
+	Y *serial.GT    `json:"y"`
+}
+
+// MarshalJSON encodes the public key as a JSON object.
+func (k *GPSWPubKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gpswPubKeyJSON{
+		Header: serial.NewHeader("abe.GPSWPubKey"),
+		T:      k.T,
+		Y:      (*serial.GT)(k.Y),
+	})
+}
+
+// UnmarshalJSON decodes the public key encoded with MarshalJSON into k.
+func (k *GPSWPubKey) UnmarshalJSON(b []byte) error {
+	var enc gpswPubKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.GPSWPubKey"); err != nil {
+		return err
+	}
+	*k = GPSWPubKey{
+		T: enc.T,
+		Y: (*bn256.GT)(enc.Y),
+	}
+
+	return nil
+}
+
+type gpswCipherJSON struct {
+	serial.Header
+	Gamma      []int         `json:"gamma"`
+	AttribToI  map[int]int   `json:"attribToI"`
+	E0         *serial.GT    `json:"e0"`
+	E          data.VectorG2 `json:"e"`
+	SymEnc     []byte        `json:"symEnc"`
+	Iv         []byte        `json:"iv"`
+	SymVersion int           `json:"symVersion,omitempty"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *GPSWCipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gpswCipherJSON{
+		Header:     serial.NewHeader("abe.GPSWCipher"),
+		Gamma:      c.Gamma,
+		AttribToI:  c.AttribToI,
+		E0:         (*serial.GT)(c.E0),
+		E:          c.E,
+		SymEnc:     c.SymEnc,
+		Iv:         c.Iv,
+		SymVersion: c.SymVersion,
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *GPSWCipher) UnmarshalJSON(b []byte) error {
+	var enc gpswCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.GPSWCipher"); err != nil {
+		return err
+	}
+	*c = GPSWCipher{
+		Gamma:      enc.Gamma,
+		AttribToI:  enc.AttribToI,
+		E0:         (*bn256.GT)(enc.E0),
+		E:          enc.E,
+		SymEnc:     enc.SymEnc,
+		Iv:         enc.Iv,
+		SymVersion: enc.SymVersion,
+	}
+
+	return nil
+}
+
+type gpswKeyJSON struct {
+	serial.Header
+	Msp *MSP          `json:"msp"`
+	D   data.VectorG1 `json:"d"`
+}
+
+// MarshalJSON encodes the policy key as a JSON object.
+func (k *GPSWKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gpswKeyJSON{
+		Header: serial.NewHeader("abe.GPSWKey"),
+		Msp:    k.Msp,
+		D:      k.D,
+	})
+}
+
+// UnmarshalJSON decodes the policy key encoded with MarshalJSON into k.
+func (k *GPSWKey) UnmarshalJSON(b []byte) error {
+	var enc gpswKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.GPSWKey"); err != nil {
+		return err
+	}
+	*k = GPSWKey{
+		Msp: enc.Msp,
+		D:   enc.D,
+	}
+
+	return nil
+}
+
+type gpswLUPubKeyJSON struct {
+	serial.Header
+	Y *serial.GT `json:"y"`
+}
+
+// MarshalJSON encodes the public key as a JSON object.
+func (k *GPSWLUPubKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gpswLUPubKeyJSON{
+		Header: serial.NewHeader("abe.GPSWLUPubKey"),
+		Y:      (*serial.GT)(k.Y),
+	})
+}
+
+// UnmarshalJSON decodes the public key encoded with MarshalJSON into k.
+func (k *GPSWLUPubKey) UnmarshalJSON(b []byte) error {
+	var enc gpswLUPubKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.GPSWLUPubKey"); err != nil {
+		return err
+	}
+	*k = GPSWLUPubKey{
+		Y: (*bn256.GT)(enc.Y),
+	}
+
+	return nil
+}
+
+type gpswLUCipherJSON struct {
+	serial.Header
+	Gamma      []string       `json:"gamma"`
+	AttribToI  map[string]int `json:"attribToI"`
+	E0         *serial.GT     `json:"e0"`
+	EPrime     *serial.G1     `json:"ePrime"`
+	E          data.VectorG2  `json:"e"`
+	SymEnc     []byte         `json:"symEnc"`
+	Iv         []byte         `json:"iv"`
+	SymVersion int            `json:"symVersion,omitempty"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *GPSWLUCipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gpswLUCipherJSON{
+		Header:     serial.NewHeader("abe.GPSWLUCipher"),
+		Gamma:      c.Gamma,
+		AttribToI:  c.AttribToI,
+		E0:         (*serial.GT)(c.E0),
+		EPrime:     (*serial.G1)(c.EPrime),
+		E:          c.E,
+		SymEnc:     c.SymEnc,
+		Iv:         c.Iv,
+		SymVersion: c.SymVersion,
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *GPSWLUCipher) UnmarshalJSON(b []byte) error {
+	var enc gpswLUCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.GPSWLUCipher"); err != nil {
+		return err
+	}
+	*c = GPSWLUCipher{
+		Gamma:      enc.Gamma,
+		AttribToI:  enc.AttribToI,
+		E0:         (*bn256.GT)(enc.E0),
+		EPrime:     (*bn256.G1)(enc.EPrime),
+		E:          enc.E,
+		SymEnc:     enc.SymEnc,
+		Iv:         enc.Iv,
+		SymVersion: enc.SymVersion,
+	}
+
+	return nil
+}
+
+type gpswLUKeyJSON struct {
+	serial.Header
+	Msp *MSP          `json:"msp"`
+	D   data.VectorG2 `json:"d"`
+	R   data.VectorG1 `json:"r"`
+}
+
+// MarshalJSON encodes the policy key as a JSON object.
+func (k *GPSWLUKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gpswLUKeyJSON{
+		Header: serial.NewHeader("abe.GPSWLUKey"),
+		Msp:    k.Msp,
+		D:      k.D,
+		R:      k.R,
+	})
+}
+
+// UnmarshalJSON decodes the policy key encoded with MarshalJSON into k.
+func (k *GPSWLUKey) UnmarshalJSON(b []byte) error {
+	var enc gpswLUKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.GPSWLUKey"); err != nil {
+		return err
+	}
+	*k = GPSWLUKey{
+		Msp: enc.Msp,
+		D:   enc.D,
+		R:   enc.R,
+	}
+
+	return nil
+}
+
+type dippePubKeyJSON struct {
+	serial.Header
+	G1ToWtA   data.MatrixG1 `json:"g1ToWtA"`
+	GToAlphaA data.VectorGT `json:"gToAlphaA"`
+	G2ToSigma *serial.G2    `json:"g2ToSigma"`
+}
+
+// MarshalJSON encodes the public key as a JSON object.
+func (k *DIPPEPubKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dippePubKeyJSON{
+		Header:    serial.NewHeader("abe.DIPPEPubKey"),
+		G1ToWtA:   k.G1ToWtA,
+		GToAlphaA: k.GToAlphaA,
+		G2ToSigma: (*serial.G2)(k.G2ToSigma),
+	})
+}
+
+// UnmarshalJSON decodes the public key encoded with MarshalJSON into k.
+func (k *DIPPEPubKey) UnmarshalJSON(b []byte) error {
+	var enc dippePubKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.DIPPEPubKey"); err != nil {
+		return err
+	}
+	*k = DIPPEPubKey{
+		G1ToWtA:   enc.G1ToWtA,
+		GToAlphaA: enc.GToAlphaA,
+		G2ToSigma: (*bn256.G2)(enc.G2ToSigma),
+	}
+
+	return nil
+}
+
+type dippeSecKeyJSON struct {
+	serial.Header
+	Sigma *serial.BigInt `json:"sigma"`
+	W     data.Matrix    `json:"w"`
+	Alpha data.Vector    `json:"alpha"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *DIPPESecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dippeSecKeyJSON{
+		Header: serial.NewHeader("abe.DIPPESecKey"),
+		Sigma:  (*serial.BigInt)(k.Sigma),
+		W:      k.W,
+		Alpha:  k.Alpha,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *DIPPESecKey) UnmarshalJSON(b []byte) error {
+	var enc dippeSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.DIPPESecKey"); err != nil {
+		return err
+	}
+	*k = DIPPESecKey{
+		Sigma: (*big.Int)(enc.Sigma),
+		W:     enc.W,
+		Alpha: enc.Alpha,
+	}
+
+	return nil
+}
+
+type dippeCipherJSON struct {
+	serial.Header
+	C0         data.VectorG1 `json:"c0"`
+	C          data.MatrixG1 `json:"c"`
+	CPrime     *serial.GT    `json:"cPrime"`
+	X          data.Vector   `json:"x"`
+	SymEnc     []byte        `json:"symEnc"`
+	Iv         []byte        `json:"iv"`
+	SymVersion int           `json:"symVersion,omitempty"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *DIPPECipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dippeCipherJSON{
+		Header:     serial.NewHeader("abe.DIPPECipher"),
+		C0:         c.C0,
+		C:          c.C,
+		CPrime:     (*serial.GT)(c.CPrime),
+		X:          c.X,
+		SymEnc:     c.SymEnc,
+		Iv:         c.Iv,
+		SymVersion: c.SymVersion,
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *DIPPECipher) UnmarshalJSON(b []byte) error {
+	var enc dippeCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.DIPPECipher"); err != nil {
+		return err
+	}
+	*c = DIPPECipher{
+		C0:         enc.C0,
+		C:          enc.C,
+		CPrime:     (*bn256.GT)(enc.CPrime),
+		X:          enc.X,
+		SymEnc:     enc.SymEnc,
+		Iv:         enc.Iv,
+		SymVersion: enc.SymVersion,
+	}
+
+	return nil
+}
+
+type dippeAuthJSON struct {
+	serial.Header
+	ID int          `json:"id"`
+	Sk *DIPPESecKey `json:"sk"`
+	Pk *DIPPEPubKey `json:"pk"`
+}
+
+// MarshalJSON encodes the authority as a JSON object.
+func (a *DIPPEAuth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dippeAuthJSON{
+		Header: serial.NewHeader("abe.DIPPEAuth"),
+		ID:     a.ID,
+		Sk:     &a.Sk,
+		Pk:     &a.Pk,
+	})
+}
+
+// UnmarshalJSON decodes the authority encoded with MarshalJSON into a.
+func (a *DIPPEAuth) UnmarshalJSON(b []byte) error {
+	var enc dippeAuthJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.DIPPEAuth"); err != nil {
+		return err
+	}
+	if enc.Sk == nil || enc.Pk == nil {
+		return fmt.Errorf("cannot decode abe.DIPPEAuth: missing keys")
+	}
+	*a = DIPPEAuth{
+		ID: enc.ID,
+		Sk: *enc.Sk,
+		Pk: *enc.Pk,
+	}
+
+	return nil
+}
+
+type maabePubKeyJSON struct {
+	serial.Header
+	Attribs    []string      `json:"attribs"`
+	EggToAlpha data.VectorGT `json:"eggToAlpha"`
+	G1ToY      data.VectorG1 `json:"g1ToY"`
+}
+
+// MarshalJSON encodes the public key as a JSON object.
+func (k *MAABEPubKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(maabePubKeyJSON{
+		Header:     serial.NewHeader("abe.MAABEPubKey"),
+		Attribs:    k.Attribs,
+		EggToAlpha: k.EggToAlpha,
+		G1ToY:      k.G1ToY,
+	})
+}
+
+// UnmarshalJSON decodes the public key encoded with MarshalJSON into k.
+func (k *MAABEPubKey) UnmarshalJSON(b []byte) error {
+	var enc maabePubKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.MAABEPubKey"); err != nil {
+		return err
+	}
+	*k = MAABEPubKey{
+		Attribs:    enc.Attribs,
+		EggToAlpha: enc.EggToAlpha,
+		G1ToY:      enc.G1ToY,
+	}
+
+	return nil
+}
+
+type maabeSecKeyJSON struct {
+	serial.Header
+	Attribs []string    `json:"attribs"`
+	Alpha   data.Vector `json:"alpha"`
+	Y       data.Vector `json:"y"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *MAABESecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(maabeSecKeyJSON{
+		Header:  serial.NewHeader("abe.MAABESecKey"),
+		Attribs: k.Attribs,
+		Alpha:   k.Alpha,
+		Y:       k.Y,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *MAABESecKey) UnmarshalJSON(b []byte) error {
+	var enc maabeSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.MAABESecKey"); err != nil {
+		return err
+	}
+	*k = MAABESecKey{
+		Attribs: enc.Attribs,
+		Alpha:   enc.Alpha,
+		Y:       enc.Y,
+	}
+
+	return nil
+}
+
+type maabeAuthJSON struct {
+	serial.Header
+	ID string       `json:"id"`
+	Sk *MAABESecKey `json:"sk"`
+	Pk *MAABEPubKey `json:"pk"`
+}
+
+// MarshalJSON encodes the authority as a JSON object.
+func (a *MAABEAuth) MarshalJSON() ([]byte, error) {
+	return json.Marshal(maabeAuthJSON{
+		Header: serial.NewHeader("abe.MAABEAuth"),
+		ID:     a.ID,
+		Sk:     &a.Sk,
+		Pk:     &a.Pk,
+	})
+}
+
+// UnmarshalJSON decodes the authority encoded with MarshalJSON into a.
+func (a *MAABEAuth) UnmarshalJSON(b []byte) error {
+	var enc maabeAuthJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.MAABEAuth"); err != nil {
+		return err
+	}
+	if enc.Sk == nil || enc.Pk == nil {
+		return fmt.Errorf("cannot decode abe.MAABEAuth: missing keys")
+	}
+	*a = MAABEAuth{
+		ID: enc.ID,
+		Sk: *enc.Sk,
+		Pk: *enc.Pk,
+	}
+
+	return nil
+}
+
+type maabeKeyJSON struct {
+	serial.Header
+	Gid    string     `json:"gid"`
+	Attrib string     `json:"attrib"`
+	Key    *serial.G2 `json:"key"`
+}
+
+// MarshalJSON encodes the attribute key as a JSON object.
+func (k *MAABEKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(maabeKeyJSON{
+		Header: serial.NewHeader("abe.MAABEKey"),
+		Gid:    k.Gid,
+		Attrib: k.Attrib,
+		Key:    (*serial.G2)(k.Key),
+	})
+}
+
+// UnmarshalJSON decodes the attribute key encoded with MarshalJSON into k.
+func (k *MAABEKey) UnmarshalJSON(b []byte) error {
+	var enc maabeKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.MAABEKey"); err != nil {
+		return err
+	}
+	*k = MAABEKey{
+		Gid:    enc.Gid,
+		Attrib: enc.Attrib,
+		Key:    (*bn256.G2)(enc.Key),
+	}
+
+	return nil
+}
+
+type maabeCipherJSON struct {
+	serial.Header
+	Msp        *MSP          `json:"msp"`
+	C0         *serial.GT    `json:"c0"`
+	C1         data.VectorGT `json:"c1"`
+	C2         data.VectorG1 `json:"c2"`
+	C3         data.VectorG1 `json:"c3"`
+	SymEnc     []byte        `json:"symEnc"`
+	Iv         []byte        `json:"iv"`
+	SymVersion int           `json:"symVersion,omitempty"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *MAABECipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(maabeCipherJSON{
+		Header:     serial.NewHeader("abe.MAABECipher"),
+		Msp:        c.Msp,
+		C0:         (*serial.GT)(c.C0),
+		C1:         c.C1,
+		C2:         c.C2,
+		C3:         c.C3,
+		SymEnc:     c.SymEnc,
+		Iv:         c.Iv,
+		SymVersion: c.SymVersion,
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *MAABECipher) UnmarshalJSON(b []byte) error {
+	var enc maabeCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.MAABECipher"); err != nil {
+		return err
+	}
+	*c = MAABECipher{
+		Msp:        enc.Msp,
+		C0:         (*bn256.GT)(enc.C0),
+		C1:         enc.C1,
+		C2:         enc.C2,
+		C3:         enc.C3,
+		SymEnc:     enc.SymEnc,
+		Iv:         enc.Iv,
+		SymVersion: enc.SymVersion,
+	}
+
+	return nil
+}
+
+type revocableFAMESecKeyJSON struct {
+	serial.Header
+	Sk   *FAMESecKey `json:"sk"`
+	Seed []byte      `json:"seed"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *RevocableFAMESecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(revocableFAMESecKeyJSON{
+		Header: serial.NewHeader("abe.RevocableFAMESecKey"),
+		Sk:     k.Sk,
+		Seed:   k.Seed,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *RevocableFAMESecKey) UnmarshalJSON(b []byte) error {
+	var enc revocableFAMESecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.RevocableFAMESecKey"); err != nil {
+		return err
+	}
+	*k = RevocableFAMESecKey{
+		Sk:   enc.Sk,
+		Seed: enc.Seed,
+	}
+
+	return nil
+}
+
+type revocableFAMEKeyJSON struct {
+	serial.Header
+	Leaf  int               `json:"leaf"`
+	Nodes []int             `json:"nodes"`
+	Keys  []*FAMEAttribKeys `json:"keys"`
+}
+
+// MarshalJSON encodes the keys of a user as a JSON object.
+func (k *RevocableFAMEKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(revocableFAMEKeyJSON{
+		Header: serial.NewHeader("abe.RevocableFAMEKey"),
+		Leaf:   k.Leaf,
+		Nodes:  k.Nodes,
+		Keys:   k.Keys,
+	})
+}
+
+// UnmarshalJSON decodes the keys of a user encoded with MarshalJSON
+// into k.
+func (k *RevocableFAMEKey) UnmarshalJSON(b []byte) error {
+	var enc revocableFAMEKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.RevocableFAMEKey"); err != nil {
+		return err
+	}
+	*k = RevocableFAMEKey{
+		Leaf:  enc.Leaf,
+		Nodes: enc.Nodes,
+		Keys:  enc.Keys,
+	}
+
+	return nil
+}
+
+type revocableFAMEUpdateJSON struct {
+	serial.Header
+	Epoch int               `json:"epoch"`
+	Nodes []int             `json:"nodes"`
+	Keys  []*FAMEAttribKeys `json:"keys"`
+}
+
+// MarshalJSON encodes the update of the keys as a JSON object.
+func (u *RevocableFAMEUpdate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(revocableFAMEUpdateJSON{
+		Header: serial.NewHeader("abe.RevocableFAMEUpdate"),
+		Epoch:  u.Epoch,
+		Nodes:  u.Nodes,
+		Keys:   u.Keys,
+	})
+}
+
+// UnmarshalJSON decodes the update of the keys encoded with MarshalJSON
+// into u.
+func (u *RevocableFAMEUpdate) UnmarshalJSON(b []byte) error {
+	var enc revocableFAMEUpdateJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.RevocableFAMEUpdate"); err != nil {
+		return err
+	}
+	*u = RevocableFAMEUpdate{
+		Epoch: enc.Epoch,
+		Nodes: enc.Nodes,
+		Keys:  enc.Keys,
+	}
+
+	return nil
+}
+
+type revocableFAMECipherJSON struct {
+	serial.Header
+	Cipher  *FAMECipher   `json:"cipher"`
+	Epoch   int           `json:"epoch"`
+	CtEpoch [3]*serial.G1 `json:"ctEpoch"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *RevocableFAMECipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(revocableFAMECipherJSON{
+		Header:  serial.NewHeader("abe.RevocableFAMECipher"),
+		Cipher:  c.Cipher,
+		Epoch:   c.Epoch,
+		CtEpoch: toJSONG1s(c.CtEpoch),
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *RevocableFAMECipher) UnmarshalJSON(b []byte) error {
+	var enc revocableFAMECipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("abe.RevocableFAMECipher"); err != nil {
+		return err
+	}
+	*c = RevocableFAMECipher{
+		Cipher:  enc.Cipher,
+		Epoch:   enc.Epoch,
+		CtEpoch: fromJSONG1s(enc.CtEpoch),
+	}
+
+	return nil
+}
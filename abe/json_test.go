@@ -0,0 +1,315 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fentec-project/gofe/abe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFAME_JSON(t *testing.T) {
+	a := abe.NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("(0 AND 1) OR 2", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	msg := "Attack at dawn!"
+	cipher, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	keys, err := a.GenerateAttribKeys([]string{"0", "1"}, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+
+	// the ciphertext and the keys are sent to a decryptor as JSON
+	cipherJSON, err := json.Marshal(cipher)
+	if err != nil {
+		t.Fatalf("Failed to encode the ciphertext: %v", err)
+	}
+	keysJSON, err := json.Marshal(keys)
+	if err != nil {
+		t.Fatalf("Failed to encode the keys: %v", err)
+	}
+	pubKeyJSON, err := json.Marshal(pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encode the public key: %v", err)
+	}
+
+	var decCipher abe.FAMECipher
+	if err := json.Unmarshal(cipherJSON, &decCipher); err != nil {
+		t.Fatalf("Failed to decode the ciphertext: %v", err)
+	}
+	var decKeys abe.FAMEAttribKeys
+	if err := json.Unmarshal(keysJSON, &decKeys); err != nil {
+		t.Fatalf("Failed to decode the keys: %v", err)
+	}
+	var decPubKey abe.FAMEPubKey
+	if err := json.Unmarshal(pubKeyJSON, &decPubKey); err != nil {
+		t.Fatalf("Failed to decode the public key: %v", err)
+	}
+	assert.Equal(t, msp, decCipher.Msp)
+
+	msgCheck, err := a.Decrypt(&decCipher, &decKeys, &decPubKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	// objects of other types are rejected
+	assert.Error(t, json.Unmarshal(cipherJSON, &decKeys))
+}
+
+func TestGPSW_JSON(t *testing.T) {
+	a := abe.NewGPSW(4)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("1 OR (2 AND 3)", true)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	msg := "Attack at dawn!"
+	cipher, err := a.Encrypt(msg, []int{2, 3}, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	key, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate policy key: %v", err)
+	}
+
+	cipherJSON, err := json.Marshal(cipher)
+	if err != nil {
+		t.Fatalf("Failed to encode the ciphertext: %v", err)
+	}
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		t.Fatalf("Failed to encode the key: %v", err)
+	}
+
+	var decCipher abe.GPSWCipher
+	if err := json.Unmarshal(cipherJSON, &decCipher); err != nil {
+		t.Fatalf("Failed to decode the ciphertext: %v", err)
+	}
+	var decKey abe.GPSWKey
+	if err := json.Unmarshal(keyJSON, &decKey); err != nil {
+		t.Fatalf("Failed to decode the key: %v", err)
+	}
+	assert.Equal(t, cipher.AttribToI, decCipher.AttribToI)
+
+	msgCheck, err := a.Decrypt(&decCipher, &decKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+}
+
+func TestGPSWLU_JSON(t *testing.T) {
+	a := abe.NewGPSWLU()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("a OR (b AND c)", true)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	pubKeyJSON, err := json.Marshal(pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encode the public key: %v", err)
+	}
+	var decPubKey abe.GPSWLUPubKey
+	if err := json.Unmarshal(pubKeyJSON, &decPubKey); err != nil {
+		t.Fatalf("Failed to decode the public key: %v", err)
+	}
+
+	msg := "Attack at dawn!"
+	cipher, err := a.Encrypt(msg, []string{"b", "c"}, &decPubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	key, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate policy key: %v", err)
+	}
+
+	cipherJSON, err := json.Marshal(cipher)
+	if err != nil {
+		t.Fatalf("Failed to encode the ciphertext: %v", err)
+	}
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		t.Fatalf("Failed to encode the key: %v", err)
+	}
+
+	var decCipher abe.GPSWLUCipher
+	if err := json.Unmarshal(cipherJSON, &decCipher); err != nil {
+		t.Fatalf("Failed to decode the ciphertext: %v", err)
+	}
+	var decKey abe.GPSWLUKey
+	if err := json.Unmarshal(keyJSON, &decKey); err != nil {
+		t.Fatalf("Failed to decode the key: %v", err)
+	}
+	assert.Equal(t, cipher.AttribToI, decCipher.AttribToI)
+
+	msgCheck, err := a.Decrypt(&decCipher, &decKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	// objects of other types are rejected
+	assert.Error(t, json.Unmarshal(cipherJSON, &decKey))
+}
+
+func TestMAABE_JSON(t *testing.T) {
+	a := abe.NewMAABE()
+	auth, err := a.NewMAABEAuth("auth1", []string{"auth1:at1", "auth1:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate a new authority: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("auth1:at1 OR auth1:at2", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	authJSON, err := json.Marshal(auth)
+	if err != nil {
+		t.Fatalf("Failed to encode the authority: %v", err)
+	}
+	var decAuth abe.MAABEAuth
+	if err := json.Unmarshal(authJSON, &decAuth); err != nil {
+		t.Fatalf("Failed to decode the authority: %v", err)
+	}
+
+	msg := "Attack at dawn!"
+	cipher, err := a.Encrypt(msg, msp, []*abe.MAABEPubKey{&decAuth.Pk})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	keys, err := decAuth.GenerateAttribKeys("gid", []string{"auth1:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate attribute keys: %v", err)
+	}
+
+	cipherJSON, err := json.Marshal(cipher)
+	if err != nil {
+		t.Fatalf("Failed to encode the ciphertext: %v", err)
+	}
+	keyJSON, err := json.Marshal(keys[0])
+	if err != nil {
+		t.Fatalf("Failed to encode the key: %v", err)
+	}
+
+	var decCipher abe.MAABECipher
+	if err := json.Unmarshal(cipherJSON, &decCipher); err != nil {
+		t.Fatalf("Failed to decode the ciphertext: %v", err)
+	}
+	var decKey abe.MAABEKey
+	if err := json.Unmarshal(keyJSON, &decKey); err != nil {
+		t.Fatalf("Failed to decode the key: %v", err)
+	}
+
+	msgCheck, err := a.Decrypt(&decCipher, []*abe.MAABEKey{&decKey})
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	// objects of other types are rejected
+	assert.Error(t, json.Unmarshal(cipherJSON, &decKey))
+}
+
+func TestRevocableFAME_JSON(t *testing.T) {
+	a := abe.NewRevocableFAME(1)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("0 OR 1", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	secKeyJSON, err := json.Marshal(secKey)
+	if err != nil {
+		t.Fatalf("Failed to encode the secret key: %v", err)
+	}
+	var decSecKey abe.RevocableFAMESecKey
+	if err := json.Unmarshal(secKeyJSON, &decSecKey); err != nil {
+		t.Fatalf("Failed to decode the secret key: %v", err)
+	}
+
+	msg := "Attack at dawn!"
+	cipher, err := a.Encrypt(msg, msp, 3, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	keys, err := a.GenerateAttribKeys([]string{"1"}, 0, &decSecKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	update, err := a.UpdateKeys(3, []int{1}, &decSecKey)
+	if err != nil {
+		t.Fatalf("Failed to update keys: %v", err)
+	}
+
+	cipherJSON, err := json.Marshal(cipher)
+	if err != nil {
+		t.Fatalf("Failed to encode the ciphertext: %v", err)
+	}
+	keysJSON, err := json.Marshal(keys)
+	if err != nil {
+		t.Fatalf("Failed to encode the keys: %v", err)
+	}
+	updateJSON, err := json.Marshal(update)
+	if err != nil {
+		t.Fatalf("Failed to encode the update: %v", err)
+	}
+
+	var decCipher abe.RevocableFAMECipher
+	if err := json.Unmarshal(cipherJSON, &decCipher); err != nil {
+		t.Fatalf("Failed to decode the ciphertext: %v", err)
+	}
+	var decKeys abe.RevocableFAMEKey
+	if err := json.Unmarshal(keysJSON, &decKeys); err != nil {
+		t.Fatalf("Failed to decode the keys: %v", err)
+	}
+	var decUpdate abe.RevocableFAMEUpdate
+	if err := json.Unmarshal(updateJSON, &decUpdate); err != nil {
+		t.Fatalf("Failed to decode the update: %v", err)
+	}
+
+	msgCheck, err := a.Decrypt(&decCipher, &decKeys, &decUpdate)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	// objects of other types are rejected
+	assert.Error(t, json.Unmarshal(updateJSON, &decKeys))
+}
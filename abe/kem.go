@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+)
+
+// KEMKeySize is the size in bytes of the keys returned by Encapsulate
+// and Decapsulate of the ABE schemes.
+const KEMKeySize = 32
+
+// kemKey derives a key of KEMKeySize bytes from the element keyGt of
+// GT with symKey, as the keys of the hybrid encryption are derived,
+// where info identifies the type of the ciphertext and header is its
+// binary encoding. The hash of the header is a part of the info of the
+// derivation, thus a modified header yields an unrelated key.
+func kemKey(keyGt *bn256.GT, info string, header []byte) ([]byte, error) {
+	h := sha256.Sum256(header)
+	return symKey(keyGt, "KEM "+info+" "+string(h[:]))
+}
+
+// Encapsulate generates a random key of KEMKeySize bytes that can be
+// used with any symmetric cipher, together with the binary encoding of
+// a ciphertext that protects it according to the MSP structure msp. The
+// key can be recovered from the returned header by Decapsulate with the
+// keys of the attributes satisfying msp.
+func (a *FAME) Encapsulate(msp *MSP, pk *FAMEPubKey) ([]byte, []byte, error) {
+	cipher, keyGt, err := a.encapsulate(msp, pk)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipher.SymVersion = SymVersionKEM
+	header, err := cipher.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := kemKey(keyGt, "abe.FAMECipher", header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, key, nil
+}
+
+// Decapsulate recovers the key encapsulated in header by Encapsulate
+// using the keys of the attributes. An error is returned if the keys
+// are not sufficient. A modified header results in a different key,
+// thus the symmetric cipher used with the key should be authenticated.
+func (a *FAME) Decapsulate(header []byte, key *FAMEAttribKeys) ([]byte, error) {
+	var cipher FAMECipher
+	if err := cipher.UnmarshalBinary(header); err != nil {
+		return nil, err
+	}
+	if cipher.SymVersion != SymVersionKEM {
+		return nil, fmt.Errorf("the provided cipher is faulty")
+	}
+	keyGt, err := a.decapsulate(&cipher, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return kemKey(keyGt, "abe.FAMECipher", header)
+}
+
+// Encapsulate generates a random key of KEMKeySize bytes that can be
+// used with any symmetric cipher, together with the binary encoding of
+// a ciphertext that protects it with attributes gamma, given as in
+// Encrypt. The key can be recovered from the returned header by
+// Decapsulate with a policy key satisfied by gamma.
+func (a *GPSW) Encapsulate(gamma interface{}, pk *GPSWPubKey) ([]byte, []byte, error) {
+	cipher, keyGt, err := a.encapsulate(gamma, pk)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipher.SymVersion = SymVersionKEM
+	header, err := cipher.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := kemKey(keyGt, "abe.GPSWCipher", header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, key, nil
+}
+
+// Decapsulate recovers the key encapsulated in header by Encapsulate
+// using the policy key. An error is returned if the key is not
+// sufficient. A modified header results in a different key, thus the
+// symmetric cipher used with the key should be authenticated.
+func (a *GPSW) Decapsulate(header []byte, key *GPSWKey) ([]byte, error) {
+	var cipher GPSWCipher
+	if err := cipher.UnmarshalBinary(header); err != nil {
+		return nil, err
+	}
+	if cipher.SymVersion != SymVersionKEM {
+		return nil, fmt.Errorf("the provided cipher is faulty")
+	}
+	keyGt, err := a.decapsulate(&cipher, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return kemKey(keyGt, "abe.GPSWCipher", header)
+}
+
+// Encapsulate generates a random key of KEMKeySize bytes that can be
+// used with any symmetric cipher, together with the binary encoding of
+// a ciphertext that protects it with the vector x, given as in Encrypt.
+// The key can be recovered from the returned header by Decapsulate with
+// the keys of a user whose vector is orthogonal to x.
+func (d *DIPPE) Encapsulate(x data.Vector, pubKeys []*DIPPEPubKey) ([]byte, []byte, error) {
+	cipher, keyGt, err := d.encapsulate(x, pubKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipher.SymVersion = SymVersionKEM
+	header, err := cipher.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := kemKey(keyGt, "abe.DIPPECipher", header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, key, nil
+}
+
+// Decapsulate recovers the key encapsulated in header by Encapsulate
+// using the key shares of the user with vector v and global identifier
+// gid. If v is not orthogonal to the vector of the header, the returned
+// key is unrelated to the encapsulated one, thus the symmetric cipher
+// used with the key should be authenticated.
+func (d *DIPPE) Decapsulate(header []byte, keys []data.VectorG2, v data.Vector, gid string) ([]byte, error) {
+	var cipher DIPPECipher
+	if err := cipher.UnmarshalBinary(header); err != nil {
+		return nil, err
+	}
+	if cipher.SymVersion != SymVersionKEM {
+		return nil, fmt.Errorf("the provided cipher is faulty")
+	}
+	keyGt, err := d.decapsulate(&cipher, keys, v, gid)
+	if err != nil {
+		return nil, err
+	}
+
+	return kemKey(keyGt, "abe.DIPPECipher", header)
+}
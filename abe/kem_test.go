@@ -0,0 +1,227 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe_test
+
+import (
+	"encoding"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/abe"
+	"github.com/fentec-project/gofe/data"
+	"github.com/stretchr/testify/assert"
+)
+
+// binaryCipher is a cipher that can be used as a header.
+type binaryCipher interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// tamperHeader decodes header into cipher, modifies it with tamper and
+// returns the encoding of the modified cipher.
+func tamperHeader(t *testing.T, header []byte, cipher binaryCipher, tamper func()) []byte {
+	if err := cipher.UnmarshalBinary(header); err != nil {
+		t.Fatalf("Failed to decode the header: %v", err)
+	}
+	tamper()
+	tampered, err := cipher.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to encode the header: %v", err)
+	}
+
+	return tampered
+}
+
+func TestFAME_KEM(t *testing.T) {
+	a := abe.NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master keys generation: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("a AND (b OR c)", false)
+	if err != nil {
+		t.Fatalf("Error during policy generation: %v", err)
+	}
+	keys, err := a.GenerateAttribKeys([]string{"a", "c"}, secKey)
+	if err != nil {
+		t.Fatalf("Error during keys generation: %v", err)
+	}
+	keysInsuff, err := a.GenerateAttribKeys([]string{"b", "c"}, secKey)
+	if err != nil {
+		t.Fatalf("Error during keys generation: %v", err)
+	}
+
+	header, key, err := a.Encapsulate(msp, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encapsulation: %v", err)
+	}
+	assert.Len(t, key, abe.KEMKeySize)
+	header2, key2, err := a.Encapsulate(msp, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encapsulation: %v", err)
+	}
+	assert.NotEqual(t, header, header2)
+	assert.NotEqual(t, key, key2)
+
+	dec, err := a.Decapsulate(header, keys)
+	if err != nil {
+		t.Fatalf("Error during decapsulation: %v", err)
+	}
+	assert.Equal(t, key, dec)
+
+	_, err = a.Decapsulate(header, keysInsuff)
+	assert.Error(t, err)
+
+	// a modified policy results in a different key
+	var cipher abe.FAMECipher
+	if err := cipher.UnmarshalBinary(header); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	cipher.Msp.RowToAttrib[1] = "d"
+	tampered, err := cipher.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	dec, err = a.Decapsulate(tampered, keys)
+	if err != nil {
+		t.Fatalf("Error during decapsulation: %v", err)
+	}
+	assert.NotEqual(t, key, dec)
+
+	// the header of a ciphertext with a message is not accepted
+	ct, err := a.Encrypt("msg", msp, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	ctBytes, err := ct.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	_, err = a.Decapsulate(ctBytes, keys)
+	assert.Error(t, err)
+
+	// a malformed header is rejected before the decapsulation
+	for _, tamper := range []func(){
+		func() { cipher.Ct0[1] = nil },
+		func() { cipher.Ct = cipher.Ct[:1] },
+		func() { cipher.Msp = nil },
+		func() { cipher.Msp.RowToAttrib = cipher.Msp.RowToAttrib[:1] },
+		func() { cipher.Msp.Mat[1] = cipher.Msp.Mat[1][:1] },
+	} {
+		_, err = a.Decapsulate(tamperHeader(t, header, &cipher, tamper), keys)
+		assert.Error(t, err)
+	}
+}
+
+func TestGPSW_KEM(t *testing.T) {
+	a := abe.NewGPSW(5)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master keys generation: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("0 AND (1 OR 4)", true)
+	if err != nil {
+		t.Fatalf("Error during policy generation: %v", err)
+	}
+	key, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Error during key generation: %v", err)
+	}
+
+	header, symKey, err := a.Encapsulate([]int{0, 1, 3}, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encapsulation: %v", err)
+	}
+	dec, err := a.Decapsulate(header, key)
+	if err != nil {
+		t.Fatalf("Error during decapsulation: %v", err)
+	}
+	assert.Equal(t, symKey, dec)
+
+	// a malformed header is rejected before the decapsulation
+	var cipher abe.GPSWCipher
+	for _, tamper := range []func(){
+		func() { cipher.E0 = nil },
+		func() { cipher.E = cipher.E[:2] },
+		func() { cipher.Gamma = append(cipher.Gamma, 4) },
+		func() { cipher.AttribToI[1] = 5 },
+	} {
+		_, err = a.Decapsulate(tamperHeader(t, header, &cipher, tamper), key)
+		assert.Error(t, err)
+	}
+
+	header, _, err = a.Encapsulate([]int{1, 3, 4}, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encapsulation: %v", err)
+	}
+	_, err = a.Decapsulate(header, key)
+	assert.Error(t, err)
+}
+
+func TestDIPPE_KEM(t *testing.T) {
+	d, err := abe.NewDIPPE(2)
+	if err != nil {
+		t.Fatalf("Error during scheme generation: %v", err)
+	}
+	auth := make([]*abe.DIPPEAuth, 2)
+	pubKeys := make([]*abe.DIPPEPubKey, 2)
+	for i := range auth {
+		auth[i], err = d.NewDIPPEAuth(i)
+		if err != nil {
+			t.Fatalf("Error during authority generation: %v", err)
+		}
+		pubKeys[i] = &auth[i].Pk
+	}
+	policyVec := data.Vector{big.NewInt(1), big.NewInt(-1)}
+	userVec := data.Vector{big.NewInt(1), big.NewInt(1)}
+	keys := make([]data.VectorG2, 2)
+	for i := range auth {
+		keys[i], err = auth[i].DeriveKeyShare(userVec, pubKeys, "gid")
+		if err != nil {
+			t.Fatalf("Error during key generation: %v", err)
+		}
+	}
+
+	header, key, err := d.Encapsulate(policyVec, pubKeys)
+	if err != nil {
+		t.Fatalf("Error during encapsulation: %v", err)
+	}
+	dec, err := d.Decapsulate(header, keys, userVec, "gid")
+	if err != nil {
+		t.Fatalf("Error during decapsulation: %v", err)
+	}
+	assert.Equal(t, key, dec)
+
+	dec, err = d.Decapsulate(header, keys, userVec, "other")
+	if err != nil {
+		t.Fatalf("Error during decapsulation: %v", err)
+	}
+	assert.NotEqual(t, key, dec)
+
+	// a malformed header is rejected before the decapsulation
+	var cipher abe.DIPPECipher
+	for _, tamper := range []func(){
+		func() { cipher.C0 = cipher.C0[:1] },
+		func() { cipher.C[1] = cipher.C[1][:1] },
+		func() { cipher.C = cipher.C[:1] },
+		func() { cipher.CPrime = nil },
+		func() { cipher.X = append(cipher.X, big.NewInt(0)) },
+	} {
+		_, err = d.Decapsulate(tamperHeader(t, header, &cipher, tamper), keys, userVec, "gid")
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,343 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/sample"
+)
+
+// MAABE represents a multi-authority ciphertext policy (CP) attribute
+// based (ABE) scheme introduced by A. Lewko and B. Waters in:
+// "Decentralizing Attribute-Based Encryption"
+// https://eprint.iacr.org/2010/351.pdf
+//
+// There is no central authority. Each authority manages the attributes
+// of its own namespace, i.e. the attributes prefixed by its id and a
+// colon, as in "hospital:doctor", and independently issues keys for
+// them. The keys of a user are bound to a global identifier gid, so
+// that users cannot combine their keys. A message is encrypted under
+// a policy given as a monotone span program (MSP), whose attributes
+// may belong to several authorities. The scheme is instantiated with
+// the random oracle that hashes the global identifiers to G2.
+//
+// Note that the security of the scheme is only proved if the mapping
+// msp.RowToAttrib from the rows of the MSP to attributes is injective.
+type MAABE struct {
+	P *big.Int // order of the elliptic curve
+}
+
+// NewMAABE configures a new instance of the scheme.
+func NewMAABE() *MAABE {
+	return &MAABE{P: bn256.Order}
+}
+
+// MAABEPubKey represents a public key of an authority in MAABE scheme.
+// The i-th elements of EggToAlpha and G1ToY belong to the i-th
+// attribute of Attribs.
+type MAABEPubKey struct {
+	Attribs    []string
+	EggToAlpha data.VectorGT
+	G1ToY      data.VectorG1
+}
+
+// MAABESecKey represents a secret key of an authority in MAABE scheme.
+// The i-th elements of Alpha and Y belong to the i-th attribute of
+// Attribs.
+type MAABESecKey struct {
+	Attribs []string
+	Alpha   data.Vector
+	Y       data.Vector
+}
+
+// MAABEAuth represents an authority in MAABE scheme.
+type MAABEAuth struct {
+	ID string
+	Sk MAABESecKey
+	Pk MAABEPubKey
+}
+
+// MAABEKey represents a key of a user for a single attribute, issued
+// by the authority managing the attribute.
+type MAABEKey struct {
+	Gid    string
+	Attrib string
+	Key    *bn256.G2
+}
+
+// MAABECipher represents a ciphertext in MAABE scheme.
+type MAABECipher struct {
+	Msp        *MSP
+	C0         *bn256.GT
+	C1         data.VectorGT
+	C2         data.VectorG1
+	C3         data.VectorG1
+	SymEnc     []byte // symmetric encryption of the message
+	Iv         []byte // initialization vector or nonce for symmetric encryption
+	SymVersion int    // symmetric encryption used, see SymVersionGCM
+}
+
+// NewMAABEAuth configures a new authority with the given id that will
+// be able to produce decryption keys for the attributes attribs. Each
+// attribute must be prefixed by the id followed by a colon.
+func (a *MAABE) NewMAABEAuth(id string, attribs []string) (*MAABEAuth, error) {
+	if id == "" || strings.Contains(id, ":") {
+		return nil, fmt.Errorf("the id of an authority must be nonempty and without colons")
+	}
+	if len(attribs) == 0 {
+		return nil, fmt.Errorf("an authority needs at least one attribute")
+	}
+	seen := make(map[string]bool)
+	for _, at := range attribs {
+		if !strings.HasPrefix(at, id+":") {
+			return nil, fmt.Errorf("attribute %q is not in the namespace of authority %q", at, id)
+		}
+		if seen[at] {
+			return nil, fmt.Errorf("attribute %q is repeated", at)
+		}
+		seen[at] = true
+	}
+
+	sampler := sample.NewUniform(a.P)
+	alpha, err := data.NewRandomVector(len(attribs), sampler)
+	if err != nil {
+		return nil, err
+	}
+	y, err := data.NewRandomVector(len(attribs), sampler)
+	if err != nil {
+		return nil, err
+	}
+
+	eggToAlpha := make(data.VectorGT, len(attribs))
+	for i := range attribs {
+		eggToAlpha[i] = new(bn256.GT).ScalarBaseMult(alpha[i])
+	}
+	attribsCopy := make([]string, len(attribs))
+	copy(attribsCopy, attribs)
+
+	return &MAABEAuth{
+		ID: id,
+		Sk: MAABESecKey{Attribs: attribsCopy, Alpha: alpha, Y: y},
+		Pk: MAABEPubKey{Attribs: attribsCopy, EggToAlpha: eggToAlpha, G1ToY: y.MulG1()},
+	}, nil
+}
+
+// GenerateAttribKeys generates the keys for the attributes attribs of
+// the authority for the user with global identifier gid.
+func (auth *MAABEAuth) GenerateAttribKeys(gid string, attribs []string) ([]*MAABEKey, error) {
+	attribToI := make(map[string]int)
+	for i, at := range auth.Sk.Attribs {
+		attribToI[at] = i
+	}
+
+	hash, err := bn256.HashG2(gid)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*MAABEKey, len(attribs))
+	for i, at := range attribs {
+		j, ok := attribToI[at]
+		if !ok {
+			return nil, fmt.Errorf("attribute %q is not managed by authority %q", at, auth.ID)
+		}
+		k := new(bn256.G2).ScalarMult(hash, auth.Sk.Y[j])
+		k.Add(k, new(bn256.G2).ScalarBaseMult(auth.Sk.Alpha[j]))
+		keys[i] = &MAABEKey{Gid: gid, Attrib: at, Key: k}
+	}
+
+	return keys, nil
+}
+
+// Encrypt takes as an input a message msg given as a string, a policy
+// given as a MSP struct msp and the public keys of the authorities
+// managing the attributes of the policy. It returns an encryption of
+// msg. In case of a failed procedure an error is returned.
+func (a *MAABE) Encrypt(msg string, msp *MSP, pubKeys []*MAABEPubKey) (*MAABECipher, error) {
+	// msg is encrypted using AES-GCM, with a key derived from a random
+	// element of GT that is encapsulated with MAABE
+	cipher, keyGt, err := a.encapsulate(msp, pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	cipher.Iv, err = newNonce()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymVersion = SymVersionGCM
+	header, err := cipher.header()
+	if err != nil {
+		return nil, err
+	}
+	cipher.SymEnc, err = sealSym(keyGt, "abe.MAABECipher", cipher.Iv, []byte(msg), header)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher, nil
+}
+
+// encapsulate encapsulates a random element of GT with MAABE under the
+// policy msp. It returns a ciphertext without the symmetric encryption
+// of a message and the element of GT, from which the key for the
+// symmetric encryption is derived.
+func (a *MAABE) encapsulate(msp *MSP, pubKeys []*MAABEPubKey) (*MAABECipher, *bn256.GT, error) {
+	if len(msp.Mat) == 0 || len(msp.Mat[0]) == 0 {
+		return nil, nil, fmt.Errorf("empty msp matrix")
+	}
+	if len(msp.RowToAttrib) != len(msp.Mat) {
+		return nil, nil, fmt.Errorf("the msp does not label all the rows")
+	}
+
+	// find the public key of each attribute
+	type attribPubKey struct {
+		pk *MAABEPubKey
+		i  int
+	}
+	attribToPubKey := make(map[string]attribPubKey)
+	for _, pk := range pubKeys {
+		for i, at := range pk.Attribs {
+			if _, ok := attribToPubKey[at]; ok {
+				return nil, nil, fmt.Errorf("attribute %q is in more than one public key", at)
+			}
+			attribToPubKey[at] = attribPubKey{pk: pk, i: i}
+		}
+	}
+
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// share s with v and zero with w
+	sampler := sample.NewUniform(a.P)
+	v, err := data.NewRandomVector(len(msp.Mat[0]), sampler)
+	if err != nil {
+		return nil, nil, err
+	}
+	w, err := data.NewRandomVector(len(msp.Mat[0]), sampler)
+	if err != nil {
+		return nil, nil, err
+	}
+	w[0].SetInt64(0)
+	r, err := data.NewRandomVector(len(msp.Mat), sampler)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c0 := new(bn256.GT).Add(keyGt, new(bn256.GT).ScalarBaseMult(v[0]))
+	c1 := make(data.VectorGT, len(msp.Mat))
+	c2 := make(data.VectorG1, len(msp.Mat))
+	c3 := make(data.VectorG1, len(msp.Mat))
+	for i, row := range msp.Mat {
+		apk, ok := attribToPubKey[msp.RowToAttrib[i]]
+		if !ok {
+			return nil, nil, fmt.Errorf("no public key for attribute %q", msp.RowToAttrib[i])
+		}
+		lambda, err := row.Dot(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		lambda.Mod(lambda, a.P)
+		omega, err := row.Dot(w)
+		if err != nil {
+			return nil, nil, err
+		}
+		omega.Mod(omega, a.P)
+
+		c1[i] = new(bn256.GT).ScalarBaseMult(lambda)
+		c1[i].Add(c1[i], new(bn256.GT).ScalarMult(apk.pk.EggToAlpha[apk.i], r[i]))
+		c2[i] = new(bn256.G1).ScalarBaseMult(r[i])
+		c3[i] = new(bn256.G1).ScalarMult(apk.pk.G1ToY[apk.i], r[i])
+		c3[i].Add(c3[i], new(bn256.G1).ScalarBaseMult(omega))
+	}
+
+	return &MAABECipher{Msp: msp, C0: c0, C1: c1, C2: c2, C3: c3}, keyGt, nil
+}
+
+// Decrypt takes as an input a cipher and the keys of a user, possibly
+// issued by several authorities, and tries to decrypt the cipher. This
+// is possible only if the keys were generated for the same global
+// identifier and their attributes satisfy the policy of the cipher,
+// otherwise an error is returned.
+func (a *MAABE) Decrypt(cipher *MAABECipher, keys []*MAABEKey) (string, error) {
+	keyGt, err := a.decapsulate(cipher, keys)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := openSym(cipher.SymVersion, keyGt, "abe.MAABECipher", cipher.Iv, cipher.SymEnc, cipher.header)
+	if err != nil {
+		return "", err
+	}
+
+	return string(msg), nil
+}
+
+// decapsulate recovers the element of GT encapsulated in the cipher,
+// from which the key for the symmetric encryption is derived.
+func (a *MAABE) decapsulate(cipher *MAABECipher, keys []*MAABEKey) (*bn256.GT, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys provided")
+	}
+	if cipher.Msp == nil || len(cipher.Msp.Mat) != len(cipher.C1) ||
+		len(cipher.C1) != len(cipher.C2) || len(cipher.C2) != len(cipher.C3) ||
+		len(cipher.Msp.RowToAttrib) != len(cipher.Msp.Mat) {
+		return nil, fmt.Errorf("the provided cipher is faulty")
+	}
+	gid := keys[0].Gid
+	attribToKey := make(map[string]*bn256.G2)
+	for _, k := range keys {
+		if k.Gid != gid {
+			return nil, fmt.Errorf("the keys were generated for different global identifiers")
+		}
+		attribToKey[k.Attrib] = k.Key
+	}
+
+	// find a minimal set of rows of the owned attributes and
+	// a combination alpha of them needed to decrypt
+	attribs := make([]string, 0, len(attribToKey))
+	for at := range attribToKey {
+		attribs = append(attribs, at)
+	}
+	rows, alpha, err := cipher.Msp.Reconstruct(attribs, false)
+	if err != nil {
+		return nil, fmt.Errorf("provided key is not sufficient for decryption")
+	}
+
+	hash, err := bn256.HashG2(gid)
+	if err != nil {
+		return nil, err
+	}
+
+	// combine the shares of the rows into the element of GT
+	// from which the key for the decryption of msg is derived
+	eggToS := new(bn256.GT).ScalarBaseMult(big.NewInt(0))
+	for k, i := range rows {
+		share := new(bn256.GT).Add(cipher.C1[i], bn256.Pair(cipher.C3[i], hash))
+		share.Add(share, new(bn256.GT).Neg(bn256.Pair(cipher.C2[i], attribToKey[cipher.Msp.RowToAttrib[i]])))
+		eggToS.Add(eggToS, share.ScalarMult(share, alpha[k]))
+	}
+	keyGt := new(bn256.GT).Add(cipher.C0, eggToS.Neg(eggToS))
+
+	return keyGt, nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abe_test
+
+import (
+	"testing"
+
+	"github.com/fentec-project/gofe/abe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMAABE(t *testing.T) {
+	// create a new MAABE struct
+	a := abe.NewMAABE()
+
+	// create three authorities, each managing the attributes
+	// of its own namespace
+	auth1, err := a.NewMAABEAuth("auth1", []string{"auth1:at1", "auth1:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate a new authority: %v", err)
+	}
+	auth2, err := a.NewMAABEAuth("auth2", []string{"auth2:at1", "auth2:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate a new authority: %v", err)
+	}
+	auth3, err := a.NewMAABEAuth("auth3", []string{"auth3:at1", "auth3:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate a new authority: %v", err)
+	}
+	pubKeys := []*abe.MAABEPubKey{&auth1.Pk, &auth2.Pk, &auth3.Pk}
+
+	// attributes outside of the namespace of an authority are rejected
+	_, err = a.NewMAABEAuth("auth4", []string{"auth1:at3"})
+	assert.Error(t, err)
+	_, err = a.NewMAABEAuth("auth:4", []string{"auth:4:at1"})
+	assert.Error(t, err)
+
+	// encrypt a message under a policy mixing the attributes
+	// of all the authorities
+	msg := "Attack at dawn!"
+	msp, err := abe.BooleanToMSP("((auth1:at1 AND auth2:at1) OR (auth1:at2 AND auth2:at2)) OR (auth3:at1 AND auth3:at2)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+	cipher, err := a.Encrypt(msg, msp, pubKeys)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	// the public keys of all the authorities of the policy are needed
+	_, err = a.Encrypt(msg, msp, pubKeys[:2])
+	assert.Error(t, err)
+
+	// keys are generated by the authorities for a user with a
+	// global identifier
+	gid1 := "gid1"
+	keys1, err := auth1.GenerateAttribKeys(gid1, []string{"auth1:at1", "auth1:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate attribute keys: %v", err)
+	}
+	keys2, err := auth2.GenerateAttribKeys(gid1, []string{"auth2:at1"})
+	if err != nil {
+		t.Fatalf("Failed to generate attribute keys: %v", err)
+	}
+	_, err = auth2.GenerateAttribKeys(gid1, []string{"auth1:at1"})
+	assert.Error(t, err)
+
+	msgCheck, err := a.Decrypt(cipher, append(keys1, keys2...))
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	// the keys of a single authority are not sufficient
+	_, err = a.Decrypt(cipher, keys1)
+	assert.Error(t, err)
+
+	// users cannot combine their keys
+	gid2 := "gid2"
+	keys3, err := auth3.GenerateAttribKeys(gid1, []string{"auth3:at1"})
+	if err != nil {
+		t.Fatalf("Failed to generate attribute keys: %v", err)
+	}
+	keys4, err := auth3.GenerateAttribKeys(gid2, []string{"auth3:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate attribute keys: %v", err)
+	}
+	_, err = a.Decrypt(cipher, append(keys3, keys4...))
+	assert.Error(t, err)
+
+	// even when they claim the same global identifier
+	keys4[0].Gid = gid1
+	_, err = a.Decrypt(cipher, append(keys3, keys4...))
+	assert.Error(t, err)
+}
@@ -0,0 +1,966 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package abe
+
+import (
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+// MarshalBinary encodes the monotone span program into a canonical
+// binary form.
+func (m *MSP) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.MSP")
+	e.BigInt(m.P)
+	e.Value(m.Mat, m.Mat != nil)
+	e.Strings(m.RowToAttrib)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the monotone span program encoded with
+// MarshalBinary into m.
+func (m *MSP) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.MSP")
+	var msp MSP
+	msp.P = d.BigInt()
+	d.Value(&msp.Mat)
+	msp.RowToAttrib = d.Strings()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*m = msp
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters of the scheme into a canonical
+// binary form.
+func (a *FAME) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.FAME")
+	e.BigInt(a.P)
+	e.OptionalInt(a.Copies)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters of the scheme encoded with
+// MarshalBinary into a.
+func (a *FAME) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.FAME")
+	p := d.BigInt()
+	copies := d.OptionalInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	a.P = p
+	a.Copies = copies
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *FAMESecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.FAMESecKey")
+	for _, x := range k.PartInt {
+		e.BigInt(x)
+	}
+	for _, p := range k.PartG1 {
+		e.G1(p)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *FAMESecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.FAMESecKey")
+	var key FAMESecKey
+	for i := range key.PartInt {
+		key.PartInt[i] = d.BigInt()
+	}
+	for i := range key.PartG1 {
+		key.PartG1[i] = d.G1()
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the public key into a canonical binary form.
+func (k *FAMEPubKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.FAMEPubKey")
+	for _, p := range k.PartG2 {
+		e.G2(p)
+	}
+	for _, p := range k.PartGT {
+		e.GT(p)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the public key encoded with MarshalBinary into k.
+func (k *FAMEPubKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.FAMEPubKey")
+	var key FAMEPubKey
+	for i := range key.PartG2 {
+		key.PartG2[i] = d.G2()
+	}
+	for i := range key.PartGT {
+		key.PartGT[i] = d.GT()
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *FAMECipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.FAMECipher")
+	for _, p := range c.Ct0 {
+		e.G2(p)
+	}
+	e.Len(len(c.Ct))
+	for _, row := range c.Ct {
+		for _, p := range row {
+			e.G1(p)
+		}
+	}
+	e.GT(c.CtPrime)
+	e.Value(c.Msp, c.Msp != nil)
+	e.Bytes(c.SymEnc)
+	e.Bytes(c.Iv)
+	e.OptionalInt(c.SymVersion)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *FAMECipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.FAMECipher")
+	var cipher FAMECipher
+	for i := range cipher.Ct0 {
+		cipher.Ct0[i] = d.G2()
+	}
+	n := d.Len()
+	cipher.Ct = make([][3]*bn256.G1, 0)
+	for i := 0; i < n && !d.Failed(); i++ {
+		var row [3]*bn256.G1
+		for j := range row {
+			row[j] = d.G1()
+		}
+		cipher.Ct = append(cipher.Ct, row)
+	}
+	cipher.CtPrime = d.GT()
+	cipher.Msp = new(MSP)
+	if !d.Value(cipher.Msp) {
+		cipher.Msp = nil
+	}
+	cipher.SymEnc = d.Bytes()
+	cipher.Iv = d.Bytes()
+	cipher.SymVersion = d.OptionalInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
+
+// MarshalBinary encodes the attribute keys into a canonical binary form.
+func (k *FAMEAttribKeys) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.FAMEAttribKeys")
+	k.encode(e)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the attribute keys encoded with MarshalBinary
+// into k.
+func (k *FAMEAttribKeys) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.FAMEAttribKeys")
+	var key FAMEAttribKeys
+	key.decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// encode writes the attribute keys to e.
+func (k *FAMEAttribKeys) encode(e *serial.Encoder) {
+	for _, p := range k.K0 {
+		e.G2(p)
+	}
+	e.Len(len(k.K))
+	for _, row := range k.K {
+		for _, p := range row {
+			e.G1(p)
+		}
+	}
+	for _, p := range k.KPrime {
+		e.G1(p)
+	}
+	e.StringIntMap(k.AttribToI)
+}
+
+// decode reads the attribute keys written by encode from d into k.
+func (k *FAMEAttribKeys) decode(d *serial.Decoder) {
+	for i := range k.K0 {
+		k.K0[i] = d.G2()
+	}
+	n := d.Len()
+	k.K = make([][3]*bn256.G1, 0)
+	for i := 0; i < n && !d.Failed(); i++ {
+		var row [3]*bn256.G1
+		for j := range row {
+			row[j] = d.G1()
+		}
+		k.K = append(k.K, row)
+	}
+	for i := range k.KPrime {
+		k.KPrime[i] = d.G1()
+	}
+	k.AttribToI = d.StringIntMap()
+}
+
+// MarshalBinary encodes the transformation key into a canonical binary
+// form.
+func (k *FAMETransformKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.FAMETransformKey")
+	(*FAMEAttribKeys)(k).encode(e)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the transformation key encoded with
+// MarshalBinary into k.
+func (k *FAMETransformKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.FAMETransformKey")
+	var key FAMEAttribKeys
+	key.decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = FAMETransformKey(key)
+
+	return nil
+}
+
+// MarshalBinary encodes the transformed ciphertext into a canonical
+// binary form.
+func (c *FAMETransformedCipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.FAMETransformedCipher")
+	e.GT(c.CtPrime)
+	e.GT(c.CtBlind)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the transformed ciphertext encoded with
+// MarshalBinary into c.
+func (c *FAMETransformedCipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.FAMETransformedCipher")
+	var cipher FAMETransformedCipher
+	cipher.CtPrime = d.GT()
+	cipher.CtBlind = d.GT()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *GPSWParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.GPSWParams")
+	e.Int(p.L)
+	e.BigInt(p.P)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters encoded with MarshalBinary into p.
+func (p *GPSWParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.GPSWParams")
+	var params GPSWParams
+	params.L = d.Int()
+	params.P = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the public key into a canonical binary form.
+func (k *GPSWPubKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.GPSWPubKey")
+	e.Value(k.T, k.T != nil)
+	e.GT(k.Y)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the public key encoded with MarshalBinary into k.
+func (k *GPSWPubKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.GPSWPubKey")
+	var key GPSWPubKey
+	d.Value(&key.T)
+	key.Y = d.GT()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *GPSWCipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.GPSWCipher")
+	e.Ints(c.Gamma)
+	e.IntIntMap(c.AttribToI)
+	e.GT(c.E0)
+	e.Value(c.E, c.E != nil)
+	e.Bytes(c.SymEnc)
+	e.Bytes(c.Iv)
+	e.OptionalInt(c.SymVersion)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *GPSWCipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.GPSWCipher")
+	var cipher GPSWCipher
+	cipher.Gamma = d.Ints()
+	cipher.AttribToI = d.IntIntMap()
+	cipher.E0 = d.GT()
+	d.Value(&cipher.E)
+	cipher.SymEnc = d.Bytes()
+	cipher.Iv = d.Bytes()
+	cipher.SymVersion = d.OptionalInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
+
+// MarshalBinary encodes the policy key into a canonical binary form.
+func (k *GPSWKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.GPSWKey")
+	e.Value(k.Msp, k.Msp != nil)
+	e.Value(k.D, k.D != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the policy key encoded with MarshalBinary into k.
+func (k *GPSWKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.GPSWKey")
+	var key GPSWKey
+	key.Msp = new(MSP)
+	if !d.Value(key.Msp) {
+		key.Msp = nil
+	}
+	d.Value(&key.D)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters of the scheme into a canonical
+// binary form.
+func (a *GPSWLU) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.GPSWLU")
+	e.BigInt(a.P)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters of the scheme encoded with
+// MarshalBinary into a.
+func (a *GPSWLU) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.GPSWLU")
+	p := d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	a.P = p
+
+	return nil
+}
+
+// MarshalBinary encodes the public key into a canonical binary form.
+func (k *GPSWLUPubKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.GPSWLUPubKey")
+	e.GT(k.Y)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the public key encoded with MarshalBinary into k.
+func (k *GPSWLUPubKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.GPSWLUPubKey")
+	var key GPSWLUPubKey
+	key.Y = d.GT()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *GPSWLUCipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.GPSWLUCipher")
+	e.Strings(c.Gamma)
+	e.StringIntMap(c.AttribToI)
+	e.GT(c.E0)
+	e.G1(c.EPrime)
+	e.Value(c.E, c.E != nil)
+	e.Bytes(c.SymEnc)
+	e.Bytes(c.Iv)
+	e.OptionalInt(c.SymVersion)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *GPSWLUCipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.GPSWLUCipher")
+	var cipher GPSWLUCipher
+	cipher.Gamma = d.Strings()
+	cipher.AttribToI = d.StringIntMap()
+	cipher.E0 = d.GT()
+	cipher.EPrime = d.G1()
+	d.Value(&cipher.E)
+	cipher.SymEnc = d.Bytes()
+	cipher.Iv = d.Bytes()
+	cipher.SymVersion = d.OptionalInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
+
+// MarshalBinary encodes the policy key into a canonical binary form.
+func (k *GPSWLUKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.GPSWLUKey")
+	e.Value(k.Msp, k.Msp != nil)
+	e.Value(k.D, k.D != nil)
+	e.Value(k.R, k.R != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the policy key encoded with MarshalBinary into k.
+func (k *GPSWLUKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.GPSWLUKey")
+	var key GPSWLUKey
+	key.Msp = new(MSP)
+	if !d.Value(key.Msp) {
+		key.Msp = nil
+	}
+	d.Value(&key.D)
+	d.Value(&key.R)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the public parameters of the scheme into
+// a canonical binary form.
+func (d *DIPPE) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.DIPPE")
+	e.Int(d.secLevel)
+	e.Value(d.G1ToA, d.G1ToA != nil)
+	e.Value(d.G1ToUA, d.G1ToUA != nil)
+	e.BigInt(d.P)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the public parameters of the scheme encoded
+// with MarshalBinary into d.
+func (d *DIPPE) UnmarshalBinary(b []byte) error {
+	dec := serial.NewDecoder(b, "abe.DIPPE")
+	var params DIPPE
+	params.secLevel = dec.Int()
+	dec.Value(&params.G1ToA)
+	dec.Value(&params.G1ToUA)
+	params.P = dec.BigInt()
+	if err := dec.Finish(); err != nil {
+		return err
+	}
+	*d = params
+
+	return nil
+}
+
+// MarshalBinary encodes the public key into a canonical binary form.
+func (k *DIPPEPubKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.DIPPEPubKey")
+	e.Value(k.G1ToWtA, k.G1ToWtA != nil)
+	e.Value(k.GToAlphaA, k.GToAlphaA != nil)
+	e.G2(k.G2ToSigma)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the public key encoded with MarshalBinary into k.
+func (k *DIPPEPubKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.DIPPEPubKey")
+	var key DIPPEPubKey
+	d.Value(&key.G1ToWtA)
+	d.Value(&key.GToAlphaA)
+	key.G2ToSigma = d.G2()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *DIPPESecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.DIPPESecKey")
+	e.BigInt(k.Sigma)
+	e.Value(k.W, k.W != nil)
+	e.Value(k.Alpha, k.Alpha != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *DIPPESecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.DIPPESecKey")
+	var key DIPPESecKey
+	key.Sigma = d.BigInt()
+	d.Value(&key.W)
+	d.Value(&key.Alpha)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the authority into a canonical binary form.
+func (a *DIPPEAuth) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.DIPPEAuth")
+	e.Int(a.ID)
+	e.Value(&a.Sk, true)
+	e.Value(&a.Pk, true)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the authority encoded with MarshalBinary into a.
+func (a *DIPPEAuth) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.DIPPEAuth")
+	var auth DIPPEAuth
+	auth.ID = d.Int()
+	d.Value(&auth.Sk)
+	d.Value(&auth.Pk)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*a = auth
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *DIPPECipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.DIPPECipher")
+	e.Value(c.C0, c.C0 != nil)
+	e.Value(c.C, c.C != nil)
+	e.GT(c.CPrime)
+	e.Value(c.X, c.X != nil)
+	e.Bytes(c.SymEnc)
+	e.Bytes(c.Iv)
+	e.OptionalInt(c.SymVersion)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *DIPPECipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.DIPPECipher")
+	var cipher DIPPECipher
+	d.Value(&cipher.C0)
+	d.Value(&cipher.C)
+	cipher.CPrime = d.GT()
+	d.Value(&cipher.X)
+	cipher.SymEnc = d.Bytes()
+	cipher.Iv = d.Bytes()
+	cipher.SymVersion = d.OptionalInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters of the scheme into a canonical
+// binary form.
+func (a *MAABE) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.MAABE")
+	e.BigInt(a.P)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters of the scheme encoded with
+// MarshalBinary into a.
+func (a *MAABE) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.MAABE")
+	p := d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	a.P = p
+
+	return nil
+}
+
+// MarshalBinary encodes the public key into a canonical binary form.
+func (k *MAABEPubKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.MAABEPubKey")
+	e.Strings(k.Attribs)
+	e.Value(k.EggToAlpha, k.EggToAlpha != nil)
+	e.Value(k.G1ToY, k.G1ToY != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the public key encoded with MarshalBinary into k.
+func (k *MAABEPubKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.MAABEPubKey")
+	var key MAABEPubKey
+	key.Attribs = d.Strings()
+	d.Value(&key.EggToAlpha)
+	d.Value(&key.G1ToY)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *MAABESecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.MAABESecKey")
+	e.Strings(k.Attribs)
+	e.Value(k.Alpha, k.Alpha != nil)
+	e.Value(k.Y, k.Y != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *MAABESecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.MAABESecKey")
+	var key MAABESecKey
+	key.Attribs = d.Strings()
+	d.Value(&key.Alpha)
+	d.Value(&key.Y)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the authority into a canonical binary form.
+func (a *MAABEAuth) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.MAABEAuth")
+	e.String(a.ID)
+	e.Value(&a.Sk, true)
+	e.Value(&a.Pk, true)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the authority encoded with MarshalBinary into a.
+func (a *MAABEAuth) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.MAABEAuth")
+	var auth MAABEAuth
+	auth.ID = d.String()
+	d.Value(&auth.Sk)
+	d.Value(&auth.Pk)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*a = auth
+
+	return nil
+}
+
+// MarshalBinary encodes the attribute key into a canonical binary form.
+func (k *MAABEKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.MAABEKey")
+	e.String(k.Gid)
+	e.String(k.Attrib)
+	e.G2(k.Key)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the attribute key encoded with MarshalBinary
+// into k.
+func (k *MAABEKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.MAABEKey")
+	var key MAABEKey
+	key.Gid = d.String()
+	key.Attrib = d.String()
+	key.Key = d.G2()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *MAABECipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.MAABECipher")
+	e.Value(c.Msp, c.Msp != nil)
+	e.GT(c.C0)
+	e.Value(c.C1, c.C1 != nil)
+	e.Value(c.C2, c.C2 != nil)
+	e.Value(c.C3, c.C3 != nil)
+	e.Bytes(c.SymEnc)
+	e.Bytes(c.Iv)
+	e.OptionalInt(c.SymVersion)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *MAABECipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.MAABECipher")
+	var cipher MAABECipher
+	cipher.Msp = new(MSP)
+	if !d.Value(cipher.Msp) {
+		cipher.Msp = nil
+	}
+	cipher.C0 = d.GT()
+	d.Value(&cipher.C1)
+	d.Value(&cipher.C2)
+	d.Value(&cipher.C3)
+	cipher.SymEnc = d.Bytes()
+	cipher.Iv = d.Bytes()
+	cipher.SymVersion = d.OptionalInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters of the scheme into a canonical
+// binary form.
+func (r *RevocableFAME) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.RevocableFAME")
+	e.Value(r.FAME, r.FAME != nil)
+	e.Int(r.Depth)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters of the scheme encoded with
+// MarshalBinary into r.
+func (r *RevocableFAME) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.RevocableFAME")
+	var scheme RevocableFAME
+	scheme.FAME = new(FAME)
+	if !d.Value(scheme.FAME) {
+		scheme.FAME = nil
+	}
+	scheme.Depth = d.Int()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*r = scheme
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *RevocableFAMESecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.RevocableFAMESecKey")
+	e.Value(k.Sk, k.Sk != nil)
+	e.Bytes(k.Seed)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *RevocableFAMESecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.RevocableFAMESecKey")
+	var key RevocableFAMESecKey
+	key.Sk = new(FAMESecKey)
+	if !d.Value(key.Sk) {
+		key.Sk = nil
+	}
+	key.Seed = d.Bytes()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// encodeFAMEKeys writes a list of attribute keys to e.
+func encodeFAMEKeys(e *serial.Encoder, keys []*FAMEAttribKeys) {
+	e.Len(len(keys))
+	for _, k := range keys {
+		k.encode(e)
+	}
+}
+
+// decodeFAMEKeys reads a list of attribute keys written by
+// encodeFAMEKeys from d.
+func decodeFAMEKeys(d *serial.Decoder) []*FAMEAttribKeys {
+	n := d.Len()
+	keys := make([]*FAMEAttribKeys, 0)
+	for i := 0; i < n && !d.Failed(); i++ {
+		k := new(FAMEAttribKeys)
+		k.decode(d)
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// MarshalBinary encodes the keys of a user into a canonical binary form.
+func (k *RevocableFAMEKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.RevocableFAMEKey")
+	e.Int(k.Leaf)
+	e.Ints(k.Nodes)
+	encodeFAMEKeys(e, k.Keys)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the keys of a user encoded with MarshalBinary
+// into k.
+func (k *RevocableFAMEKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.RevocableFAMEKey")
+	var key RevocableFAMEKey
+	key.Leaf = d.Int()
+	key.Nodes = d.Ints()
+	key.Keys = decodeFAMEKeys(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the update of the keys into a canonical binary
+// form.
+func (u *RevocableFAMEUpdate) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.RevocableFAMEUpdate")
+	e.Int(u.Epoch)
+	e.Ints(u.Nodes)
+	encodeFAMEKeys(e, u.Keys)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the update of the keys encoded with
+// MarshalBinary into u.
+func (u *RevocableFAMEUpdate) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.RevocableFAMEUpdate")
+	var update RevocableFAMEUpdate
+	update.Epoch = d.Int()
+	update.Nodes = d.Ints()
+	update.Keys = decodeFAMEKeys(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*u = update
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *RevocableFAMECipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("abe.RevocableFAMECipher")
+	e.Value(c.Cipher, c.Cipher != nil)
+	e.Int(c.Epoch)
+	for _, p := range c.CtEpoch {
+		e.G1(p)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *RevocableFAMECipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "abe.RevocableFAMECipher")
+	var cipher RevocableFAMECipher
+	cipher.Cipher = new(FAMECipher)
+	if !d.Value(cipher.Cipher) {
+		cipher.Cipher = nil
+	}
+	cipher.Epoch = d.Int()
+	for i := range cipher.CtEpoch {
+		cipher.CtEpoch[i] = d.G1()
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
@@ -0,0 +1,340 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe_test
+
+import (
+	"encoding"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/abe"
+	"github.com/fentec-project/gofe/data"
+	"github.com/stretchr/testify/assert"
+)
+
+// roundTrip encodes in, decodes the result into out and checks
+// that encoding out gives the same bytes.
+func roundTrip(t *testing.T, in encoding.BinaryMarshaler, out interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}) {
+	b, err := in.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if err := out.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	check, err := out.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	assert.Equal(t, b, check)
+}
+
+func TestFAME_MarshalBinary(t *testing.T) {
+	a := abe.NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("(0 AND 1) OR (2 AND 3)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	decodedScheme := new(abe.FAME)
+	roundTrip(t, a, decodedScheme)
+	roundTrip(t, abe.NewFAMEWithCopies(3), new(abe.FAME))
+	decodedPubKey := new(abe.FAMEPubKey)
+	roundTrip(t, pubKey, decodedPubKey)
+	decodedSecKey := new(abe.FAMESecKey)
+	roundTrip(t, secKey, decodedSecKey)
+	decodedMsp := new(abe.MSP)
+	roundTrip(t, msp, decodedMsp)
+	assert.Equal(t, msp, decodedMsp)
+
+	msg := "Attack at dawn!"
+	cipher, err := decodedScheme.Encrypt(msg, decodedMsp, decodedPubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	keys, err := decodedScheme.GenerateAttribKeys([]string{"2", "3"}, decodedSecKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+
+	decodedCipher := new(abe.FAMECipher)
+	roundTrip(t, cipher, decodedCipher)
+	decodedKeys := new(abe.FAMEAttribKeys)
+	roundTrip(t, keys, decodedKeys)
+	assert.Equal(t, keys.AttribToI, decodedKeys.AttribToI)
+
+	msgCheck, err := a.Decrypt(decodedCipher, decodedKeys, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+
+	// a ciphertext cannot be decoded as a key
+	b, err := cipher.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	assert.Error(t, new(abe.FAMEAttribKeys).UnmarshalBinary(b))
+
+	// required group elements cannot be absent
+	for _, tamper := range []func(c *abe.FAMECipher){
+		func(c *abe.FAMECipher) { c.Ct0[1] = nil },
+		func(c *abe.FAMECipher) { c.Ct[0][2] = nil },
+		func(c *abe.FAMECipher) { c.CtPrime = nil },
+	} {
+		header := *cipher
+		header.SymEnc = nil
+		header.Ct = append([][3]*bn256.G1{}, cipher.Ct...)
+		tamper(&header)
+		b, err := header.MarshalBinary()
+		if err != nil {
+			t.Fatalf("Failed to marshal: %v", err)
+		}
+		assert.Error(t, new(abe.FAMECipher).UnmarshalBinary(b))
+	}
+}
+
+func TestGPSW_MarshalBinary(t *testing.T) {
+	a := abe.NewGPSW(5)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("1 AND (2 OR 3)", true)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	params := new(abe.GPSWParams)
+	roundTrip(t, a.Params, params)
+	assert.Equal(t, a.Params, params)
+	decoded := &abe.GPSW{Params: params}
+	decodedPubKey := new(abe.GPSWPubKey)
+	roundTrip(t, pubKey, decodedPubKey)
+	decodedSecKey := new(data.Vector)
+	roundTrip(t, secKey, decodedSecKey)
+
+	msg := "Attack at dawn!"
+	cipher, err := decoded.Encrypt(msg, []int{0, 1, 3}, decodedPubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	key, err := decoded.GeneratePolicyKey(msp, *decodedSecKey)
+	if err != nil {
+		t.Fatalf("Failed to generate policy key: %v", err)
+	}
+
+	decodedCipher := new(abe.GPSWCipher)
+	roundTrip(t, cipher, decodedCipher)
+	assert.Equal(t, cipher.AttribToI, decodedCipher.AttribToI)
+	decodedKey := new(abe.GPSWKey)
+	roundTrip(t, key, decodedKey)
+
+	msgCheck, err := a.Decrypt(decodedCipher, decodedKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+}
+
+func TestGPSWLU_MarshalBinary(t *testing.T) {
+	a := abe.NewGPSWLU()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("a AND (b OR c)", true)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	decoded := new(abe.GPSWLU)
+	roundTrip(t, a, decoded)
+	assert.Equal(t, a, decoded)
+	decodedPubKey := new(abe.GPSWLUPubKey)
+	roundTrip(t, pubKey, decodedPubKey)
+
+	msg := "Attack at dawn!"
+	cipher, err := decoded.Encrypt(msg, []string{"a", "c", "d"}, decodedPubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	key, err := decoded.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Failed to generate policy key: %v", err)
+	}
+
+	decodedCipher := new(abe.GPSWLUCipher)
+	roundTrip(t, cipher, decodedCipher)
+	assert.Equal(t, cipher.AttribToI, decodedCipher.AttribToI)
+	decodedKey := new(abe.GPSWLUKey)
+	roundTrip(t, key, decodedKey)
+
+	msgCheck, err := a.Decrypt(decodedCipher, decodedKey)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+}
+
+func TestDIPPE_MarshalBinary(t *testing.T) {
+	d, err := abe.NewDIPPE(2)
+	if err != nil {
+		t.Fatalf("Failed to generate a new scheme: %v", err)
+	}
+	decoded := new(abe.DIPPE)
+	roundTrip(t, d, decoded)
+
+	vecLen := 3
+	auth := make([]*abe.DIPPEAuth, vecLen)
+	pubKeys := make([]*abe.DIPPEPubKey, vecLen)
+	for i := range auth {
+		a, err := decoded.NewDIPPEAuth(i)
+		if err != nil {
+			t.Fatalf("Failed to generate a new authority: %v", err)
+		}
+		auth[i] = new(abe.DIPPEAuth)
+		roundTrip(t, a, auth[i])
+		pubKeys[i] = new(abe.DIPPEPubKey)
+		roundTrip(t, &a.Pk, pubKeys[i])
+	}
+
+	msg := "some message"
+	policyVec := data.Vector([]*big.Int{big.NewInt(1), big.NewInt(-1), big.NewInt(0)})
+	cipher, err := decoded.Encrypt(msg, policyVec, pubKeys)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	decodedCipher := new(abe.DIPPECipher)
+	roundTrip(t, cipher, decodedCipher)
+
+	userGID := "someGID"
+	userVec := data.Vector([]*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(5)})
+	userKeys := make([]data.VectorG2, vecLen)
+	for i := range auth {
+		userKeys[i], err = auth[i].DeriveKeyShare(userVec, pubKeys, userGID)
+		if err != nil {
+			t.Fatalf("Failed to generate a user key: %v", err)
+		}
+	}
+
+	dec, err := d.Decrypt(decodedCipher, userKeys, userVec, userGID)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, dec)
+}
+
+func TestMAABE_MarshalBinary(t *testing.T) {
+	a := abe.NewMAABE()
+	auth1, err := a.NewMAABEAuth("auth1", []string{"auth1:at1", "auth1:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate a new authority: %v", err)
+	}
+	auth2, err := a.NewMAABEAuth("auth2", []string{"auth2:at1"})
+	if err != nil {
+		t.Fatalf("Failed to generate a new authority: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("auth1:at1 AND (auth1:at2 OR auth2:at1)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	decoded := new(abe.MAABE)
+	roundTrip(t, a, decoded)
+	assert.Equal(t, a, decoded)
+	decodedAuth := new(abe.MAABEAuth)
+	roundTrip(t, auth1, decodedAuth)
+	decodedPubKey := new(abe.MAABEPubKey)
+	roundTrip(t, &auth2.Pk, decodedPubKey)
+
+	msg := "Attack at dawn!"
+	cipher, err := decoded.Encrypt(msg, msp, []*abe.MAABEPubKey{&decodedAuth.Pk, decodedPubKey})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	keys, err := decodedAuth.GenerateAttribKeys("gid", []string{"auth1:at1", "auth1:at2"})
+	if err != nil {
+		t.Fatalf("Failed to generate attribute keys: %v", err)
+	}
+
+	decodedCipher := new(abe.MAABECipher)
+	roundTrip(t, cipher, decodedCipher)
+	decodedKeys := make([]*abe.MAABEKey, len(keys))
+	for i := range keys {
+		decodedKeys[i] = new(abe.MAABEKey)
+		roundTrip(t, keys[i], decodedKeys[i])
+	}
+
+	msgCheck, err := a.Decrypt(decodedCipher, decodedKeys)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+}
+
+func TestRevocableFAME_MarshalBinary(t *testing.T) {
+	a := abe.NewRevocableFAME(3)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate master keys: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("0 AND (1 OR 2)", false)
+	if err != nil {
+		t.Fatalf("Failed to generate the policy: %v", err)
+	}
+
+	decoded := new(abe.RevocableFAME)
+	roundTrip(t, a, decoded)
+	assert.Equal(t, a, decoded)
+	decodedSecKey := new(abe.RevocableFAMESecKey)
+	roundTrip(t, secKey, decodedSecKey)
+
+	msg := "Attack at dawn!"
+	cipher, err := decoded.Encrypt(msg, msp, 7, pubKey)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	keys, err := decoded.GenerateAttribKeys([]string{"0", "2"}, 5, decodedSecKey)
+	if err != nil {
+		t.Fatalf("Failed to generate keys: %v", err)
+	}
+	update, err := decoded.UpdateKeys(7, []int{1, 6}, decodedSecKey)
+	if err != nil {
+		t.Fatalf("Failed to update keys: %v", err)
+	}
+
+	decodedCipher := new(abe.RevocableFAMECipher)
+	roundTrip(t, cipher, decodedCipher)
+	decodedKeys := new(abe.RevocableFAMEKey)
+	roundTrip(t, keys, decodedKeys)
+	decodedUpdate := new(abe.RevocableFAMEUpdate)
+	roundTrip(t, update, decodedUpdate)
+
+	msgCheck, err := a.Decrypt(decodedCipher, decodedKeys, decodedUpdate)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	assert.Equal(t, msg, msgCheck)
+}
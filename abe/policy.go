@@ -13,15 +13,14 @@
  * See the License for the specific language governing permissions and
  * limitations under the License.
  */
-
 package abe
 
 import (
+	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 
-	"fmt"
-
 	"github.com/fentec-project/gofe/data"
 )
 
@@ -37,8 +36,9 @@ type MSP struct {
 	RowToAttrib []string
 }
 
-// BooleanToMSP takes as an input a boolean expression (without a NOT gate) as
-// a string where attributes are joined by AND and OR gates. It outputs a
+// BooleanToMSP takes as an input a boolean expression as a string where
+// attributes are joined by AND and OR gates and threshold gates kOF(...),
+// and possibly negated by NOT gates. It outputs a
 // msp structure representing the expression, i.e. a matrix whose rows
 // correspond to attributes used in the expression and with the property that a
 // boolean expression assigning 1 to some attributes is satisfied iff the
@@ -47,20 +47,45 @@ type MSP struct {
 // vector is produced whose i-th entry indicates to which attribute the i-th row
 // corresponds.
 // Example: BooleanToMSP("attrib1 AND (attrib2 OR attrib3)", true)
-// The names of the attributes should not include "AND" or "OR" as
-// a substring and '(' or ')' as a character, otherwise the function
-// will not work properly.
+// A threshold gate kOF(exp1, ..., expn) is satisfied iff at least k of
+// the comma separated sub-expressions are satisfied, for example
+// BooleanToMSP("attrib1 AND 2OF(attrib2, attrib3, attrib4)", true).
+// A numeric attribute can be compared to a number between 0 and
+// 2^NumericBits - 1 with one of the operators <, <=, >, >= and =, as
+// in BooleanToMSP("clearance >= 3 OR admin", true), which is satisfied
+// by the attributes given by NumericAttribs for a matching value.
+// NOT gates are pushed to the attributes by De Morgan's laws, and a
+// negated attribute is mapped to a row for the attribute given by
+// NegatedAttrib, e.g. BooleanToMSP("employee AND NOT contractor", true)
+// is satisfied by the attributes employee and !contractor, the latter
+// of which can be obtained by NegatedAttribs. Negated comparisons are
+// mapped to the opposite ones, e.g. NOT age < 65 to age >= 65.
+// The operators AND, OR and OF are case insensitive, and AND takes
+// precedence over OR. Attribute names containing spaces, brackets,
+// commas or quotes, or equal to an operator, must be put in double
+// quotes, e.g. "\"dept(eng)\" OR \"AND\"", with double quotes and
+// backslashes in them escaped by a backslash. If the expression is
+// not well formed, a *PolicySyntaxError pointing at the offending
+// column is returned.
 func BooleanToMSP(boolExp string, convertToOnes bool) (*MSP, error) {
-	// by the Lewko-Waters algorithm we obtain a MSP struct with the property
-	// that is the the boolean expression is satisfied if and only if the corresponding
-	// rows of the msp matrix span the vector [1, 0,..., 0]
-	vec := make(data.Vector, 1)
-	vec[0] = big.NewInt(1)
-	msp, _, err := booleanToMSPIterative(boolExp, vec, 1)
+	tree, err := parsePolicy(boolExp)
 	if err != nil {
 		return nil, err
 	}
 
+	return treeToMSP(tree, convertToOnes)
+}
+
+// treeToMSP builds a msp structure from the tree as described at
+// BooleanToMSP.
+func treeToMSP(tree *policyNode, convertToOnes bool) (*MSP, error) {
+	// the MSP struct obtained from the tree has the property that
+	// the boolean expression is satisfied if and only if the
+	// corresponding rows of the msp matrix span the vector [1, 0,..., 0]
+	vec := make(data.Vector, 1)
+	vec[0] = big.NewInt(1)
+	msp, _ := tree.toMSP(vec, 1)
+
 	// if convertToOnes is set to true convert the matrix to such a MSP
 	// struct so that the boolean expression is satisfied iff the
 	// corresponding rows span the vector [1, 1,..., 1]
@@ -78,6 +103,7 @@ func BooleanToMSP(boolExp string, convertToOnes bool) (*MSP, error) {
 			}
 		}
 		//change the msp matrix by multiplying with it the matrix invMat
+		var err error
 		msp.Mat, err = msp.Mat.Mul(invMat)
 		if err != nil {
 			return nil, err
@@ -87,126 +113,436 @@ func BooleanToMSP(boolExp string, convertToOnes bool) (*MSP, error) {
 	return msp, nil
 }
 
-// booleanToMspIterative iteratively builds a msp structure by splitting the expression
-// into two parts separated by an AND or OR gate, generating a msp structure on each of
-// them, and joining both structures together. The structure is such the the boolean expression
-// assigning 1 to some attributes is satisfied iff the corresponding rows span a vector
-// [1, 0,..., 0]. The algorithm is known as Lewko-Waters algorithm, see Appendix G in
-// https://eprint.iacr.org/2010/351.pdf.
-func booleanToMSPIterative(boolExp string, vec data.Vector, c int) (*MSP, int, error) {
-	boolExp = strings.TrimSpace(boolExp)
-	numBrc := 0
-	var boolExp1 string
-	var boolExp2 string
-	var c1 int
-	var cOut int
-	var msp1 *MSP
-	var msp2 *MSP
-	var err error
-	found := false
-
-	// find the main AND or OR gate and iteratively call the function on
-	// both the sub-expressions
-	for i, e := range boolExp {
-		if e == '(' {
-			numBrc++
-			continue
+// MSPToBoolean is the inverse of BooleanToMSP: it returns a boolean
+// expression from which msp can be built by BooleanToMSP, with either
+// value of convertToOnes. The expression is in a canonical form, where
+// redundant brackets are removed, nested AND and OR gates are merged,
+// operators are in upper case and attribute names are quoted only if
+// needed. It returns an error if msp was not built by BooleanToMSP.
+func MSPToBoolean(msp *MSP) (string, error) {
+	if msp == nil || len(msp.Mat) == 0 || len(msp.Mat) != len(msp.RowToAttrib) {
+		return "", fmt.Errorf("msp should have a row for each attribute")
+	}
+	mat, err := data.NewMatrix(msp.Mat)
+	if err != nil || len(mat[0]) == 0 {
+		return "", fmt.Errorf("msp matrix is not well formed")
+	}
+	tree, err := mspMatToTree(mat, msp.RowToAttrib)
+	if err != nil {
+		return "", err
+	}
+
+	return tree.String(), nil
+}
+
+// mspMatToTree recovers the tree from which the matrix mat with the
+// rows mapped to attributes rowToAttrib was built by BooleanToMSP,
+// with either value of convertToOnes.
+func mspMatToTree(mat data.Matrix, rowToAttrib []string) (*policyNode, error) {
+	// try to decode the matrix as a MSP for the vector [1, 0,..., 0],
+	// and if it fails, convert it back from the one for [1, 1,..., 1]
+	tree, err := mspToTree(mat, rowToAttrib)
+	if err != nil {
+		onesMat := make(data.Matrix, len(mat))
+		for i, row := range mat {
+			onesMat[i] = make(data.Vector, len(row))
+			onesMat[i][0] = new(big.Int).Set(row[0])
+			for j := 1; j < len(row); j++ {
+				onesMat[i][j] = new(big.Int).Sub(row[j], row[0])
+			}
 		}
-		if e == ')' {
-			numBrc--
-			continue
+		if tree, err = mspToTree(onesMat, rowToAttrib); err != nil {
+			return nil, err
 		}
-		if numBrc == 0 && i < len(boolExp)-3 && boolExp[i:i+3] == "AND" {
-			boolExp1 = boolExp[:i]
-			boolExp2 = boolExp[i+3:]
-			vec1, vec2 := makeAndVecs(vec, c)
-			msp1, c1, err = booleanToMSPIterative(boolExp1, vec1, c+1)
-			if err != nil {
-				return nil, 0, err
-			}
-			msp2, cOut, err = booleanToMSPIterative(boolExp2, vec2, c1)
-			if err != nil {
-				return nil, 0, err
-			}
-			found = true
-			break
-		}
-		if numBrc == 0 && i < len(boolExp)-2 && boolExp[i:i+2] == "OR" {
-			boolExp1 = boolExp[:i]
-			boolExp2 = boolExp[i+2:]
-			msp1, c1, err = booleanToMSPIterative(boolExp1, vec, c)
-			if err != nil {
-				return nil, 0, err
-			}
-			msp2, cOut, err = booleanToMSPIterative(boolExp2, vec, c1)
-			if err != nil {
-				return nil, 0, err
-			}
-			found = true
-			break
+	}
+
+	return tree, nil
+}
+
+// policyNode is a node of a tree representing a boolean expression,
+// which is either an attribute or a gate that is satisfied iff at
+// least threshold of its children are satisfied. A gate with the
+// threshold 1 is an OR gate and a gate with the threshold equal to
+// the number of children is an AND gate. The complement of an
+// attribute, if set, is the attribute that holds iff it does not.
+type policyNode struct {
+	attrib     string
+	complement string
+	threshold  int
+	children   []*policyNode
+}
+
+func (n *policyNode) isAttrib() bool { return n.children == nil }
+func (n *policyNode) isOr() bool     { return !n.isAttrib() && n.threshold == 1 }
+func (n *policyNode) isAnd() bool    { return !n.isAttrib() && n.threshold == len(n.children) }
+
+// newGate returns a gate that is satisfied iff at least k of the
+// children are satisfied. A gate with a single child is replaced by
+// the child, and AND and OR gates absorb the children of the same
+// kind, so that equivalent expressions result in the same tree.
+func newGate(k int, children []*policyNode) *policyNode {
+	if len(children) == 1 {
+		return children[0]
+	}
+	and := k == len(children)
+	merged := make([]*policyNode, 0, len(children))
+	for _, c := range children {
+		if (and && c.isAnd()) || (k == 1 && c.isOr()) {
+			merged = append(merged, c.children...)
+		} else {
+			merged = append(merged, c)
 		}
 	}
+	if and {
+		k = len(merged)
+	}
 
-	// If the AND or OR gate is not found then there are two options,
-	// either the whole expression is in brackets, or the the expression
-	// is only one attribute. It neither of both is true, then
-	// an error is returned while converting the expression into an
-	// attribute
-	if !found {
-		if boolExp[0] == '(' && boolExp[len(boolExp)-1] == ')' {
-			boolExp = boolExp[1:(len(boolExp) - 1)]
-			return booleanToMSPIterative(boolExp, vec, c)
+	return &policyNode{threshold: k, children: merged}
+}
+
+// toMSP builds a msp structure for the node, given the vector vec that
+// should be spanned by the rows of the structure iff the node is
+// satisfied, and a counter c of the columns used. The structure is
+// such that the boolean expression assigning 1 to some attributes is
+// satisfied iff the corresponding rows span the vector the root is
+// given, i.e. [1, 0,..., 0]. It returns the structure and the number
+// of columns used. It follows the Lewko-Waters algorithm, see
+// Appendix G in https://eprint.iacr.org/2010/351.pdf: all the
+// children of an OR gate are given the vector of the gate, while the
+// first child of an AND gate is given the vector [0,..., 0, -1] and
+// the AND gate of the other children the vector vec extended by 1
+// in a new column.
+//
+// Similarly to Shamir's secret sharing, the i-th child of a gate with
+// a threshold 1 < k < n, where n is the number of children, is given
+// the vector vec extended by (i, i^2,..., i^(k-1)) in k-1 new columns,
+// so that any k of the vectors span vec, while fewer of them do not.
+func (n *policyNode) toMSP(vec data.Vector, c int) (*MSP, int) {
+	if n.isAttrib() {
+		mat := make(data.Matrix, 1)
+		mat[0] = padVec(vec, c)
+		return &MSP{Mat: mat, RowToAttrib: []string{n.attrib}}, c
+	}
+
+	if n.isAnd() {
+		vec1, vec2 := makeAndVecs(vec, c)
+		rest := n.children[1]
+		if len(n.children) > 2 {
+			rest = &policyNode{threshold: len(n.children) - 1, children: n.children[1:]}
 		}
+		msp1, c1 := n.children[0].toMSP(vec1, c+1)
+		msp2, cOut := rest.toMSP(vec2, c1)
+		return joinMSPs([]*MSP{msp1, msp2}, cOut), cOut
+	}
+
+	k := n.threshold
+	msps := make([]*MSP, len(n.children))
+	cOut := c + k - 1
+	for i, child := range n.children {
+		msps[i], cOut = child.toMSP(thresholdVec(vec, c, k, i), cOut)
+	}
+
+	return joinMSPs(msps, cOut), cOut
+}
+
+// makeAndVecs returns the vectors given to the first child of an AND
+// gate and to the AND gate of the other children, when the gate is
+// given the vector vec and the counter of columns c.
+func makeAndVecs(vec data.Vector, c int) (data.Vector, data.Vector) {
+	vec1 := padVec(nil, c+1)
+	vec1[c] = big.NewInt(-1)
+	vec2 := padVec(vec, c+1)
+	vec2[c] = big.NewInt(1)
+
+	return vec1, vec2
+}
 
-		if strings.Contains(boolExp, "(") || strings.Contains(boolExp, ")") {
-			return nil, 0, fmt.Errorf("bad boolean expression or attributes contain ( or )")
+// thresholdVec returns the vector given to the i-th child, counting
+// from 0, of a gate with the threshold k, when the gate is given the
+// vector vec and the counter of columns c.
+func thresholdVec(vec data.Vector, c, k, i int) data.Vector {
+	vecI := padVec(vec, c+k-1)
+	x := big.NewInt(int64(i + 1))
+	pow := big.NewInt(1)
+	for j := c; j < c+k-1; j++ {
+		pow = new(big.Int).Mul(pow, x)
+		vecI[j] = pow
+	}
+
+	return vecI
+}
+
+// padVec returns a copy of vector vec padded with zeros to length c.
+func padVec(vec data.Vector, c int) data.Vector {
+	ret := make(data.Vector, c)
+	for i := range ret {
+		if i < len(vec) {
+			ret[i] = new(big.Int).Set(vec[i])
+		} else {
+			ret[i] = big.NewInt(0)
 		}
+	}
 
-		mat := make(data.Matrix, 1)
-		mat[0] = make(data.Vector, c)
-		for i := 0; i < c; i++ {
-			if i < len(vec) {
-				mat[0][i] = new(big.Int).Set(vec[i])
-			} else {
-				mat[0][i] = big.NewInt(0)
-			}
+	return ret
+}
+
+// joinMSPs joins msp structures built on the children of a gate
+// into one, with the rows of all of them padded with zeros to c columns.
+func joinMSPs(msps []*MSP, c int) *MSP {
+	mat := make(data.Matrix, 0)
+	rowToAttribS := make([]string, 0)
+	for _, msp := range msps {
+		for _, row := range msp.Mat {
+			mat = append(mat, padVec(row, c))
 		}
+		rowToAttribS = append(rowToAttribS, msp.RowToAttrib...)
+	}
 
-		rowToAttribS := make([]string, 1)
-		rowToAttribS[0] = boolExp
-		return &MSP{Mat: mat, RowToAttrib: rowToAttribS}, c, nil
+	return &MSP{Mat: mat, RowToAttrib: rowToAttribS}
+}
 
+// mspToTree recovers the tree from which the matrix mat with the rows
+// mapped to attributes rowToAttrib was built by toMSP.
+func mspToTree(mat data.Matrix, rowToAttrib []string) (*policyNode, error) {
+	vec := make(data.Vector, 1)
+	vec[0] = big.NewInt(1)
+	d := &mspDecoder{mat: mat, rowToAttrib: rowToAttrib}
+	tree, _, err := d.decode(0, len(mat), vec, 1)
+	if err != nil {
+		return nil, err
 	}
-	// otherwise we join the two msp structures into one
-	mat := make(data.Matrix, len(msp1.Mat)+len(msp2.Mat))
-	for i := 0; i < len(msp1.Mat); i++ {
-		mat[i] = make(data.Vector, cOut)
-		for j := 0; j < len(msp1.Mat[0]); j++ {
-			mat[i][j] = msp1.Mat[i][j]
+
+	// decoding only inspects the entries that determine the tree,
+	// the others are checked by building the matrix again
+	msp, _ := tree.toMSP(vec, 1)
+	if len(msp.Mat[0]) != len(mat[0]) {
+		return nil, errNotFromPolicy
+	}
+	for i := range mat {
+		if !d.equalsVec(i, msp.Mat[i]) {
+			return nil, errNotFromPolicy
 		}
-		for j := len(msp1.Mat[0]); j < cOut; j++ {
-			mat[i][j] = big.NewInt(0)
+	}
+
+	return tree, nil
+}
+
+var errNotFromPolicy = fmt.Errorf("msp was not built from a boolean expression")
+
+// mspDecoder recovers a tree from a matrix built by toMSP.
+type mspDecoder struct {
+	mat         data.Matrix
+	rowToAttrib []string
+}
+
+// entry returns the entry of the matrix in the given row and column,
+// or 0 if the column is out of range.
+func (d *mspDecoder) entry(row, col int) *big.Int {
+	if col >= len(d.mat[row]) {
+		return big.NewInt(0)
+	}
+	return d.mat[row][col]
+}
+
+// equalsVec reports whether the row equals vec padded with zeros.
+func (d *mspDecoder) equalsVec(row int, vec data.Vector) bool {
+	for j := range d.mat[row] {
+		v := big.NewInt(0)
+		if j < len(vec) {
+			v = vec[j]
+		}
+		if d.mat[row][j].Cmp(v) != 0 {
+			return false
 		}
 	}
-	for i := 0; i < len(msp2.Mat); i++ {
-		mat[i+len(msp1.Mat)] = msp2.Mat[i]
+
+	return true
+}
+
+// lastRow returns the last of the rows [from, to) for which f holds
+// for the entry in the given column, or -1 if there is none.
+func (d *mspDecoder) lastRow(from, to, col int, f func(*big.Int) bool) int {
+	for i := to - 1; i >= from; i-- {
+		if f(d.entry(i, col)) {
+			return i
+		}
 	}
-	rowToAttribS := append(msp1.RowToAttrib, msp2.RowToAttrib...)
 
-	return &MSP{Mat: mat, RowToAttrib: rowToAttribS}, cOut, nil
+	return -1
 }
 
-// makeAndVecs is a helping structure that given a vector and and counter
-// creates two new vectors used whenever an AND gate is found in a iterative
-// step of BooleanToMsp
-func makeAndVecs(vec data.Vector, c int) (data.Vector, data.Vector) {
-	vec1 := data.NewConstantVector(c+1, big.NewInt(0))
-	vec2 := data.NewConstantVector(c+1, big.NewInt(0))
-	for i := 0; i < len(vec); i++ {
-		vec2[i].Set(vec[i])
+// decode recovers the node built from vector vec with the counter of
+// columns c, which resulted in the rows [from, to) of the matrix. It
+// returns the node and the number of columns used.
+//
+// The last row built for a node is always given the vector of the
+// node, extended in the columns introduced by the node and its
+// descendants. Thus the last row of an AND gate has the entry 1 in
+// the column c, the last row of a gate with a threshold 1 < k < n has
+// the entry n, while the last row of an OR gate has the entry 0,
+// unless all of its children but the last one are attributes.
+func (d *mspDecoder) decode(from, to int, vec data.Vector, c int) (*policyNode, int, error) {
+	if to-from == 1 && d.equalsVec(from, vec) {
+		return &policyNode{attrib: d.rowToAttrib[from]}, c, nil
 	}
-	vec1[c] = big.NewInt(-1)
-	vec2[c] = big.NewInt(1)
+	if to-from < 2 {
+		return nil, 0, errNotFromPolicy
+	}
+	last := d.entry(to-1, c)
+	switch {
+	case last.Sign() == 0 || d.equalsVec(from, vec):
+		return d.decodeOr(from, to, vec, c)
+	case last.Cmp(big.NewInt(1)) == 0:
+		return d.decodeAnd(from, to, vec, c)
+	case last.IsInt64() && last.Int64() <= int64(to-from):
+		return d.decodeThreshold(from, to, vec, c, int(last.Int64()))
+	default:
+		return nil, 0, errNotFromPolicy
+	}
+}
 
-	return vec1, vec2
+// decodeOr recovers an OR gate built from the rows [from, to). Its
+// children are either attributes with the vector vec, or gates, the
+// last row of which has a nonzero entry in the first column introduced
+// by the gate, which is zero in the rows of the following children.
+func (d *mspDecoder) decodeOr(from, to int, vec data.Vector, c int) (*policyNode, int, error) {
+	children := make([]*policyNode, 0)
+	for i := from; i < to; {
+		if d.equalsVec(i, vec) {
+			children = append(children, &policyNode{attrib: d.rowToAttrib[i]})
+			i++
+			continue
+		}
+		j := d.lastRow(i, to, c, func(e *big.Int) bool { return e.Sign() != 0 }) + 1
+		if j <= i || (i == from && j == to) {
+			return nil, 0, errNotFromPolicy
+		}
+		child, cOut, err := d.decode(i, j, vec, c)
+		if err != nil {
+			return nil, 0, err
+		}
+		children = append(children, child)
+		i, c = j, cOut
+	}
+	if len(children) < 2 {
+		return nil, 0, errNotFromPolicy
+	}
+
+	return newGate(1, children), c, nil
+}
+
+// decodeAnd recovers an AND gate built from the rows [from, to). The
+// rows of its first child have the entry -1 or 0 in the column c,
+// the last of them -1, while the rows of the AND gate of the other
+// children have the entry 1 or 0.
+func (d *mspDecoder) decodeAnd(from, to int, vec data.Vector, c int) (*policyNode, int, error) {
+	m := d.lastRow(from, to, c, func(e *big.Int) bool { return e.Cmp(big.NewInt(-1)) == 0 }) + 1
+	if m <= from || m >= to {
+		return nil, 0, errNotFromPolicy
+	}
+	vec1, vec2 := makeAndVecs(vec, c)
+	child, c1, err := d.decode(from, m, vec1, c+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	rest, cOut, err := d.decode(m, to, vec2, c1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return newGate(2, []*policyNode{child, rest}), cOut, nil
+}
+
+// decodeThreshold recovers a gate with n children and a threshold
+// 1 < k < n built from the rows [from, to). The rows of the i-th
+// child have the entry i or 0 in column c, the last of them i. The
+// last row of the first child has the entry 1 and the last row of
+// the last child the entry n^j in the k-1 columns c+j-1 introduced
+// by the gate, which does not hold for both of them in the column
+// following them.
+func (d *mspDecoder) decodeThreshold(from, to int, vec data.Vector, c, n int) (*policyNode, int, error) {
+	// split the rows among the children
+	bounds := []int{from}
+	for i := 1; i <= n; i++ {
+		x := big.NewInt(int64(i))
+		end := d.lastRow(from, to, c, func(e *big.Int) bool { return e.Cmp(x) == 0 }) + 1
+		if end <= bounds[i-1] {
+			return nil, 0, errNotFromPolicy
+		}
+		bounds = append(bounds, end)
+	}
+	if bounds[n] != to {
+		return nil, 0, errNotFromPolicy
+	}
+
+	firstLast, lastLast := bounds[1]-1, to-1
+	pow := big.NewInt(int64(n))
+	k := 1
+	for k < n && d.entry(firstLast, c+k-1).Cmp(big.NewInt(1)) == 0 &&
+		d.entry(lastLast, c+k-1).Cmp(pow) == 0 {
+		pow = new(big.Int).Mul(pow, big.NewInt(int64(n)))
+		k++
+	}
+	if k < 2 || k >= n {
+		return nil, 0, errNotFromPolicy
+	}
+
+	children := make([]*policyNode, n)
+	cOut := c + k - 1
+	for i := range children {
+		var err error
+		children[i], cOut, err = d.decode(bounds[i], bounds[i+1], thresholdVec(vec, c, k, i), cOut)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return newGate(k, children), cOut, nil
+}
+
+// String returns the boolean expression of the tree in the canonical
+// form described at MSPToBoolean.
+func (n *policyNode) String() string {
+	if n.isAttrib() {
+		if isNegatedAttrib(n.attrib) {
+			return "NOT " + quoteAttrib(n.attrib[1:])
+		}
+		return quoteAttrib(n.attrib)
+	}
+
+	parts := make([]string, len(n.children))
+	for i, c := range n.children {
+		parts[i] = c.String()
+		// AND takes precedence over OR, but the brackets
+		// are kept for clarity
+		if (n.isAnd() || n.isOr()) && !c.isAttrib() && (c.isAnd() || c.isOr()) {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	switch {
+	case n.isAnd():
+		return strings.Join(parts, " AND ")
+	case n.isOr():
+		return strings.Join(parts, " OR ")
+	default:
+		return strconv.Itoa(n.threshold) + "OF(" + strings.Join(parts, ", ") + ")"
+	}
+}
+
+// quoteAttrib returns the name of the attribute, quoted if it
+// could not be parsed as an unquoted word.
+func quoteAttrib(attrib string) string {
+	plain := attrib != ""
+	for _, r := range attrib {
+		if !isWordRune(r) || r == '\\' {
+			plain = false
+		}
+	}
+	t := token{kind: tokWord, text: attrib}
+	if plain && !t.is("AND") && !t.is("OR") && !t.is("NOT") && !t.is("OF") &&
+		!strings.HasSuffix(strings.ToUpper(attrib), "OF") {
+		return attrib
+	}
+
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(attrib) + "\""
 }
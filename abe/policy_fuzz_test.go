@@ -0,0 +1,60 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"testing"
+)
+
+func FuzzBooleanToMSP(f *testing.F) {
+	for _, exp := range []string{
+		"a AND (b OR c)",
+		"2OF(a, b AND c, d) OR e",
+		`"dept(eng)" and "x\"y" Or 1 of (f, g)`,
+		"age < 65 AND (clearance >= 3 OR x = 7)",
+		"NOT (a OR NOT b) AND NOT age < 3",
+	} {
+		f.Add(exp)
+	}
+
+	f.Fuzz(func(t *testing.T, exp string) {
+		msp, err := BooleanToMSP(exp, false)
+		if err != nil {
+			if _, ok := err.(*PolicySyntaxError); !ok {
+				t.Fatalf("Expected a syntax error for %q, got %v", exp, err)
+			}
+			return
+		}
+		canonical, err := MSPToBoolean(msp)
+		if err != nil {
+			t.Fatalf("Error while recovering %q: %v", exp, err)
+		}
+		mspCheck, err := BooleanToMSP(canonical, false)
+		if err != nil {
+			t.Fatalf("Error while processing %q: %v", canonical, err)
+		}
+		canonicalCheck, err := MSPToBoolean(mspCheck)
+		if err != nil {
+			t.Fatalf("Error while recovering %q: %v", canonical, err)
+		}
+		if canonical != canonicalCheck {
+			t.Fatalf("Canonical expressions %q and %q differ", canonical, canonicalCheck)
+		}
+	})
+}
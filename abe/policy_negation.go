@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import "strings"
+
+// NegatedAttrib returns the attribute that stands for the absence of
+// attrib, i.e. NOT attrib in a policy. Since MSP structures can only
+// express monotone policies, a negated attribute is an attribute on its
+// own, which the entities that do not own attrib should be given keys
+// for, see NegatedAttribs.
+func NegatedAttrib(attrib string) string {
+	return "!" + attrib
+}
+
+// isNegatedAttrib reports whether attrib was returned by NegatedAttrib.
+func isNegatedAttrib(attrib string) bool {
+	return strings.HasPrefix(attrib, "!")
+}
+
+// NegatedAttribs returns the negated attributes of all the attributes
+// in universe that are not in gamma. Adding them to gamma when
+// generating keys, e.g. with FAME.GenerateAttribKeys, allows an entity
+// with attributes gamma to satisfy policies with NOT gates over the
+// attributes in universe.
+func NegatedAttribs(gamma, universe []string) []string {
+	owned := make(map[string]bool)
+	for _, a := range gamma {
+		owned[a] = true
+	}
+	negated := make([]string, 0)
+	for _, a := range universe {
+		if !owned[a] {
+			negated = append(negated, NegatedAttrib(a))
+			owned[a] = true
+		}
+	}
+
+	return negated
+}
+
+// negate returns a tree that is satisfied iff the tree of n is not. By
+// De Morgan's laws, a gate with the threshold k and n children is
+// replaced by a gate with the threshold n-k+1 of the negated children,
+// so that only the attributes are negated.
+func (n *policyNode) negate() *policyNode {
+	if n.isAttrib() {
+		if n.complement != "" {
+			return &policyNode{attrib: n.complement, complement: n.attrib}
+		}
+		if isNegatedAttrib(n.attrib) {
+			return &policyNode{attrib: n.attrib[1:]}
+		}
+		return &policyNode{attrib: NegatedAttrib(n.attrib), complement: n.attrib}
+	}
+
+	children := make([]*policyNode, len(n.children))
+	for i, c := range n.children {
+		children[i] = c.negate()
+	}
+
+	return newGate(len(children)-n.threshold+1, children)
+}
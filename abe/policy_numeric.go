@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// NumericBits is the number of bits of the values of numeric
+// attributes that can be compared in policies.
+const NumericBits = 32
+
+// NumericAttribs returns the attributes that represent the numeric
+// attribute name with the given value, for example an attribute
+// "age" of an entity that is 42 years old. The attributes should be
+// added to the attributes of the entity when generating its keys, e.g.
+// with FAME.GenerateAttribKeys, so that it can satisfy comparisons of
+// the attribute, like "age < 65", in policies.
+//
+// As in the bag-of-bits encoding of Bethencourt, Sahai and Waters, see
+// Section 4.3 in https://www.cs.utexas.edu/~bwaters/publications/papers/cp-abe.pdf,
+// there is an attribute for each of the NumericBits bits of the value,
+// stating the position and the value of the bit.
+func NumericAttribs(name string, value uint32) []string {
+	attribs := make([]string, NumericBits)
+	for i := range attribs {
+		attribs[i] = bitAttrib(name, i, (value>>uint(i))&1 == 1)
+	}
+
+	return attribs
+}
+
+// bitAttrib returns the attribute stating that the i-th bit
+// of the numeric attribute name is set or not.
+func bitAttrib(name string, i int, set bool) string {
+	b := 0
+	if set {
+		b = 1
+	}
+	return name + "#bit" + strconv.Itoa(i) + ":" + strconv.Itoa(b)
+}
+
+// bitNode returns a leaf for the attribute stating that the i-th bit
+// of the numeric attribute name is set or not, with the complement
+// stating the opposite.
+func bitNode(name string, i int, set bool) *policyNode {
+	return &policyNode{attrib: bitAttrib(name, i, set), complement: bitAttrib(name, i, !set)}
+}
+
+// comparisonNode returns a tree of the attributes of the bits of the
+// numeric attribute name that is satisfied iff the attribute compares
+// to c as given by the operator op, which is one of <, <=, >, >=, =.
+// Using each of the attributes at most once, it has at most NumericBits
+// leaves. It returns an error if the comparison is never satisfied.
+func comparisonNode(name, op string, c uint32) (*policyNode, error) {
+	never := fmt.Errorf("comparison %s %s %d is never satisfied", name, op, c)
+	switch op {
+	case "<":
+		if c == 0 {
+			return nil, never
+		}
+		return lessEqualNode(name, c-1), nil
+	case "<=":
+		return lessEqualNode(name, c), nil
+	case ">":
+		if c == math.MaxUint32 {
+			return nil, never
+		}
+		return greaterEqualNode(name, c+1), nil
+	case ">=":
+		return greaterEqualNode(name, c), nil
+	case "=", "==":
+		bits := make([]*policyNode, NumericBits)
+		for i := range bits {
+			bits[i] = bitNode(name, i, (c>>uint(i))&1 == 1)
+		}
+		return newGate(NumericBits, bits), nil
+	default:
+		return nil, fmt.Errorf("unknown comparison operator %s", op)
+	}
+}
+
+// greaterEqualNode returns a tree satisfied iff the numeric attribute
+// name is at least c. With t the number of trailing zero bits of c,
+// this holds iff the t-th bit is set and the remaining bits of the
+// attribute are at least those of c, which is built from the t-th bit
+// up: if the i-th bit of c is set, the i-th bit of the attribute needs
+// to be set as well, otherwise setting it suffices.
+func greaterEqualNode(name string, c uint32) *policyNode {
+	if c == 0 {
+		// any value is accepted
+		return newGate(1, []*policyNode{bitNode(name, 0, false), bitNode(name, 0, true)})
+	}
+	t := 0
+	for (c>>uint(t))&1 == 0 {
+		t++
+	}
+	node := bitNode(name, t, true)
+	for i := t + 1; i < NumericBits; i++ {
+		bit := bitNode(name, i, true)
+		if (c>>uint(i))&1 == 1 {
+			node = newGate(2, []*policyNode{bit, node})
+		} else {
+			node = newGate(1, []*policyNode{bit, node})
+		}
+	}
+
+	return node
+}
+
+// lessEqualNode returns a tree satisfied iff the numeric attribute
+// name is at most c, built as in greaterEqualNode with the roles of
+// the set and unset bits swapped.
+func lessEqualNode(name string, c uint32) *policyNode {
+	if c == math.MaxUint32 {
+		return greaterEqualNode(name, 0)
+	}
+	t := 0
+	for (c>>uint(t))&1 == 1 {
+		t++
+	}
+	node := bitNode(name, t, false)
+	for i := t + 1; i < NumericBits; i++ {
+		bit := bitNode(name, i, false)
+		if (c>>uint(i))&1 == 0 {
+			node = newGate(2, []*policyNode{bit, node})
+		} else {
+			node = newGate(1, []*policyNode{bit, node})
+		}
+	}
+
+	return node
+}
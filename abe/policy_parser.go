@@ -0,0 +1,338 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PolicySyntaxError is returned when a boolean expression describing
+// a policy cannot be parsed. Col is the position of the offending
+// character in the expression, counting characters from 1.
+type PolicySyntaxError struct {
+	Col int
+	Msg string
+}
+
+func (e *PolicySyntaxError) Error() string {
+	return fmt.Sprintf("bad boolean expression at column %d: %s", e.Col, e.Msg)
+}
+
+// tokenKind is the kind of a token of a boolean expression.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokComma
+	tokWord   // unquoted attribute name, operator, threshold or number
+	tokQuoted // quoted attribute name
+	tokCmp    // comparison operator
+)
+
+// token is a token of a boolean expression starting at column col.
+type token struct {
+	kind tokenKind
+	text string
+	col  int
+}
+
+// describe returns a description of the token for error messages.
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokQuoted:
+		return strconv.Quote(t.text)
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+// is reports whether t is the unquoted word w, ignoring case.
+func (t token) is(w string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, w)
+}
+
+// isWordRune reports whether r can be a part of an unquoted word.
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && r != '(' && r != ')' && r != ',' && r != '"' && !isCmpRune(r)
+}
+
+// isCmpRune reports whether r can be a part of a comparison operator.
+func isCmpRune(r rune) bool {
+	return r == '<' || r == '>' || r == '='
+}
+
+// tokenize splits a boolean expression into tokens. Attribute names
+// can be quoted with double quotes, within which a double quote or
+// a backslash must be escaped with a backslash.
+func tokenize(exp string) ([]token, error) {
+	runes := []rune(exp)
+	tokens := make([]token, 0)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		col := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", col: col})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", col: col})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", col: col})
+			i++
+		case r == '"':
+			var b strings.Builder
+			i++
+			for {
+				if i == len(runes) {
+					return nil, &PolicySyntaxError{Col: col, Msg: "unterminated quoted attribute"}
+				}
+				if runes[i] == '"' {
+					i++
+					break
+				}
+				if runes[i] == '\\' {
+					if i+1 == len(runes) || (runes[i+1] != '"' && runes[i+1] != '\\') {
+						return nil, &PolicySyntaxError{Col: i + 1, Msg: "only \\\" and \\\\ can be escaped"}
+					}
+					i++
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if b.Len() == 0 {
+				return nil, &PolicySyntaxError{Col: col, Msg: "empty attribute"}
+			}
+			tokens = append(tokens, token{kind: tokQuoted, text: b.String(), col: col})
+		case isCmpRune(r):
+			start := i
+			for i < len(runes) && isCmpRune(runes[i]) {
+				i++
+			}
+			op := string(runes[start:i])
+			switch op {
+			case "<", "<=", ">", ">=", "=", "==":
+			default:
+				return nil, &PolicySyntaxError{Col: col, Msg: "unknown comparison operator " + op}
+			}
+			tokens = append(tokens, token{kind: tokCmp, text: op, col: col})
+		default:
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokWord, text: string(runes[start:i]), col: col})
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, col: len(runes) + 1}), nil
+}
+
+// policyParser is a recursive descent parser of boolean expressions
+// with the grammar
+//
+//	expr      = and { "OR" and }
+//	and       = factor { "AND" factor }
+//	factor     = "NOT" factor | "(" expr ")" | threshold | comparison | attribute
+//	threshold  = number "OF" "(" expr { "," expr } ")"
+//	comparison = attribute ( "<" | "<=" | ">" | ">=" | "=" ) number
+//
+// where the operators are case insensitive, NOT takes precedence over
+// AND, which takes precedence over OR, and the number and OF may also
+// be written together, as in 2OF(a, b, c).
+type policyParser struct {
+	tokens []token
+	pos    int
+}
+
+// parsePolicy parses a boolean expression into a policy tree.
+func parsePolicy(exp string) (*policyNode, error) {
+	tokens, err := tokenize(exp)
+	if err != nil {
+		return nil, err
+	}
+	p := &policyParser{tokens: tokens}
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(0); t.kind != tokEOF {
+		return nil, p.errorf(t, "expected AND, OR or end of expression, found %s", t.describe())
+	}
+
+	return node, nil
+}
+
+func (p *policyParser) peek(k int) token {
+	if p.pos+k < len(p.tokens) {
+		return p.tokens[p.pos+k]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *policyParser) next() token {
+	t := p.peek(0)
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *policyParser) errorf(t token, format string, args ...interface{}) error {
+	return &PolicySyntaxError{Col: t.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *policyParser) parseExpr() (*policyNode, error) {
+	nodes := make([]*policyNode, 0)
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !p.peek(0).is("OR") {
+			return newGate(1, nodes), nil
+		}
+		p.next()
+	}
+}
+
+func (p *policyParser) parseAnd() (*policyNode, error) {
+	nodes := make([]*policyNode, 0)
+	for {
+		node, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		if !p.peek(0).is("AND") {
+			return newGate(len(nodes), nodes), nil
+		}
+		p.next()
+	}
+}
+
+func (p *policyParser) parseFactor() (*policyNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokLParen:
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.next(); end.kind != tokRParen {
+			return nil, p.errorf(end, "expected ')' closing the one at column %d, found %s", t.col, end.describe())
+		}
+		return node, nil
+	case tokQuoted:
+		return p.parseAttrib(t)
+	case tokWord:
+		if t.is("NOT") {
+			node, err := p.parseFactor()
+			if err != nil {
+				return nil, err
+			}
+			return node.negate(), nil
+		}
+		if t.is("AND") || t.is("OR") {
+			return nil, p.errorf(t, "expected an attribute or '(', found operator %s", t.describe())
+		}
+		if num, ok := p.thresholdPrefix(t); ok {
+			return p.parseThreshold(t, num)
+		}
+		return p.parseAttrib(t)
+	default:
+		return nil, p.errorf(t, "expected an attribute or '(', found %s", t.describe())
+	}
+}
+
+// thresholdPrefix checks if the word t starts a threshold gate, i.e.
+// it is of the form kOF or k followed by OF, and the next token is
+// '('. If so, it consumes the tokens up to '(' and returns k.
+func (p *policyParser) thresholdPrefix(t token) (string, bool) {
+	digits := strings.TrimRightFunc(t.text, unicode.IsLetter)
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return "", false
+	}
+	switch {
+	case strings.EqualFold(t.text[len(digits):], "OF") && p.peek(0).kind == tokLParen:
+		return digits, true
+	case digits == t.text && p.peek(0).is("OF") && p.peek(1).kind == tokLParen:
+		p.next()
+		return digits, true
+	}
+
+	return "", false
+}
+
+func (p *policyParser) parseThreshold(t token, num string) (*policyNode, error) {
+	open := p.next()
+	nodes := make([]*policyNode, 0)
+	for {
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		end := p.next()
+		if end.kind == tokRParen {
+			break
+		}
+		if end.kind != tokComma {
+			return nil, p.errorf(end, "expected ',' or ')' closing the one at column %d, found %s", open.col, end.describe())
+		}
+	}
+
+	k, err := strconv.Atoi(num)
+	if err != nil || k < 1 || k > len(nodes) {
+		return nil, p.errorf(t, "threshold %s of a gate with %d arguments", num, len(nodes))
+	}
+
+	return newGate(k, nodes), nil
+}
+
+// parseAttrib parses the attribute t, which is compared to a number
+// if followed by a comparison operator.
+func (p *policyParser) parseAttrib(t token) (*policyNode, error) {
+	if isNegatedAttrib(t.text) {
+		return nil, p.errorf(t, "attribute %s starts with !, which is reserved for negated attributes", t.describe())
+	}
+	if p.peek(0).kind != tokCmp {
+		return &policyNode{attrib: t.text}, nil
+	}
+	op := p.next()
+	num := p.next()
+	c, err := strconv.ParseUint(num.text, 10, NumericBits)
+	if num.kind != tokWord || err != nil {
+		return nil, p.errorf(num, "expected a number between 0 and %d, found %s", uint32(math.MaxUint32), num.describe())
+	}
+	node, err := comparisonNode(t.text, op.text, uint32(c))
+	if err != nil {
+		return nil, p.errorf(op, "%v", err)
+	}
+
+	return node, nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+)
+
+// Satisfies reports whether the set of attributes attribs satisfies
+// the policy of the MSP, i.e. whether the rows mapped to the attributes
+// span the vector [1, 0,..., 0], or [1, 1,..., 1] if convertToOnes is
+// set to true, as in BooleanToMSP.
+func (m *MSP) Satisfies(attribs []string, convertToOnes bool) bool {
+	_, _, err := m.Reconstruct(attribs, convertToOnes)
+	return err == nil
+}
+
+// Reconstruct finds a set of the rows of the MSP, mapped to the
+// attributes attribs, that span the vector [1, 0,..., 0], or
+// [1, 1,..., 1] if convertToOnes is set to true, as in BooleanToMSP.
+// It returns the indices of the rows and the coefficients alpha of
+// the linear combination of the rows that gives the vector. The rows
+// are linearly independent and all the coefficients are nonzero, thus
+// no row can be left out, which keeps the work of the decryption low.
+// If the MSP was built by BooleanToMSP, the set of the rows is also
+// the smallest possible. An error is returned if the attributes do
+// not satisfy the policy.
+func (m *MSP) Reconstruct(attribs []string, convertToOnes bool) ([]int, data.Vector, error) {
+	if len(m.Mat) == 0 || len(m.Mat[0]) == 0 {
+		return nil, nil, fmt.Errorf("empty msp matrix")
+	}
+	if _, err := data.NewMatrix(m.Mat); err != nil {
+		return nil, nil, err
+	}
+	if len(m.RowToAttrib) != len(m.Mat) {
+		return nil, nil, fmt.Errorf("the msp does not label all the rows")
+	}
+	p := m.P
+	if p == nil {
+		p = bn256.Order
+	}
+
+	owned := make(map[string]bool)
+	for _, at := range attribs {
+		owned[at] = true
+	}
+	rows := make([]int, 0)
+	for i, at := range m.RowToAttrib {
+		if owned[at] {
+			rows = append(rows, i)
+		}
+	}
+	// if the MSP was built from a boolean expression, the smallest
+	// set of the attributes satisfying it can be read from its tree
+	if tree, err := mspMatToTree(m.Mat, m.RowToAttrib); err == nil {
+		leaf := 0
+		if minRows, ok := tree.minSatisfying(owned, &leaf); ok {
+			rows = minRows
+		}
+	}
+	mat := make(data.Matrix, len(rows))
+	for i, row := range rows {
+		mat[i] = m.Mat[row]
+	}
+	if len(mat) == 0 {
+		return nil, nil, fmt.Errorf("the attributes do not satisfy the policy")
+	}
+
+	target := data.NewConstantVector(len(mat[0]), big.NewInt(0))
+	for i := range target {
+		if i == 0 || convertToOnes {
+			target[i].SetInt64(1)
+		}
+	}
+	alpha, err := data.GaussianEliminationSolver(mat.Transpose(), target, p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the attributes do not satisfy the policy")
+	}
+
+	// the solution has nonzero entries only at the pivots of the
+	// elimination, which belong to linearly independent rows
+	minRows := make([]int, 0, len(rows))
+	minAlpha := make(data.Vector, 0, len(rows))
+	for i, a := range alpha {
+		if a.Sign() != 0 {
+			minRows = append(minRows, rows[i])
+			minAlpha = append(minAlpha, a)
+		}
+	}
+
+	return minRows, minAlpha, nil
+}
+
+// minSatisfying returns the indices of the leaves in the smallest set
+// of the owned attributes satisfying the tree, where the leaves are
+// indexed in the order they appear, starting at *leaf, which is
+// advanced past the leaves of the tree. It reports false if the owned
+// attributes do not satisfy the tree.
+func (n *policyNode) minSatisfying(owned map[string]bool, leaf *int) ([]int, bool) {
+	if n.isAttrib() {
+		*leaf++
+		if owned[n.attrib] {
+			return []int{*leaf - 1}, true
+		}
+		return nil, false
+	}
+
+	satisfied := make([][]int, 0, len(n.children))
+	for _, child := range n.children {
+		if rows, ok := child.minSatisfying(owned, leaf); ok {
+			satisfied = append(satisfied, rows)
+		}
+	}
+	if len(satisfied) < n.threshold {
+		return nil, false
+	}
+	sort.SliceStable(satisfied, func(i, j int) bool {
+		return len(satisfied[i]) < len(satisfied[j])
+	})
+	rows := make([]int, 0)
+	for _, childRows := range satisfied[:n.threshold] {
+		rows = append(rows, childRows...)
+	}
+	sort.Ints(rows)
+
+	return rows, true
+}
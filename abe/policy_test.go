@@ -17,9 +17,13 @@
 package abe
 
 import (
+	"fmt"
+	"math"
 	"math/big"
+	"math/rand"
 	"testing"
 
+	"github.com/fentec-project/bn256"
 	"github.com/fentec-project/gofe/data"
 	"github.com/stretchr/testify/assert"
 )
@@ -53,3 +57,549 @@ func TestBooleanToMsp(t *testing.T) {
 	_, err = BooleanToMSP("1 AND ((6 OR 7) AND (8 OR 9)) OR ((2 AND 3) OR (4 AND 5)))", true)
 	assert.Error(t, err)
 }
+
+// spans checks if the rows of the msp matrix corresponding to the
+// given attributes span the vector [1, 0,..., 0], or [1, 1,..., 1]
+// if ones is set.
+func spans(msp *MSP, attribs []string, ones bool, p *big.Int) bool {
+	owned := make(map[string]bool)
+	for _, a := range attribs {
+		owned[a] = true
+	}
+	m := make(data.Matrix, 0)
+	for i, a := range msp.RowToAttrib {
+		if owned[a] {
+			m = append(m, msp.Mat[i])
+		}
+	}
+	if len(m) == 0 {
+		return false
+	}
+	v := data.NewConstantVector(len(msp.Mat[0]), big.NewInt(0))
+	for i := range v {
+		if i == 0 || ones {
+			v[i] = big.NewInt(1)
+		}
+	}
+	_, err := data.GaussianEliminationSolver(m.Transpose(), v, p)
+
+	return err == nil
+}
+
+func TestBooleanToMsp_Threshold(t *testing.T) {
+	p := big.NewInt(1000003)
+	tests := []struct {
+		exp   string
+		sat   [][]string
+		unsat [][]string
+	}{
+		{
+			exp:   "2OF(a, b, c)",
+			sat:   [][]string{{"a", "b"}, {"a", "c"}, {"b", "c"}, {"a", "b", "c"}},
+			unsat: [][]string{{"a"}, {"b"}, {"c"}, {}},
+		},
+		{
+			exp:   "x AND 3OF(a, b OR c, (d AND e), 1OF(f, g))",
+			sat:   [][]string{{"x", "a", "c", "f"}, {"x", "d", "e", "b", "g"}, {"x", "a", "b", "d", "e", "f"}},
+			unsat: [][]string{{"a", "c", "f"}, {"x", "a", "b", "c"}, {"x", "a", "d", "f", "g"}},
+		},
+		{
+			exp:   "4OF(a, b, c, d) OR e",
+			sat:   [][]string{{"a", "b", "c", "d"}, {"e"}},
+			unsat: [][]string{{"a", "b", "c"}, {"b", "c", "d"}},
+		},
+	}
+
+	for _, test := range tests {
+		for _, ones := range []bool{false, true} {
+			msp, err := BooleanToMSP(test.exp, ones)
+			if err != nil {
+				t.Fatalf("Error while processing a boolean expression: %v", err)
+			}
+			for _, attribs := range test.sat {
+				assert.True(t, spans(msp, attribs, ones, p), "%v should satisfy %s", attribs, test.exp)
+			}
+			for _, attribs := range test.unsat {
+				assert.False(t, spans(msp, attribs, ones, p), "%v should not satisfy %s", attribs, test.exp)
+			}
+		}
+	}
+
+	// a threshold gate is as compact as an equivalent expression
+	msp, err := BooleanToMSP("2OF(a, b, c)", false)
+	if err != nil {
+		t.Fatalf("Error while processing a boolean expression: %v", err)
+	}
+	assert.Equal(t, 3, len(msp.Mat))
+	assert.Equal(t, []string{"a", "b", "c"}, msp.RowToAttrib)
+
+	for _, exp := range []string{"0OF(a, b)", "3OF(a, b)", "2OF(a, , b)", "2OF(a, b"} {
+		_, err = BooleanToMSP(exp, false)
+		assert.Error(t, err, "%s should not be accepted", exp)
+	}
+}
+
+func TestBooleanToMsp_LewkoWaters(t *testing.T) {
+	// policies with only AND and OR gates result in the matrices
+	// of the Lewko-Waters algorithm
+	tests := []struct {
+		exp string
+		mat [][]int64
+	}{
+		{"a AND b AND c", [][]int64{{0, -1, 0}, {0, 0, -1}, {1, 1, 1}}},
+		{"a OR (b AND (c OR d))", [][]int64{{1, 0}, {0, -1}, {1, 1}, {1, 1}}},
+	}
+	for _, test := range tests {
+		msp, err := BooleanToMSP(test.exp, false)
+		if err != nil {
+			t.Fatalf("Error while processing a boolean expression: %v", err)
+		}
+		mat := make(data.Matrix, len(test.mat))
+		for i, row := range test.mat {
+			mat[i] = make(data.Vector, len(row))
+			for j, e := range row {
+				mat[i][j] = big.NewInt(e)
+			}
+		}
+		assert.Equal(t, mat, msp.Mat)
+	}
+}
+
+func TestBooleanToMsp_Parser(t *testing.T) {
+	p := big.NewInt(1000003)
+	tests := []struct {
+		exp   string
+		sat   [][]string
+		unsat [][]string
+	}{
+		{
+			// names containing operators and brackets
+			exp:   `ORGANIZATION and "dept(eng)" Or "AND"`,
+			sat:   [][]string{{"ORGANIZATION", "dept(eng)"}, {"AND"}},
+			unsat: [][]string{{"ORGANIZATION"}, {"dept(eng)"}},
+		},
+		{
+			// AND takes precedence over OR
+			exp:   "a AND b OR c",
+			sat:   [][]string{{"a", "b"}, {"c"}},
+			unsat: [][]string{{"a"}, {"b"}},
+		},
+		{
+			exp:   "a OR b AND c",
+			sat:   [][]string{{"a"}, {"b", "c"}},
+			unsat: [][]string{{"b"}, {"c"}},
+		},
+		{
+			exp:   `2 of ("a \"quoted\" name", b, "c\\d")`,
+			sat:   [][]string{{`a "quoted" name`, "b"}, {"b", `c\d`}},
+			unsat: [][]string{{"b"}, {`a "quoted" name`}},
+		},
+	}
+
+	for _, test := range tests {
+		msp, err := BooleanToMSP(test.exp, false)
+		if err != nil {
+			t.Fatalf("Error while processing a boolean expression: %v", err)
+		}
+		for _, attribs := range test.sat {
+			assert.True(t, spans(msp, attribs, false, p), "%v should satisfy %s", attribs, test.exp)
+		}
+		for _, attribs := range test.unsat {
+			assert.False(t, spans(msp, attribs, false, p), "%v should not satisfy %s", attribs, test.exp)
+		}
+	}
+
+	errTests := []struct {
+		exp string
+		col int
+	}{
+		{"a AND", 6},
+		{"a AND (b OR c", 14},
+		{"a b", 3},
+		{"(a OR b))", 9},
+		{"a OR AND b", 6},
+		{`a OR "b`, 6},
+		{`"a\b"`, 3},
+		{"3OF(a, b)", 1},
+		{"2OF(a; b)", 8},
+		{"", 1},
+	}
+	for _, test := range errTests {
+		_, err := BooleanToMSP(test.exp, false)
+		if assert.Error(t, err, "%s should not be accepted", test.exp) {
+			syntaxErr, ok := err.(*PolicySyntaxError)
+			if assert.True(t, ok, "%s should give a syntax error", test.exp) {
+				assert.Equal(t, test.col, syntaxErr.Col, "wrong column of the error in %s", test.exp)
+			}
+		}
+	}
+}
+
+func TestBooleanToMsp_Numeric(t *testing.T) {
+	p := big.NewInt(1000003)
+	values := []uint32{0, 1, 2, 3, 4, 5, 17, 64, 65, 66, 1 << 20, math.MaxUint32 - 1, math.MaxUint32}
+	tests := []struct {
+		op  string
+		cmp func(x, c uint32) bool
+	}{
+		{"<", func(x, c uint32) bool { return x < c }},
+		{"<=", func(x, c uint32) bool { return x <= c }},
+		{">", func(x, c uint32) bool { return x > c }},
+		{">=", func(x, c uint32) bool { return x >= c }},
+		{"=", func(x, c uint32) bool { return x == c }},
+	}
+
+	for _, test := range tests {
+		for _, c := range values {
+			exp := fmt.Sprintf("age %s %d", test.op, c)
+			msp, err := BooleanToMSP(exp, false)
+			if (test.op == "<" && c == 0) || (test.op == ">" && c == math.MaxUint32) {
+				assert.Error(t, err, "%s should not be accepted", exp)
+				continue
+			}
+			if err != nil {
+				t.Fatalf("Error while processing a boolean expression: %v", err)
+			}
+			assert.True(t, len(msp.Mat) <= NumericBits)
+			for _, x := range values {
+				assert.Equal(t, test.cmp(x, c), spans(msp, NumericAttribs("age", x), false, p),
+					"wrong result of %s for age %d", exp, x)
+			}
+		}
+	}
+
+	// comparisons combined with other attributes
+	msp, err := BooleanToMSP("(clearance>=3 AND age<65) OR admin", true)
+	if err != nil {
+		t.Fatalf("Error while processing a boolean expression: %v", err)
+	}
+	attribs := append(NumericAttribs("clearance", 4), NumericAttribs("age", 40)...)
+	assert.True(t, spans(msp, attribs, true, p))
+	attribs = append(NumericAttribs("clearance", 4), NumericAttribs("age", 70)...)
+	assert.False(t, spans(msp, attribs, true, p))
+	assert.True(t, spans(msp, append(attribs, "admin"), true, p))
+
+	for _, exp := range []string{"age < x", "age <> 3", "age < -1", "age < 4294967296", "age >= 2OF(a, b)", "age 3"} {
+		_, err = BooleanToMSP(exp, false)
+		assert.Error(t, err, "%s should not be accepted", exp)
+	}
+}
+
+func TestBooleanToMsp_Not(t *testing.T) {
+	p := big.NewInt(1000003)
+	tests := []struct {
+		exp   string
+		sat   [][]string
+		unsat [][]string
+	}{
+		{
+			exp:   "employee AND NOT contractor",
+			sat:   [][]string{{"employee", "!contractor"}},
+			unsat: [][]string{{"employee"}, {"employee", "contractor"}, {"!contractor"}},
+		},
+		{
+			// NOT takes precedence over AND
+			exp:   "NOT a AND b",
+			sat:   [][]string{{"!a", "b"}},
+			unsat: [][]string{{"a", "b"}, {"!b"}},
+		},
+		{
+			exp:   "NOT (a AND (b OR NOT c))",
+			sat:   [][]string{{"!a"}, {"!b", "c"}},
+			unsat: [][]string{{"a", "b"}, {"!b", "!c"}, {"a", "c"}},
+		},
+		{
+			// at most one of a, b and c
+			exp:   "NOT 2OF(a, b, c)",
+			sat:   [][]string{{"!a", "!b"}, {"!b", "!c"}, {"!a", "!b", "!c"}},
+			unsat: [][]string{{"!a"}, {"!c"}},
+		},
+		{
+			exp:   "NOT NOT a",
+			sat:   [][]string{{"a"}},
+			unsat: [][]string{{"!a"}},
+		},
+	}
+
+	for _, test := range tests {
+		for _, ones := range []bool{false, true} {
+			msp, err := BooleanToMSP(test.exp, ones)
+			if err != nil {
+				t.Fatalf("Error while processing a boolean expression: %v", err)
+			}
+			for _, attribs := range test.sat {
+				assert.True(t, spans(msp, attribs, ones, p), "%v should satisfy %s", attribs, test.exp)
+			}
+			for _, attribs := range test.unsat {
+				assert.False(t, spans(msp, attribs, ones, p), "%v should not satisfy %s", attribs, test.exp)
+			}
+		}
+	}
+
+	// negated comparisons are mapped to the opposite ones
+	for _, c := range []uint32{0, 3, 64} {
+		msp, err := BooleanToMSP(fmt.Sprintf("NOT age < %d", c), false)
+		if err != nil && c != 0 {
+			t.Fatalf("Error while processing a boolean expression: %v", err)
+		}
+		mspCheck, err := BooleanToMSP(fmt.Sprintf("age >= %d", c), false)
+		if err != nil {
+			t.Fatalf("Error while processing a boolean expression: %v", err)
+		}
+		if c != 0 {
+			assert.Equal(t, mspCheck, msp)
+		}
+	}
+	msp, err := BooleanToMSP("NOT (age = 5)", false)
+	if err != nil {
+		t.Fatalf("Error while processing a boolean expression: %v", err)
+	}
+	for _, x := range []uint32{0, 4, 5, 6, 1 << 30} {
+		assert.Equal(t, x != 5, spans(msp, NumericAttribs("age", x), false, p))
+	}
+
+	assert.Equal(t, []string{"!b", "!d"}, NegatedAttribs([]string{"a", "c"}, []string{"a", "b", "c", "d", "b"}))
+
+	for _, exp := range []string{"NOT", "a AND NOT", "NOT AND a", "!a", `"!a" OR b`} {
+		_, err := BooleanToMSP(exp, false)
+		assert.Error(t, err, "%s should not be accepted", exp)
+	}
+}
+
+func TestMSPToBoolean(t *testing.T) {
+	tests := []struct {
+		exp       string
+		canonical string
+	}{
+		{"a", "a"},
+		{"((a))", "a"},
+		{"a and (b AND c)", "a AND b AND c"},
+		{"a OR (b or c) OR d", "a OR b OR c OR d"},
+		{"a AND b OR c", "(a AND b) OR c"},
+		{"(a OR b) AND 2of(c, d AND e, f)", "(a OR b) AND 2OF(c, d AND e, f)"},
+		{"1OF(a, b) AND 3OF(c, d, e)", "(a OR b) AND c AND d AND e"},
+		{`"dept(eng)" OR "or" OR "x\"y" OR "2of"`, `"dept(eng)" OR "or" OR "x\"y" OR "2of"`},
+		{"NOT (a OR NOT b) AND not \"not\"", "NOT a AND b AND NOT \"not\""},
+	}
+
+	for _, test := range tests {
+		for _, ones := range []bool{false, true} {
+			msp, err := BooleanToMSP(test.exp, ones)
+			if err != nil {
+				t.Fatalf("Error while processing a boolean expression: %v", err)
+			}
+			exp, err := MSPToBoolean(msp)
+			if err != nil {
+				t.Fatalf("Error while recovering a boolean expression: %v", err)
+			}
+			assert.Equal(t, test.canonical, exp)
+		}
+	}
+
+	// a matrix that was not built from a boolean expression
+	msp := &MSP{Mat: data.Matrix{{big.NewInt(1), big.NewInt(3)}, {big.NewInt(1), big.NewInt(5)}},
+		RowToAttrib: []string{"a", "b"}}
+	_, err := MSPToBoolean(msp)
+	assert.Error(t, err)
+}
+
+// randomPolicy generates a random policy tree of the given depth
+// with attributes from names.
+func randomPolicy(r *rand.Rand, depth int, names []string) *policyNode {
+	if depth == 0 || r.Intn(3) == 0 {
+		return &policyNode{attrib: names[r.Intn(len(names))]}
+	}
+	n := 2 + r.Intn(3)
+	children := make([]*policyNode, n)
+	for i := range children {
+		children[i] = randomPolicy(r, depth-1, names)
+	}
+
+	return newGate(1+r.Intn(n), children)
+}
+
+// satisfies evaluates the policy tree on a set of attributes.
+func (n *policyNode) satisfies(attribs map[string]bool) bool {
+	if n.isAttrib() {
+		return attribs[n.attrib]
+	}
+	count := 0
+	for _, c := range n.children {
+		if c.satisfies(attribs) {
+			count++
+		}
+	}
+
+	return count >= n.threshold
+}
+
+// TestMSPToBoolean_RoundTrip checks on random policies that the
+// canonical expression of a policy results in the same MSP, and
+// that the MSP is satisfied by the same sets of attributes as
+// the policy.
+func TestMSPToBoolean_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	p := big.NewInt(1000003)
+	names := []string{"a", "b", "c", "ORGANIZATION", "dept(eng)", "x y", "AND", `q"uote`, `back\slash`, "2of", "ünïcode"}
+
+	for it := 0; it < 300; it++ {
+		tree := randomPolicy(r, 3, names)
+		exp := tree.String()
+		msp, err := BooleanToMSP(exp, false)
+		if err != nil {
+			t.Fatalf("Error while processing %s: %v", exp, err)
+		}
+		expCheck, err := MSPToBoolean(msp)
+		if err != nil {
+			t.Fatalf("Error while recovering %s: %v", exp, err)
+		}
+		assert.Equal(t, exp, expCheck)
+
+		for k := 0; k < 5; k++ {
+			attribs := make(map[string]bool)
+			owned := make([]string, 0)
+			for _, name := range names {
+				if r.Intn(2) == 0 {
+					attribs[name] = true
+					owned = append(owned, name)
+				}
+			}
+			assert.Equal(t, tree.satisfies(attribs), spans(msp, owned, false, p),
+				"%v satisfies %s differently than its MSP", owned, exp)
+		}
+	}
+}
+
+func TestMSP_Reconstruct(t *testing.T) {
+	exp := "a AND ((b AND c) OR 2OF(d, e, f) OR NOT g)"
+	cases := []struct {
+		attribs []string
+		rows    int
+	}{
+		{[]string{"a", "b", "c"}, 3},
+		{[]string{"a", "d", "f"}, 3},
+		{[]string{"a", "!g"}, 2},
+		{[]string{"a", "b", "c", "d", "e", "f", "!g", "h"}, 2},
+		{[]string{"a", "b", "d"}, 0},
+		{[]string{"b", "c", "!g"}, 0},
+		{[]string{"a", "g"}, 0},
+		{[]string{}, 0},
+	}
+	for _, convertToOnes := range []bool{false, true} {
+		msp, err := BooleanToMSP(exp, convertToOnes)
+		if err != nil {
+			t.Fatalf("Error while processing a boolean expression: %v", err)
+		}
+		target := make(data.Vector, len(msp.Mat[0]))
+		for i := range target {
+			target[i] = big.NewInt(0)
+			if i == 0 || convertToOnes {
+				target[i].SetInt64(1)
+			}
+		}
+
+		for _, c := range cases {
+			rows, alpha, err := msp.Reconstruct(c.attribs, convertToOnes)
+			assert.Equal(t, c.rows != 0, msp.Satisfies(c.attribs, convertToOnes), "%v", c.attribs)
+			if c.rows == 0 {
+				assert.Error(t, err)
+				continue
+			}
+			if err != nil {
+				t.Fatalf("Error during reconstruction: %v", err)
+			}
+			assert.Len(t, rows, c.rows, "%v", c.attribs)
+
+			// the rows belong to the attributes and their
+			// combination gives the target vector
+			sum := data.NewConstantVector(len(target), big.NewInt(0))
+			for i, row := range rows {
+				assert.Contains(t, c.attribs, msp.RowToAttrib[row])
+				assert.NotEqual(t, 0, alpha[i].Sign())
+				sum = sum.Add(msp.Mat[row].MulScalar(alpha[i]))
+			}
+			sum = sum.Mod(bn256.Order)
+			for i := range target {
+				assert.Equal(t, 0, target[i].Cmp(sum[i]), "%v", c.attribs)
+			}
+		}
+	}
+
+	_, _, err := (&MSP{Mat: data.Matrix{}, RowToAttrib: []string{}}).Reconstruct([]string{"a"}, false)
+	assert.Error(t, err)
+}
+
+func TestAccessTreeToMSP(t *testing.T) {
+	tree := NewAndNode(NewAttribNode("a"),
+		NewThresholdNode(2, NewAttribNode("b"), NewAttribNode("c"),
+			NewOrNode(NewAttribNode("d"), NewAttribNode(NegatedAttrib("e")))))
+	exp := "a AND 2OF(b, c, d OR NOT e)"
+	assert.Equal(t, exp, tree.String())
+
+	clauses := [][]string{{"a", "b"}, {"c"}, {"d", "e", "f"}}
+	cnf, err := CNFToMSP(clauses, true)
+	if err != nil {
+		t.Fatalf("Error while building a msp from CNF: %v", err)
+	}
+	dnf, err := DNFToMSP(clauses, true)
+	if err != nil {
+		t.Fatalf("Error while building a msp from DNF: %v", err)
+	}
+	jsonTree := []byte(`{"gate": "AND", "children": [{"attrib": "a"},
+		{"gate": "OF", "threshold": 2, "children": [{"attrib": "b"}, {"attrib": "c"},
+			{"gate": "OR", "children": [{"attrib": "d"}, {"attrib": "!e"}]}]}]}`)
+	fromJSON, err := JSONToMSP(jsonTree, false)
+	if err != nil {
+		t.Fatalf("Error while building a msp from JSON: %v", err)
+	}
+
+	tests := []struct {
+		exp string
+		msp *MSP
+	}{
+		{"(a OR b) AND c AND (d OR e OR f)", cnf},
+		{"(a AND b) OR c OR (d AND e AND f)", dnf},
+		{exp, fromJSON},
+	}
+	for _, test := range tests {
+		expected, err := BooleanToMSP(test.exp, false)
+		if err != nil {
+			t.Fatalf("Error while processing a boolean expression: %v", err)
+		}
+		// the same policy results in the same matrix
+		assert.Equal(t, expected.RowToAttrib, test.msp.RowToAttrib)
+		back, err := MSPToAccessTree(test.msp)
+		if err != nil {
+			t.Fatalf("Error while recovering an access tree: %v", err)
+		}
+		assert.Equal(t, test.exp, back.String())
+		msp, err := AccessTreeToMSP(back, false)
+		if err != nil {
+			t.Fatalf("Error while building a msp from an access tree: %v", err)
+		}
+		assert.Equal(t, expected.Mat, msp.Mat)
+	}
+
+	for _, tree := range []*AccessTree{
+		nil,
+		{},
+		{Attrib: "a", Gate: GateAnd, Children: []*AccessTree{NewAttribNode("b")}},
+		{Attrib: "a", Children: []*AccessTree{NewAttribNode("b")}},
+		NewOrNode(),
+		NewThresholdNode(3, NewAttribNode("a"), NewAttribNode("b")),
+		{Gate: GateOr, Threshold: 1, Children: []*AccessTree{NewAttribNode("a")}},
+		{Gate: "XOR", Children: []*AccessTree{NewAttribNode("a")}},
+		NewAndNode(NewAttribNode("a"), nil),
+	} {
+		_, err := AccessTreeToMSP(tree, true)
+		assert.Error(t, err)
+	}
+	for _, clauses := range [][][]string{nil, {{"a"}, {}}} {
+		_, err := CNFToMSP(clauses, true)
+		assert.Error(t, err)
+		_, err = DNFToMSP(clauses, true)
+		assert.Error(t, err)
+	}
+	_, err = JSONToMSP([]byte(`{"gate": "AND", "children": [`), true)
+	assert.Error(t, err)
+}
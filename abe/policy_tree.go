@@ -0,0 +1,234 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Gates of an AccessTree.
+const (
+	GateAnd       = "AND"
+	GateOr        = "OR"
+	GateThreshold = "OF"
+)
+
+// AccessTree is a structured representation of a policy. A leaf of the
+// tree holds an attribute, while an inner node is a gate joining its
+// children: an AND gate is satisfied iff all the children are, an OR
+// gate iff at least one of them is, and a threshold gate OF iff at
+// least Threshold of them are. A negated attribute is given in the form
+// returned by NegatedAttrib. The tree can be encoded in JSON, e.g.
+//
+//	{"gate": "AND", "children": [{"attrib": "a"},
+//		{"gate": "OF", "threshold": 2, "children": [{"attrib": "b"},
+//			{"attrib": "c"}, {"attrib": "d"}]}]}
+type AccessTree struct {
+	Attrib    string        `json:"attrib,omitempty"`
+	Gate      string        `json:"gate,omitempty"`
+	Threshold int           `json:"threshold,omitempty"`
+	Children  []*AccessTree `json:"children,omitempty"`
+}
+
+// NewAttribNode returns a leaf of an AccessTree holding the attribute.
+func NewAttribNode(attrib string) *AccessTree {
+	return &AccessTree{Attrib: attrib}
+}
+
+// NewAndNode returns an AND gate of an AccessTree.
+func NewAndNode(children ...*AccessTree) *AccessTree {
+	return &AccessTree{Gate: GateAnd, Children: children}
+}
+
+// NewOrNode returns an OR gate of an AccessTree.
+func NewOrNode(children ...*AccessTree) *AccessTree {
+	return &AccessTree{Gate: GateOr, Children: children}
+}
+
+// NewThresholdNode returns a gate of an AccessTree that is satisfied
+// iff at least k of the children are satisfied.
+func NewThresholdNode(k int, children ...*AccessTree) *AccessTree {
+	return &AccessTree{Gate: GateThreshold, Threshold: k, Children: children}
+}
+
+// String returns the boolean expression of the tree in the canonical
+// form described at MSPToBoolean. If the tree is not well formed, the
+// error is returned in place of the expression.
+func (t *AccessTree) String() string {
+	node, err := t.toNode()
+	if err != nil {
+		return err.Error()
+	}
+
+	return node.String()
+}
+
+// toNode converts the tree to a policyNode, checking that it is well
+// formed.
+func (t *AccessTree) toNode() (*policyNode, error) {
+	if t == nil {
+		return nil, fmt.Errorf("access tree has an empty node")
+	}
+	if t.Gate == "" {
+		if t.Attrib == "" || len(t.Children) != 0 {
+			return nil, fmt.Errorf("a leaf of the access tree should hold an attribute and no children")
+		}
+		return &policyNode{attrib: t.Attrib}, nil
+	}
+	if t.Attrib != "" {
+		return nil, fmt.Errorf("gate %s of the access tree should not hold an attribute", t.Gate)
+	}
+	if len(t.Children) == 0 {
+		return nil, fmt.Errorf("gate %s of the access tree has no children", t.Gate)
+	}
+
+	var k int
+	switch t.Gate {
+	case GateAnd:
+		k = len(t.Children)
+	case GateOr:
+		k = 1
+	case GateThreshold:
+		k = t.Threshold
+		if k < 1 || k > len(t.Children) {
+			return nil, fmt.Errorf("threshold %d of a gate with %d children", k, len(t.Children))
+		}
+	default:
+		return nil, fmt.Errorf("unknown gate %q of the access tree", t.Gate)
+	}
+	if t.Gate != GateThreshold && t.Threshold != 0 {
+		return nil, fmt.Errorf("gate %s of the access tree should not have a threshold", t.Gate)
+	}
+
+	children := make([]*policyNode, len(t.Children))
+	for i, c := range t.Children {
+		var err error
+		if children[i], err = c.toNode(); err != nil {
+			return nil, err
+		}
+	}
+
+	return newGate(k, children), nil
+}
+
+// fromNode converts a policyNode to an AccessTree.
+func fromNode(n *policyNode) *AccessTree {
+	if n.isAttrib() {
+		return NewAttribNode(n.attrib)
+	}
+
+	children := make([]*AccessTree, len(n.children))
+	for i, c := range n.children {
+		children[i] = fromNode(c)
+	}
+	switch {
+	case n.isAnd():
+		return NewAndNode(children...)
+	case n.isOr():
+		return NewOrNode(children...)
+	default:
+		return NewThresholdNode(n.threshold, children...)
+	}
+}
+
+// AccessTreeToMSP builds a msp structure from the access tree, which
+// has the properties described at BooleanToMSP.
+func AccessTreeToMSP(tree *AccessTree, convertToOnes bool) (*MSP, error) {
+	node, err := tree.toNode()
+	if err != nil {
+		return nil, err
+	}
+
+	return treeToMSP(node, convertToOnes)
+}
+
+// MSPToAccessTree is the inverse of AccessTreeToMSP: it returns the
+// access tree of msp, in which nested AND and OR gates are merged. It
+// returns an error if msp was not built from a policy by
+// AccessTreeToMSP, BooleanToMSP, CNFToMSP, DNFToMSP or JSONToMSP.
+func MSPToAccessTree(msp *MSP) (*AccessTree, error) {
+	if msp == nil || len(msp.Mat) == 0 || len(msp.Mat) != len(msp.RowToAttrib) {
+		return nil, fmt.Errorf("msp should have a row for each attribute")
+	}
+	if len(msp.Mat[0]) == 0 {
+		return nil, fmt.Errorf("msp matrix is not well formed")
+	}
+	node, err := mspMatToTree(msp.Mat, msp.RowToAttrib)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromNode(node), nil
+}
+
+// CNFToMSP builds a msp structure from a policy in the conjunctive
+// normal form, i.e. an AND of the clauses, each of which is an OR of
+// its attributes. For example, [][]string{{"a", "b"}, {"c"}} is the
+// policy (a OR b) AND c.
+func CNFToMSP(clauses [][]string, convertToOnes bool) (*MSP, error) {
+	tree, err := clausesToTree(clauses, NewAndNode, NewOrNode)
+	if err != nil {
+		return nil, err
+	}
+
+	return AccessTreeToMSP(tree, convertToOnes)
+}
+
+// DNFToMSP builds a msp structure from a policy in the disjunctive
+// normal form, i.e. an OR of the clauses, each of which is an AND of
+// its attributes. For example, [][]string{{"a", "b"}, {"c"}} is the
+// policy (a AND b) OR c.
+func DNFToMSP(clauses [][]string, convertToOnes bool) (*MSP, error) {
+	tree, err := clausesToTree(clauses, NewOrNode, NewAndNode)
+	if err != nil {
+		return nil, err
+	}
+
+	return AccessTreeToMSP(tree, convertToOnes)
+}
+
+// clausesToTree returns an outer gate joining the clauses, each of
+// which is an inner gate joining its attributes.
+func clausesToTree(clauses [][]string, outer, inner func(...*AccessTree) *AccessTree) (*AccessTree, error) {
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("no clauses provided")
+	}
+	trees := make([]*AccessTree, len(clauses))
+	for i, clause := range clauses {
+		if len(clause) == 0 {
+			return nil, fmt.Errorf("clause %d is empty", i)
+		}
+		leaves := make([]*AccessTree, len(clause))
+		for j, attrib := range clause {
+			leaves[j] = NewAttribNode(attrib)
+		}
+		trees[i] = inner(leaves...)
+	}
+
+	return outer(trees...), nil
+}
+
+// JSONToMSP builds a msp structure from an access tree encoded in
+// JSON, as described at AccessTree.
+func JSONToMSP(data []byte, convertToOnes bool) (*MSP, error) {
+	var tree AccessTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	return AccessTreeToMSP(&tree, convertToOnes)
+}
@@ -0,0 +1,292 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+)
+
+// The streams produced by EncryptStream start with the length of the
+// binary encoding of a ciphertext of the scheme, encoded as an unsigned
+// varint, followed by the encoding itself. The ciphertext holds the
+// policy or the attributes and the encapsulated element of GT, from
+// which the key for the encryption of the data is derived, and a random
+// nonce prefix in the field Iv. The data follows, split into segments of
+// streamSegmentSize bytes, except for the last one, which is shorter and
+// possibly empty. The segments are encrypted with AES-GCM in the STREAM
+// construction of Hoang, Reyhanitabar, Rogaway and Vizar, see
+// https://eprint.iacr.org/2015/189.pdf: the nonce of a segment is the
+// prefix followed by the index of the segment and a byte indicating
+// whether the segment is the last one, so that segments cannot be
+// reordered and the truncation of the stream is detected. The hash of
+// the encoded ciphertext is bound to all the segments as associated data.
+const (
+	streamSegmentSize = 64 << 10
+	streamPrefixSize  = 7
+	streamTagSize     = 16
+	streamMaxHeader   = 1 << 30
+)
+
+// EncryptStream is like Encrypt, but it encrypts the data read from r
+// until EOF and writes the result to w, using memory that does not
+// depend on the size of the data. The result can be decrypted by
+// DecryptStream.
+func (a *FAME) EncryptStream(w io.Writer, r io.Reader, msp *MSP, pk *FAMEPubKey) error {
+	cipher, keyGt, err := a.encapsulate(msp, pk)
+	if err != nil {
+		return err
+	}
+	cipher.Iv, err = newStreamPrefix()
+	if err != nil {
+		return err
+	}
+	cipher.SymVersion = SymVersionStream
+	header, err := cipher.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return encryptStream(w, r, keyGt, "abe.FAMECipher", header, cipher.Iv)
+}
+
+// DecryptStream decrypts the data encrypted by EncryptStream read
+// from r and writes it to w. The decrypted data is written as soon as
+// it is authenticated, one segment at a time, thus if an error is
+// returned, the data written to w should be discarded.
+func (a *FAME) DecryptStream(w io.Writer, r io.Reader, key *FAMEAttribKeys, pk *FAMEPubKey) error {
+	br := bufio.NewReader(r)
+	header, err := readStreamHeader(br)
+	if err != nil {
+		return err
+	}
+	var cipher FAMECipher
+	if err := cipher.UnmarshalBinary(header); err != nil {
+		return err
+	}
+	keyGt, err := a.decapsulate(&cipher, key)
+	if err != nil {
+		return err
+	}
+
+	return decryptStream(w, br, keyGt, "abe.FAMECipher", header, cipher.SymVersion, cipher.Iv)
+}
+
+// EncryptStream is like Encrypt, but it encrypts the data read from r
+// until EOF and writes the result to w, using memory that does not
+// depend on the size of the data. The result can be decrypted by
+// DecryptStream.
+func (a *GPSW) EncryptStream(w io.Writer, r io.Reader, gamma interface{}, pk *GPSWPubKey) error {
+	cipher, keyGt, err := a.encapsulate(gamma, pk)
+	if err != nil {
+		return err
+	}
+	cipher.Iv, err = newStreamPrefix()
+	if err != nil {
+		return err
+	}
+	cipher.SymVersion = SymVersionStream
+	header, err := cipher.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return encryptStream(w, r, keyGt, "abe.GPSWCipher", header, cipher.Iv)
+}
+
+// DecryptStream decrypts the data encrypted by EncryptStream read
+// from r and writes it to w. The decrypted data is written as soon as
+// it is authenticated, one segment at a time, thus if an error is
+// returned, the data written to w should be discarded.
+func (a *GPSW) DecryptStream(w io.Writer, r io.Reader, key *GPSWKey) error {
+	br := bufio.NewReader(r)
+	header, err := readStreamHeader(br)
+	if err != nil {
+		return err
+	}
+	var cipher GPSWCipher
+	if err := cipher.UnmarshalBinary(header); err != nil {
+		return err
+	}
+	keyGt, err := a.decapsulate(&cipher, key)
+	if err != nil {
+		return err
+	}
+
+	return decryptStream(w, br, keyGt, "abe.GPSWCipher", header, cipher.SymVersion, cipher.Iv)
+}
+
+// EncryptStream is like Encrypt, but it encrypts the data read from r
+// until EOF and writes the result to w, using memory that does not
+// depend on the size of the data. The result can be decrypted by
+// DecryptStream.
+func (d *DIPPE) EncryptStream(w io.Writer, r io.Reader, x data.Vector, pubKeys []*DIPPEPubKey) error {
+	cipher, keyGt, err := d.encapsulate(x, pubKeys)
+	if err != nil {
+		return err
+	}
+	cipher.Iv, err = newStreamPrefix()
+	if err != nil {
+		return err
+	}
+	cipher.SymVersion = SymVersionStream
+	header, err := cipher.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return encryptStream(w, r, keyGt, "abe.DIPPECipher", header, cipher.Iv)
+}
+
+// DecryptStream decrypts the data encrypted by EncryptStream read
+// from r and writes it to w. The decrypted data is written as soon as
+// it is authenticated, one segment at a time, thus if an error is
+// returned, the data written to w should be discarded.
+func (d *DIPPE) DecryptStream(w io.Writer, r io.Reader, keys []data.VectorG2, v data.Vector, gid string) error {
+	br := bufio.NewReader(r)
+	header, err := readStreamHeader(br)
+	if err != nil {
+		return err
+	}
+	var cipher DIPPECipher
+	if err := cipher.UnmarshalBinary(header); err != nil {
+		return err
+	}
+	keyGt, err := d.decapsulate(&cipher, keys, v, gid)
+	if err != nil {
+		return err
+	}
+
+	return decryptStream(w, br, keyGt, "abe.DIPPECipher", header, cipher.SymVersion, cipher.Iv)
+}
+
+// newStreamPrefix returns a random nonce prefix for a stream.
+func newStreamPrefix() ([]byte, error) {
+	prefix := make([]byte, streamPrefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, err
+	}
+
+	return prefix, nil
+}
+
+// streamNonce returns the nonce of the i-th segment of a stream.
+func streamNonce(prefix []byte, i uint32, last bool) []byte {
+	nonce := make([]byte, streamPrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamPrefixSize:], i)
+	if last {
+		nonce[streamPrefixSize+4] = 1
+	}
+
+	return nonce
+}
+
+// encryptStream writes the encoded ciphertext header followed by the
+// data read from r, encrypted under a key derived from keyGt.
+func encryptStream(w io.Writer, r io.Reader, keyGt *bn256.GT, info string, header, prefix []byte) error {
+	aead, err := newGCM(keyGt, info)
+	if err != nil {
+		return err
+	}
+	ad := sha256.Sum256(header)
+
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	if _, err := w.Write(lenBuf[:binary.PutUvarint(lenBuf, uint64(len(header)))]); err != nil {
+		return err
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	buf := make([]byte, streamSegmentSize, streamSegmentSize+streamTagSize)
+	for i := uint32(0); ; i++ {
+		n, err := io.ReadFull(r, buf)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return err
+		}
+		if i == ^uint32(0) && !last {
+			return fmt.Errorf("the data is too large to be encrypted")
+		}
+		seg := aead.Seal(buf[:0], streamNonce(prefix, i, last), buf[:n], ad[:])
+		if _, err := w.Write(seg); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		buf = buf[:streamSegmentSize]
+	}
+}
+
+// readStreamHeader reads the encoded ciphertext at the start of a stream.
+func readStreamHeader(br *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil || n > streamMaxHeader {
+		return nil, fmt.Errorf("malformed stream header")
+	}
+	header := make([]byte, n)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("malformed stream header")
+	}
+
+	return header, nil
+}
+
+// decryptStream decrypts the segments of a stream read from r under a
+// key derived from keyGt and writes the data to w.
+func decryptStream(w io.Writer, r io.Reader, keyGt *bn256.GT, info string, header []byte, version int, prefix []byte) error {
+	if version != SymVersionStream || len(prefix) != streamPrefixSize {
+		return fmt.Errorf("the ciphertext does not start a stream")
+	}
+	aead, err := newGCM(keyGt, info)
+	if err != nil {
+		return err
+	}
+	ad := sha256.Sum256(header)
+
+	// all the segments but the last one have the full size, thus
+	// the last one is recognized by the end of the stream
+	buf := make([]byte, streamSegmentSize+streamTagSize)
+	for i := uint32(0); ; i++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			return fmt.Errorf("the stream is truncated")
+		}
+		last := err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return err
+		}
+		msg, err := aead.Open(buf[:0], streamNonce(prefix, i, last), buf[:n], ad[:])
+		if err != nil {
+			return fmt.Errorf("failed to decrypt")
+		}
+		if _, err := w.Write(msg); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
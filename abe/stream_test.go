@@ -0,0 +1,228 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/abe"
+	"github.com/fentec-project/gofe/data"
+	"github.com/stretchr/testify/assert"
+)
+
+// tamperStreamHeader decodes the header of the stream ct into cipher,
+// modifies it with tamper and returns the stream with the modified
+// header.
+func tamperStreamHeader(t *testing.T, ct []byte, cipher binaryCipher, tamper func()) []byte {
+	n, k := binary.Uvarint(ct)
+	header := tamperHeader(t, ct[k:k+int(n)], cipher, tamper)
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	res := append(lenBuf[:binary.PutUvarint(lenBuf, uint64(len(header)))], header...)
+
+	return append(res, ct[k+int(n):]...)
+}
+
+func TestFAME_Stream(t *testing.T) {
+	a := abe.NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master keys generation: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("a AND (b OR c)", false)
+	if err != nil {
+		t.Fatalf("Error during policy generation: %v", err)
+	}
+	keys, err := a.GenerateAttribKeys([]string{"a", "c"}, secKey)
+	if err != nil {
+		t.Fatalf("Error during keys generation: %v", err)
+	}
+	keysInsuff, err := a.GenerateAttribKeys([]string{"b", "c"}, secKey)
+	if err != nil {
+		t.Fatalf("Error during keys generation: %v", err)
+	}
+
+	for _, size := range []int{0, 100, 64 << 10, 200000} {
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(rand.Reader, msg); err != nil {
+			t.Fatalf("Error during message generation: %v", err)
+		}
+		var enc bytes.Buffer
+		if err := a.EncryptStream(&enc, bytes.NewReader(msg), msp, pubKey); err != nil {
+			t.Fatalf("Error during encryption: %v", err)
+		}
+		ct := enc.Bytes()
+
+		var dec bytes.Buffer
+		if err := a.DecryptStream(&dec, bytes.NewReader(ct), keys, pubKey); err != nil {
+			t.Fatalf("Error during decryption: %v", err)
+		}
+		assert.True(t, bytes.Equal(msg, dec.Bytes()))
+
+		err = a.DecryptStream(ioutil.Discard, bytes.NewReader(ct), keysInsuff, pubKey)
+		assert.Error(t, err)
+
+		// the stream cannot be truncated, not even at a segment boundary
+		for _, cut := range []int{1, 16, 64<<10 + 16} {
+			if cut < len(ct)-100 {
+				err = a.DecryptStream(ioutil.Discard, bytes.NewReader(ct[:len(ct)-cut]), keys, pubKey)
+				assert.Error(t, err)
+			}
+		}
+		tampered := append([]byte{}, ct...)
+		tampered[len(tampered)-1] ^= 1
+		err = a.DecryptStream(ioutil.Discard, bytes.NewReader(tampered), keys, pubKey)
+		assert.Error(t, err)
+	}
+
+	// a malformed header is rejected before the decryption
+	var enc bytes.Buffer
+	if err := a.EncryptStream(&enc, bytes.NewReader([]byte("msg")), msp, pubKey); err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	var cipher abe.FAMECipher
+	for _, tamper := range []func(){
+		func() { cipher.Ct0[1] = nil },
+		func() { cipher.Ct[0][2] = nil },
+		func() { cipher.Ct = cipher.Ct[:1] },
+		func() { cipher.CtPrime = nil },
+		func() { cipher.Msp = nil },
+		func() { cipher.Msp.RowToAttrib = cipher.Msp.RowToAttrib[:1] },
+		func() { cipher.Msp.Mat[1] = cipher.Msp.Mat[1][:1] },
+	} {
+		tampered := tamperStreamHeader(t, enc.Bytes(), &cipher, tamper)
+		err = a.DecryptStream(ioutil.Discard, bytes.NewReader(tampered), keys, pubKey)
+		assert.Error(t, err)
+	}
+}
+
+func TestGPSW_Stream(t *testing.T) {
+	a := abe.NewGPSW(5)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master keys generation: %v", err)
+	}
+	msp, err := abe.BooleanToMSP("0 AND (1 OR 4)", true)
+	if err != nil {
+		t.Fatalf("Error during policy generation: %v", err)
+	}
+	key, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Error during key generation: %v", err)
+	}
+	msg := make([]byte, 200000)
+	if _, err := io.ReadFull(rand.Reader, msg); err != nil {
+		t.Fatalf("Error during message generation: %v", err)
+	}
+	var enc bytes.Buffer
+	if err := a.EncryptStream(&enc, bytes.NewReader(msg), []int{0, 1, 3}, pubKey); err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	ct := enc.Bytes()
+
+	var dec bytes.Buffer
+	if err := a.DecryptStream(&dec, bytes.NewReader(ct), key); err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.True(t, bytes.Equal(msg, dec.Bytes()))
+
+	err = a.DecryptStream(ioutil.Discard, bytes.NewReader(ct[:len(ct)-20]), key)
+	assert.Error(t, err)
+	tampered := append([]byte{}, ct...)
+	tampered[len(tampered)-100] ^= 1
+	err = a.DecryptStream(ioutil.Discard, bytes.NewReader(tampered), key)
+	assert.Error(t, err)
+
+	// a malformed header is rejected before the decryption
+	var cipher abe.GPSWCipher
+	for _, tamper := range []func(){
+		func() { cipher.E0 = nil },
+		func() { cipher.E[0] = nil },
+		func() { cipher.E = cipher.E[:2] },
+		func() { cipher.Gamma = append(cipher.Gamma, 4) },
+		func() { cipher.AttribToI[1] = 5 },
+		func() { delete(cipher.AttribToI, 1) },
+	} {
+		tampered := tamperStreamHeader(t, ct, &cipher, tamper)
+		err = a.DecryptStream(ioutil.Discard, bytes.NewReader(tampered), key)
+		assert.Error(t, err)
+	}
+}
+
+func TestDIPPE_Stream(t *testing.T) {
+	d, err := abe.NewDIPPE(2)
+	if err != nil {
+		t.Fatalf("Error during scheme generation: %v", err)
+	}
+	auth := make([]*abe.DIPPEAuth, 2)
+	pubKeys := make([]*abe.DIPPEPubKey, 2)
+	for i := range auth {
+		auth[i], err = d.NewDIPPEAuth(i)
+		if err != nil {
+			t.Fatalf("Error during authority generation: %v", err)
+		}
+		pubKeys[i] = &auth[i].Pk
+	}
+	policyVec := data.Vector{big.NewInt(1), big.NewInt(-1)}
+	userVec := data.Vector{big.NewInt(1), big.NewInt(1)}
+	keys := make([]data.VectorG2, 2)
+	for i := range auth {
+		keys[i], err = auth[i].DeriveKeyShare(userVec, pubKeys, "gid")
+		if err != nil {
+			t.Fatalf("Error during key generation: %v", err)
+		}
+	}
+	msg := make([]byte, 200000)
+	if _, err := io.ReadFull(rand.Reader, msg); err != nil {
+		t.Fatalf("Error during message generation: %v", err)
+	}
+	var enc bytes.Buffer
+	if err := d.EncryptStream(&enc, bytes.NewReader(msg), policyVec, pubKeys); err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	ct := enc.Bytes()
+
+	var dec bytes.Buffer
+	if err := d.DecryptStream(&dec, bytes.NewReader(ct), keys, userVec, "gid"); err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.True(t, bytes.Equal(msg, dec.Bytes()))
+
+	err = d.DecryptStream(ioutil.Discard, bytes.NewReader(ct), keys, userVec, "other")
+	assert.Error(t, err)
+	err = d.DecryptStream(ioutil.Discard, bytes.NewReader(ct[:len(ct)-20]), keys, userVec, "gid")
+	assert.Error(t, err)
+
+	// a malformed header is rejected before the decryption
+	var cipher abe.DIPPECipher
+	for _, tamper := range []func(){
+		func() { cipher.C0[0] = nil },
+		func() { cipher.C0 = cipher.C0[:1] },
+		func() { cipher.C[1] = cipher.C[1][:1] },
+		func() { cipher.C = cipher.C[:1] },
+		func() { cipher.CPrime = nil },
+		func() { cipher.X = append(cipher.X, big.NewInt(0)) },
+	} {
+		tampered := tamperStreamHeader(t, ct, &cipher, tamper)
+		err = d.DecryptStream(ioutil.Discard, bytes.NewReader(tampered), keys, userVec, "gid")
+		assert.Error(t, err)
+	}
+}
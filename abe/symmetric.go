@@ -0,0 +1,249 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io"
+
+	"github.com/fentec-project/bn256"
+	"golang.org/x/crypto/hkdf"
+)
+
+// The ciphertexts of the ABE schemes encapsulate a random element of
+// GT, from which a key for the symmetric encryption of the message is
+// derived. The field SymVersion of a ciphertext identifies how the
+// message was encrypted.
+const (
+	// SymVersionCBC denotes the legacy encryption with AES-CBC and
+	// PKCS7 padding under the key sha256(keyGt.String()), without
+	// authentication. Such ciphertexts can still be decrypted, but
+	// are no longer produced.
+	SymVersionCBC = 0
+	// SymVersionGCM denotes the encryption with AES-GCM under a key
+	// derived by HKDF-SHA256 from the canonical bytes of the element
+	// of GT, where the rest of the ciphertext, i.e. the policy or the
+	// attributes and the group elements, is bound as associated data.
+	// The encryption is preceded by a commitment to the element of GT,
+	// so that it decrypts only under the element it was created with.
+	SymVersionGCM = 1
+	// SymVersionStream denotes the encryption of data of arbitrary
+	// size by EncryptStream, where the ciphertext is followed by the
+	// data encrypted in segments with AES-GCM. Such ciphertexts can
+	// only be decrypted by DecryptStream.
+	SymVersionStream = 2
+	// SymVersionKEM denotes a ciphertext without a message, returned
+	// by Encapsulate, from which Decapsulate derives a key for the
+	// symmetric encryption chosen by the caller.
+	SymVersionKEM = 3
+	// SymVersionCCA denotes the encryption with AES-GCM as in
+	// SymVersionGCM by EncryptCCA, where the randomness of the
+	// encapsulation is derived from the message. Such ciphertexts can
+	// only be decrypted by DecryptCCA.
+	SymVersionCCA = 4
+)
+
+// symKey derives a key for AES-256 from the element keyGt of GT by
+// HKDF-SHA256, where info identifies the type of the ciphertext.
+func symKey(keyGt *bn256.GT, info string) ([]byte, error) {
+	key := make([]byte, 32)
+	kdf := hkdf.New(sha256.New, keyGt.Marshal(), nil, []byte("gofe "+info))
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// newGCM returns AES-GCM with a key derived from keyGt.
+func newGCM(keyGt *bn256.GT, info string) (cipher.AEAD, error) {
+	key, err := symKey(keyGt, info)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// commitSize is the size of the key commitment that precedes the
+// messages encrypted with AES-GCM.
+const commitSize = 32
+
+// keyCommitment returns a commitment to the element keyGt of GT,
+// derived by HKDF-SHA256 independently of the key of the encryption.
+// AES-GCM is not key-committing, i.e. a ciphertext can be crafted to
+// decrypt under different keys, so the commitment is checked to ensure
+// that the element of GT recovered in the decryption, possibly with
+// the help of an untrusted party, is the encapsulated one.
+func keyCommitment(keyGt *bn256.GT, info string) ([]byte, error) {
+	return symKey(keyGt, info+" commitment")
+}
+
+// newNonce returns a random nonce for AES-GCM.
+func newNonce() ([]byte, error) {
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return nonce, nil
+}
+
+// sealSym encrypts msg with AES-GCM under a key derived from keyGt
+// and the nonce iv, binding header as associated data. The encryption
+// is preceded by the commitment to keyGt.
+func sealSym(keyGt *bn256.GT, info string, iv, msg, header []byte) ([]byte, error) {
+	commitment, err := keyCommitment(keyGt, info)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(keyGt, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(commitment, iv, msg, header), nil
+}
+
+// openSym decrypts symEnc that was encrypted as given by version under
+// a key derived from keyGt and the initialization vector or nonce iv.
+// For SymVersionGCM the function header returning the associated data
+// is called, and an error is returned if the ciphertext was modified
+// or keyGt does not match the commitment.
+func openSym(version int, keyGt *bn256.GT, info string, iv, symEnc []byte, header func() ([]byte, error)) ([]byte, error) {
+	switch version {
+	case SymVersionCBC:
+		return openCBC(keyGt, iv, symEnc)
+	case SymVersionGCM:
+		ad, err := header()
+		if err != nil {
+			return nil, err
+		}
+		commitment, err := keyCommitment(keyGt, info)
+		if err != nil {
+			return nil, err
+		}
+		if len(symEnc) < commitSize ||
+			subtle.ConstantTimeCompare(symEnc[:commitSize], commitment) != 1 {
+			return nil, fmt.Errorf("failed to decrypt")
+		}
+		aead, err := newGCM(keyGt, info)
+		if err != nil {
+			return nil, err
+		}
+		if len(iv) != aead.NonceSize() {
+			return nil, fmt.Errorf("failed to decrypt")
+		}
+		msg, err := aead.Open(nil, iv, symEnc[commitSize:], ad)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt")
+		}
+		return msg, nil
+	default:
+		return nil, fmt.Errorf("unsupported symmetric encryption version %d", version)
+	}
+}
+
+// openCBC decrypts symEnc that was encrypted with the legacy AES-CBC.
+func openCBC(keyGt *bn256.GT, iv, symEnc []byte) ([]byte, error) {
+	keyCBC := sha256.Sum256([]byte(keyGt.String()))
+	c, err := aes.NewCipher(keyCBC[:])
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != c.BlockSize() || len(symEnc) == 0 || len(symEnc)%c.BlockSize() != 0 {
+		return nil, fmt.Errorf("failed to decrypt")
+	}
+
+	msgPad := make([]byte, len(symEnc))
+	decrypter := cipher.NewCBCDecrypter(c, iv)
+	decrypter.CryptBlocks(msgPad, symEnc)
+
+	// unpad the message, checking all the bytes of the padding
+	padLen := int(msgPad[len(msgPad)-1])
+	if padLen == 0 || padLen > c.BlockSize() {
+		return nil, fmt.Errorf("failed to decrypt")
+	}
+	for _, b := range msgPad[len(msgPad)-padLen:] {
+		if int(b) != padLen {
+			return nil, fmt.Errorf("failed to decrypt")
+		}
+	}
+
+	return msgPad[0:(len(msgPad) - padLen)], nil
+}
+
+// header returns the canonical encoding of the ciphertext without the
+// symmetric encryption of the message, which is bound to it as the
+// associated data.
+func (c *FAMECipher) header() ([]byte, error) {
+	h := *c
+	h.SymEnc = nil
+	return h.MarshalBinary()
+}
+
+// header returns the canonical encoding of the ciphertext without the
+// symmetric encryption of the message.
+func (c *GPSWCipher) header() ([]byte, error) {
+	h := *c
+	h.SymEnc = nil
+	return h.MarshalBinary()
+}
+
+// header returns the canonical encoding of the ciphertext without the
+// symmetric encryption of the message.
+func (c *DIPPECipher) header() ([]byte, error) {
+	h := *c
+	h.SymEnc = nil
+	return h.MarshalBinary()
+}
+
+// header returns the canonical encoding of the ciphertext without the
+// symmetric encryption of the message.
+func (c *GPSWLUCipher) header() ([]byte, error) {
+	h := *c
+	h.SymEnc = nil
+	return h.MarshalBinary()
+}
+
+// header returns the canonical encoding of the ciphertext without the
+// symmetric encryption of the message.
+func (c *MAABECipher) header() ([]byte, error) {
+	h := *c
+	h.SymEnc = nil
+	return h.MarshalBinary()
+}
+
+// header returns the canonical encoding of the ciphertext without the
+// symmetric encryption of the message.
+func (c *RevocableFAMECipher) header() ([]byte, error) {
+	h := *c
+	if c.Cipher != nil {
+		fameCipher := *c.Cipher
+		fameCipher.SymEnc = nil
+		h.Cipher = &fameCipher
+	}
+	return h.MarshalBinary()
+}
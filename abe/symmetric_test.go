@@ -0,0 +1,297 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package abe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"github.com/stretchr/testify/assert"
+)
+
+// legacyCBC encrypts msg as the ciphertexts of SymVersionCBC were.
+func legacyCBC(t *testing.T, keyGt *bn256.GT, msg string) ([]byte, []byte) {
+	keyCBC := sha256.Sum256([]byte(keyGt.String()))
+	c, err := aes.NewCipher(keyCBC[:])
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	iv := make([]byte, c.BlockSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+
+	// message is padded according to pkcs7 standard
+	padLen := c.BlockSize() - (len(msg) % c.BlockSize())
+	msgPad := make([]byte, len(msg)+padLen)
+	copy(msgPad, msg)
+	for i := len(msg); i < len(msgPad); i++ {
+		msgPad[i] = byte(padLen)
+	}
+	symEnc := make([]byte, len(msgPad))
+	cipher.NewCBCEncrypter(c, iv).CryptBlocks(symEnc, msgPad)
+
+	return symEnc, iv
+}
+
+func TestOpenCBC_Padding(t *testing.T) {
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error during key generation: %v", err)
+	}
+	keyCBC := sha256.Sum256([]byte(keyGt.String()))
+	c, err := aes.NewCipher(keyCBC[:])
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	symEnc, iv := legacyCBC(t, keyGt, "msg")
+	msg, err := openCBC(keyGt, iv, symEnc)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, "msg", string(msg))
+
+	// only a valid PKCS#7 padding is accepted
+	encrypt := func(msgPad []byte) []byte {
+		symEnc := make([]byte, len(msgPad))
+		cipher.NewCBCEncrypter(c, iv).CryptBlocks(symEnc, msgPad)
+		return symEnc
+	}
+	for _, last := range [][]byte{
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 0},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 3, 2},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 4, 4, 4},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 17},
+		{16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 16, 32},
+	} {
+		msgPad := append(make([]byte, 16), last...)
+		for i := range msgPad[:16] {
+			msgPad[i] = 16
+		}
+		_, err = openCBC(keyGt, iv, encrypt(msgPad))
+		assert.EqualError(t, err, "failed to decrypt")
+	}
+}
+
+func TestOpenSym_Commitment(t *testing.T) {
+	_, keyGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error during key generation: %v", err)
+	}
+	_, otherGt, err := bn256.RandomGT(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error during key generation: %v", err)
+	}
+	iv, err := newNonce()
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	header := func() ([]byte, error) { return []byte("header"), nil }
+	symEnc, err := sealSym(keyGt, "test", iv, []byte("msg"), []byte("header"))
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	msg, err := openSym(SymVersionGCM, keyGt, "test", iv, symEnc, header)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, "msg", string(msg))
+
+	// a ciphertext decrypts only under the committed element of GT,
+	// even if the encryption itself is valid under another one
+	forged, err := sealSym(otherGt, "test", iv, []byte("msg"), []byte("header"))
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	copy(forged, symEnc[:commitSize])
+	_, err = openSym(SymVersionGCM, otherGt, "test", iv, forged, header)
+	assert.EqualError(t, err, "failed to decrypt")
+	_, err = openSym(SymVersionGCM, keyGt, "test", iv, symEnc[:commitSize-1], header)
+	assert.EqualError(t, err, "failed to decrypt")
+}
+
+func TestFAME_SymVersion(t *testing.T) {
+	a := NewFAME()
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master keys generation: %v", err)
+	}
+	msp, err := BooleanToMSP("a OR b", false)
+	if err != nil {
+		t.Fatalf("Error during policy generation: %v", err)
+	}
+	keys, err := a.GenerateAttribKeys([]string{"a"}, secKey)
+	if err != nil {
+		t.Fatalf("Error during keys generation: %v", err)
+	}
+	msg := "Attack at dawn!"
+	ct, err := a.Encrypt(msg, msp, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	assert.Equal(t, SymVersionGCM, ct.SymVersion)
+
+	// the policy is bound to the ciphertext, even the rows
+	// that are not needed for the decryption
+	tampered := *ct
+	tampered.Msp = &MSP{Mat: msp.Mat, RowToAttrib: []string{"a", "c"}}
+	_, err = a.Decrypt(&tampered, keys, pubKey)
+	assert.Error(t, err)
+	tampered = *ct
+	tampered.SymEnc = append([]byte{}, ct.SymEnc...)
+	tampered.SymEnc[0] ^= 1
+	_, err = a.Decrypt(&tampered, keys, pubKey)
+	assert.Error(t, err)
+
+	// a legacy ciphertext with the message encrypted by AES-CBC,
+	// which keeps its version through the encodings
+	keyGt, err := a.decapsulate(ct, keys)
+	if err != nil {
+		t.Fatalf("Error during decapsulation: %v", err)
+	}
+	legacy := *ct
+	legacy.SymEnc, legacy.Iv = legacyCBC(t, keyGt, msg)
+	legacy.SymVersion = SymVersionCBC
+	b, err := legacy.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var legacyDec FAMECipher
+	if err := legacyDec.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	dec, err := a.Decrypt(&legacyDec, keys, pubKey)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, msg, dec)
+
+	b, err = json.Marshal(&legacy)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	assert.NotContains(t, string(b), "symVersion")
+	legacyDec = FAMECipher{}
+	if err := json.Unmarshal(b, &legacyDec); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	dec, err = a.Decrypt(&legacyDec, keys, pubKey)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, msg, dec)
+}
+
+func TestGPSW_SymVersion(t *testing.T) {
+	a := NewGPSW(5)
+	pubKey, secKey, err := a.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master keys generation: %v", err)
+	}
+	msp, err := BooleanToMSP("0 AND 1", true)
+	if err != nil {
+		t.Fatalf("Error during policy generation: %v", err)
+	}
+	key, err := a.GeneratePolicyKey(msp, secKey)
+	if err != nil {
+		t.Fatalf("Error during key generation: %v", err)
+	}
+	msg := "Attack at dawn!"
+	ct, err := a.Encrypt(msg, []int{0, 1, 3}, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+
+	// the attributes are bound to the ciphertext
+	tampered := *ct
+	tampered.Gamma = []int{0, 1, 3, 4}
+	_, err = a.Decrypt(&tampered, key)
+	assert.Error(t, err)
+
+	keyGt, err := a.decapsulate(ct, key)
+	if err != nil {
+		t.Fatalf("Error during decapsulation: %v", err)
+	}
+	legacy := *ct
+	legacy.SymEnc, legacy.Iv = legacyCBC(t, keyGt, msg)
+	legacy.SymVersion = SymVersionCBC
+	dec, err := a.Decrypt(&legacy, key)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, msg, dec)
+}
+
+func TestDIPPE_SymVersion(t *testing.T) {
+	d, err := NewDIPPE(2)
+	if err != nil {
+		t.Fatalf("Error during scheme generation: %v", err)
+	}
+	auth := make([]*DIPPEAuth, 2)
+	pubKeys := make([]*DIPPEPubKey, 2)
+	for i := range auth {
+		auth[i], err = d.NewDIPPEAuth(i)
+		if err != nil {
+			t.Fatalf("Error during authority generation: %v", err)
+		}
+		pubKeys[i] = &auth[i].Pk
+	}
+	policyVec := data.Vector{big.NewInt(1), big.NewInt(-1)}
+	userVec := data.Vector{big.NewInt(1), big.NewInt(1)}
+	keys := make([]data.VectorG2, 2)
+	for i := range auth {
+		keys[i], err = auth[i].DeriveKeyShare(userVec, pubKeys, "gid")
+		if err != nil {
+			t.Fatalf("Error during key generation: %v", err)
+		}
+	}
+	msg := "Attack at dawn!"
+	ct, err := d.Encrypt(msg, policyVec, pubKeys)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+
+	tampered := *ct
+	tampered.Iv = append([]byte{}, ct.Iv...)
+	tampered.Iv[0] ^= 1
+	_, err = d.Decrypt(&tampered, keys, userVec, "gid")
+	assert.Error(t, err)
+
+	keyGt, err := d.decapsulate(ct, keys, userVec, "gid")
+	if err != nil {
+		t.Fatalf("Error during decapsulation: %v", err)
+	}
+	legacy := *ct
+	legacy.SymEnc, legacy.Iv = legacyCBC(t, keyGt, msg)
+	legacy.SymVersion = SymVersionCBC
+	dec, err := d.Decrypt(&legacy, keys, userVec, "gid")
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, msg, dec)
+
+	legacy.SymVersion = 7
+	_, err = d.Decrypt(&legacy, keys, userVec, "gid")
+	assert.Error(t, err)
+}
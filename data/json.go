@@ -0,0 +1,326 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+type vectorJSON struct {
+	serial.Header
+	Value []*serial.BigInt `json:"value"`
+}
+
+type matrixJSON struct {
+	serial.Header
+	Value [][]*serial.BigInt `json:"value"`
+}
+
+type vectorG1JSON struct {
+	serial.Header
+	Value []*serial.G1 `json:"value"`
+}
+
+type vectorG2JSON struct {
+	serial.Header
+	Value []*serial.G2 `json:"value"`
+}
+
+type vectorGTJSON struct {
+	serial.Header
+	Value []*serial.GT `json:"value"`
+}
+
+type matrixG1JSON struct {
+	serial.Header
+	Value [][]*serial.G1 `json:"value"`
+}
+
+type matrixG2JSON struct {
+	serial.Header
+	Value [][]*serial.G2 `json:"value"`
+}
+
+func toJSONInts(v []*big.Int) []*serial.BigInt {
+	res := make([]*serial.BigInt, len(v))
+	for i, x := range v {
+		res[i] = (*serial.BigInt)(x)
+	}
+
+	return res
+}
+
+func fromJSONInts(v []*serial.BigInt) Vector {
+	res := make(Vector, len(v))
+	for i, x := range v {
+		res[i] = (*big.Int)(x)
+	}
+
+	return res
+}
+
+// MarshalJSON encodes vector v as a JSON object. A nil vector
+// is encoded as null.
+func (v Vector) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(vectorJSON{serial.NewHeader("data.Vector"), toJSONInts(v)})
+}
+
+// UnmarshalJSON decodes a vector encoded with MarshalJSON into v.
+func (v *Vector) UnmarshalJSON(b []byte) error {
+	var enc vectorJSON
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("data.Vector"); err != nil {
+		return err
+	}
+	*v = fromJSONInts(enc.Value)
+
+	return nil
+}
+
+// MarshalJSON encodes matrix m as a JSON object. A nil matrix
+// is encoded as null.
+func (m Matrix) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	rows := make([][]*serial.BigInt, len(m))
+	for i, row := range m {
+		rows[i] = toJSONInts(row)
+	}
+
+	return json.Marshal(matrixJSON{serial.NewHeader("data.Matrix"), rows})
+}
+
+// UnmarshalJSON decodes a matrix encoded with MarshalJSON into m.
+func (m *Matrix) UnmarshalJSON(b []byte) error {
+	var enc matrixJSON
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("data.Matrix"); err != nil {
+		return err
+	}
+	mat := make(Matrix, len(enc.Value))
+	for i, row := range enc.Value {
+		mat[i] = fromJSONInts(row)
+	}
+	*m = mat
+
+	return nil
+}
+
+// MarshalJSON encodes vector v as a JSON object. A nil vector
+// is encoded as null.
+func (v VectorG1) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	enc := vectorG1JSON{serial.NewHeader("data.VectorG1"), make([]*serial.G1, len(v))}
+	for i, p := range v {
+		enc.Value[i] = (*serial.G1)(p)
+	}
+
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes a vector encoded with MarshalJSON into v.
+func (v *VectorG1) UnmarshalJSON(b []byte) error {
+	var enc vectorG1JSON
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("data.VectorG1"); err != nil {
+		return err
+	}
+	vec := make(VectorG1, len(enc.Value))
+	for i, p := range enc.Value {
+		vec[i] = (*bn256.G1)(p)
+	}
+	*v = vec
+
+	return nil
+}
+
+// MarshalJSON encodes vector v as a JSON object. A nil vector
+// is encoded as null.
+func (v VectorG2) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	enc := vectorG2JSON{serial.NewHeader("data.VectorG2"), make([]*serial.G2, len(v))}
+	for i, p := range v {
+		enc.Value[i] = (*serial.G2)(p)
+	}
+
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes a vector encoded with MarshalJSON into v.
+func (v *VectorG2) UnmarshalJSON(b []byte) error {
+	var enc vectorG2JSON
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("data.VectorG2"); err != nil {
+		return err
+	}
+	vec := make(VectorG2, len(enc.Value))
+	for i, p := range enc.Value {
+		vec[i] = (*bn256.G2)(p)
+	}
+	*v = vec
+
+	return nil
+}
+
+// MarshalJSON encodes vector v as a JSON object. A nil vector
+// is encoded as null.
+func (v VectorGT) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	enc := vectorGTJSON{serial.NewHeader("data.VectorGT"), make([]*serial.GT, len(v))}
+	for i, p := range v {
+		enc.Value[i] = (*serial.GT)(p)
+	}
+
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes a vector encoded with MarshalJSON into v.
+func (v *VectorGT) UnmarshalJSON(b []byte) error {
+	var enc vectorGTJSON
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("data.VectorGT"); err != nil {
+		return err
+	}
+	vec := make(VectorGT, len(enc.Value))
+	for i, p := range enc.Value {
+		vec[i] = (*bn256.GT)(p)
+	}
+	*v = vec
+
+	return nil
+}
+
+// MarshalJSON encodes matrix m as a JSON object. A nil matrix
+// is encoded as null.
+func (m MatrixG1) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	enc := matrixG1JSON{serial.NewHeader("data.MatrixG1"), make([][]*serial.G1, len(m))}
+	for i, row := range m {
+		enc.Value[i] = make([]*serial.G1, len(row))
+		for j, p := range row {
+			enc.Value[i][j] = (*serial.G1)(p)
+		}
+	}
+
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes a matrix encoded with MarshalJSON into m.
+func (m *MatrixG1) UnmarshalJSON(b []byte) error {
+	var enc matrixG1JSON
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("data.MatrixG1"); err != nil {
+		return err
+	}
+	mat := make(MatrixG1, len(enc.Value))
+	for i, row := range enc.Value {
+		mat[i] = make(VectorG1, len(row))
+		for j, p := range row {
+			mat[i][j] = (*bn256.G1)(p)
+		}
+	}
+	*m = mat
+
+	return nil
+}
+
+// MarshalJSON encodes matrix m as a JSON object. A nil matrix
+// is encoded as null.
+func (m MatrixG2) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	enc := matrixG2JSON{serial.NewHeader("data.MatrixG2"), make([][]*serial.G2, len(m))}
+	for i, row := range m {
+		enc.Value[i] = make([]*serial.G2, len(row))
+		for j, p := range row {
+			enc.Value[i][j] = (*serial.G2)(p)
+		}
+	}
+
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON decodes a matrix encoded with MarshalJSON into m.
+func (m *MatrixG2) UnmarshalJSON(b []byte) error {
+	var enc matrixG2JSON
+	if string(b) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("data.MatrixG2"); err != nil {
+		return err
+	}
+	mat := make(MatrixG2, len(enc.Value))
+	for i, row := range enc.Value {
+		mat[i] = make(VectorG2, len(row))
+		for j, p := range row {
+			mat[i][j] = (*bn256.G2)(p)
+		}
+	}
+	*m = mat
+
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	sampler := sample.NewUniformRange(big.NewInt(-1000), big.NewInt(1000))
+	v, err := NewRandomVector(4, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	m, err := NewRandomMatrix(2, 3, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+
+	vJSON, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var vDec Vector
+	if err := json.Unmarshal(vJSON, &vDec); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	// big integers are compared by value, as zero might be represented
+	// differently after decoding
+	assert.Equal(t, v.String(), vDec.String())
+
+	mJSON, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var mDec Matrix
+	if err := json.Unmarshal(mJSON, &mDec); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	assert.True(t, mDec.CheckDims(m.Rows(), m.Cols()))
+	assert.Equal(t, m.ToVec().String(), mDec.ToVec().String())
+
+	// the type tag is checked when decoding
+	assert.Error(t, json.Unmarshal(vJSON, &mDec))
+	assert.Error(t, json.Unmarshal([]byte(`{"type":"data.Vector","version":2,"value":[]}`), &vDec))
+	assert.NoError(t, json.Unmarshal([]byte(`{"type":"data.Vector","version":1,"value":["0x10","-7"]}`), &vDec))
+	assert.Equal(t, Vector{big.NewInt(16), big.NewInt(-7)}, vDec)
+
+	m = m.Mod(big.NewInt(1000))
+	g1JSON, err := json.Marshal(m.MulG1())
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var g1Dec MatrixG1
+	if err := json.Unmarshal(g1JSON, &g1Dec); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	checkJSON, err := json.Marshal(g1Dec)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	assert.Equal(t, g1JSON, checkJSON)
+
+	g2JSON, err := json.Marshal(v.Mod(big.NewInt(1000)).MulG2())
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var g2Dec VectorG2
+	if err := json.Unmarshal(g2JSON, &g2Dec); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	checkJSON, err = json.Marshal(g2Dec)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	assert.Equal(t, g2JSON, checkJSON)
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package data
+
+import (
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+// MarshalBinary encodes vector v into a canonical binary form.
+func (v Vector) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("data.Vector")
+	e.BigInts(v)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a vector encoded with MarshalBinary into v.
+func (v *Vector) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "data.Vector")
+	vec := d.BigInts()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*v = vec
+
+	return nil
+}
+
+// MarshalBinary encodes matrix m into a canonical binary form.
+func (m Matrix) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("data.Matrix")
+	e.Len(len(m))
+	for _, row := range m {
+		e.BigInts(row)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a matrix encoded with MarshalBinary into m.
+func (m *Matrix) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "data.Matrix")
+	rows := d.Len()
+	mat := make(Matrix, 0)
+	for i := 0; i < rows && !d.Failed(); i++ {
+		mat = append(mat, d.BigInts())
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*m = mat
+
+	return nil
+}
+
+// MarshalBinary encodes vector v into a canonical binary form.
+func (v VectorG1) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("data.VectorG1")
+	e.Len(len(v))
+	for _, p := range v {
+		e.G1(p)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a vector encoded with MarshalBinary into v.
+func (v *VectorG1) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "data.VectorG1")
+	n := d.Len()
+	vec := make(VectorG1, 0)
+	for i := 0; i < n && !d.Failed(); i++ {
+		vec = append(vec, d.G1())
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*v = vec
+
+	return nil
+}
+
+// MarshalBinary encodes vector v into a canonical binary form.
+func (v VectorG2) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("data.VectorG2")
+	e.Len(len(v))
+	for _, p := range v {
+		e.G2(p)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a vector encoded with MarshalBinary into v.
+func (v *VectorG2) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "data.VectorG2")
+	n := d.Len()
+	vec := make(VectorG2, 0)
+	for i := 0; i < n && !d.Failed(); i++ {
+		vec = append(vec, d.G2())
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*v = vec
+
+	return nil
+}
+
+// MarshalBinary encodes vector v into a canonical binary form.
+func (v VectorGT) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("data.VectorGT")
+	e.Len(len(v))
+	for _, p := range v {
+		e.GT(p)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a vector encoded with MarshalBinary into v.
+func (v *VectorGT) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "data.VectorGT")
+	n := d.Len()
+	vec := make(VectorGT, 0)
+	for i := 0; i < n && !d.Failed(); i++ {
+		vec = append(vec, d.GT())
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*v = vec
+
+	return nil
+}
+
+// MarshalBinary encodes matrix m into a canonical binary form.
+func (m MatrixG1) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("data.MatrixG1")
+	e.Len(len(m))
+	for _, row := range m {
+		e.Len(len(row))
+		for _, p := range row {
+			e.G1(p)
+		}
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a matrix encoded with MarshalBinary into m.
+func (m *MatrixG1) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "data.MatrixG1")
+	rows := d.Len()
+	mat := make(MatrixG1, 0)
+	for i := 0; i < rows && !d.Failed(); i++ {
+		cols := d.Len()
+		row := make(VectorG1, 0)
+		for j := 0; j < cols && !d.Failed(); j++ {
+			row = append(row, d.G1())
+		}
+		mat = append(mat, row)
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*m = mat
+
+	return nil
+}
+
+// MarshalBinary encodes matrix m into a canonical binary form.
+func (m MatrixG2) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("data.MatrixG2")
+	e.Len(len(m))
+	for _, row := range m {
+		e.Len(len(row))
+		for _, p := range row {
+			e.G2(p)
+		}
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a matrix encoded with MarshalBinary into m.
+func (m *MatrixG2) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "data.MatrixG2")
+	rows := d.Len()
+	mat := make(MatrixG2, 0)
+	for i := 0; i < rows && !d.Failed(); i++ {
+		cols := d.Len()
+		row := make(VectorG2, 0)
+		for j := 0; j < cols && !d.Failed(); j++ {
+			row = append(row, d.G2())
+		}
+		mat = append(mat, row)
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*m = mat
+
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package data
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	sampler := sample.NewUniformRange(big.NewInt(-1000), big.NewInt(1000))
+	v, err := NewRandomVector(5, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	m, err := NewRandomMatrix(3, 4, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+
+	vBytes, err := v.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	var vDec Vector
+	if err := vDec.UnmarshalBinary(vBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	// big integers are compared by value, as zero might be represented
+	// differently after decoding
+	assert.Equal(t, v.String(), vDec.String())
+
+	mBytes, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	var mDec Matrix
+	if err := mDec.UnmarshalBinary(mBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	assert.True(t, mDec.CheckDims(m.Rows(), m.Cols()))
+	assert.Equal(t, m.ToVec().String(), mDec.ToVec().String())
+
+	// a vector cannot be decoded as a matrix
+	assert.Error(t, mDec.UnmarshalBinary(vBytes))
+
+	// group elements are compared through their canonical encoding
+	m = m.Mod(big.NewInt(1000))
+	g1Bytes, err := m.MulG1().MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	var g1Dec MatrixG1
+	if err := g1Dec.UnmarshalBinary(g1Bytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	checkBytes, err := g1Dec.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	assert.Equal(t, g1Bytes, checkBytes)
+
+	g2Bytes, err := m.MulG2().MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	var g2Dec MatrixG2
+	if err := g2Dec.UnmarshalBinary(g2Bytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	checkBytes, err = g2Dec.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	assert.Equal(t, g2Bytes, checkBytes)
+}
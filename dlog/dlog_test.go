@@ -0,0 +1,142 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dlog_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/dlog"
+	"github.com/fentec-project/gofe/internal/keygen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolvers_Zp(t *testing.T) {
+	key, err := keygen.NewElGamal(32)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	table, err := dlog.NewTableZp(key.P, key.G, 12)
+	if err != nil {
+		t.Fatalf("Error during table generation: %v", err)
+	}
+	defer table.Close()
+
+	solvers := map[string]dlog.Solver{
+		"bsgs":         dlog.BabyStepGiantStep{},
+		"bsgs-table":   dlog.BabyStepGiantStep{Table: table},
+		"kangaroo":     dlog.Kangaroo{},
+		"rho":          dlog.PollardRho{},
+		"rho-parallel": dlog.PollardRho{Parallel: true},
+		"table":        dlog.TableLookup{Table: table},
+		"auto":         dlog.Auto{},
+		"auto-table":   dlog.Auto{Table: table},
+	}
+	for name, s := range solvers {
+		for _, xCheck := range []*big.Int{big.NewInt(0), big.NewInt(3001), big.NewInt(-4000)} {
+			h := new(big.Int).Exp(key.G, new(big.Int).Mod(xCheck, key.Q), key.P)
+			pr := &dlog.ZpProblem{H: h, G: key.G, P: key.P, Order: key.Q, Bound: big.NewInt(4000), Neg: true}
+			x, err := s.SolveZp(context.Background(), pr)
+			if err != nil {
+				t.Fatalf("Error in solver %s: %v", name, err)
+			}
+			assert.Equal(t, xCheck.Cmp(x), 0, "solver %s result is wrong", name)
+		}
+	}
+}
+
+func TestSolvers_GT(t *testing.T) {
+	table, err := dlog.NewTableGT(10)
+	if err != nil {
+		t.Fatalf("Error during table generation: %v", err)
+	}
+	defer table.Close()
+	g := new(bn256.GT).ScalarBaseMult(big.NewInt(1))
+
+	solvers := map[string]dlog.Solver{
+		"bsgs":     dlog.BabyStepGiantStep{},
+		"kangaroo": dlog.Kangaroo{},
+		"table":    dlog.TableLookup{Table: table},
+		"auto":     dlog.Auto{Table: table},
+	}
+	for name, s := range solvers {
+		for _, xCheck := range []*big.Int{big.NewInt(700), big.NewInt(-1000)} {
+			h := new(bn256.GT).ScalarMult(g, new(big.Int).Mod(xCheck, bn256.Order))
+			pr := &dlog.GTProblem{H: h, G: g, Bound: big.NewInt(1000), Neg: true}
+			x, err := s.SolveGT(context.Background(), pr)
+			if err != nil {
+				t.Fatalf("Error in solver %s: %v", name, err)
+			}
+			assert.Equal(t, xCheck.Cmp(x), 0, "solver %s result is wrong", name)
+		}
+	}
+}
+
+func TestSolvers_Unsupported(t *testing.T) {
+	key, err := keygen.NewElGamal(32)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	g := new(bn256.GT).ScalarBaseMult(big.NewInt(1))
+	zp := &dlog.ZpProblem{H: key.G, G: key.G, P: key.P, Order: key.Q}
+	gt := &dlog.GTProblem{H: g, G: g}
+
+	_, err = dlog.PollardRho{}.SolveGT(context.Background(), gt)
+	assert.True(t, errors.Is(err, dlog.ErrUnsupported))
+	_, err = dlog.Kangaroo{}.SolveZp(context.Background(), zp)
+	assert.True(t, errors.Is(err, dlog.ErrUnsupported))
+	_, err = dlog.BabyStepGiantStep{}.SolveGT(context.Background(), &dlog.GTProblem{H: g, G: g, Bound: new(big.Int).Lsh(dlog.MaxBound, 1)})
+	assert.True(t, errors.Is(err, dlog.ErrUnsupported))
+	_, err = dlog.TableLookup{}.SolveGT(context.Background(), gt)
+	assert.True(t, errors.Is(err, dlog.ErrUnsupported))
+
+	key, err = keygen.NewElGamal(128)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	_, err = dlog.Auto{}.SolveZp(context.Background(), &dlog.ZpProblem{H: key.G, G: key.G, P: key.P, Order: key.Q})
+	assert.True(t, errors.Is(err, dlog.ErrUnsupported))
+}
+
+func TestSolvers_NotFound(t *testing.T) {
+	key, err := keygen.NewElGamal(32)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	h := new(big.Int).Exp(key.G, big.NewInt(5000), key.P)
+	pr := &dlog.ZpProblem{H: h, G: key.G, P: key.P, Order: key.Q, Bound: big.NewInt(1000)}
+
+	for _, s := range []dlog.Solver{dlog.BabyStepGiantStep{}, dlog.PollardRho{}} {
+		_, err = s.SolveZp(context.Background(), pr)
+		assert.Error(t, err)
+	}
+}
+
+func TestSolvers_Cancel(t *testing.T) {
+	key, err := keygen.NewElGamal(128)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pr := &dlog.ZpProblem{H: key.G, G: key.G, P: key.P, Order: key.Q, Bound: new(big.Int).Lsh(big.NewInt(1), 60)}
+
+	_, err = dlog.Auto{}.SolveZp(ctx, pr)
+	assert.Equal(t, context.Canceled, err)
+}
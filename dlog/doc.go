@@ -0,0 +1,28 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+// Package dlog computes discrete logarithms in the multiplicative
+// group of integers modulo a prime and in the BN256.GT group, as
+// needed for decryption by the schemes based on the discrete
+// logarithm.
+//
+// Solvers implement the Solver interface and differ in the
+// problems they are suited for: BabyStepGiantStep is fast for
+// small bounds, but needs memory proportional to the square root of
+// the bound; Kangaroo needs constant memory for arbitrary bounds;
+// PollardRho computes logarithms without a bound in small groups
+// of known order; TableLookup uses a precomputed Table for the
+// smallest bounds. Auto chooses a solver based on the bound.
+package dlog
@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/fentec-project/bn256"
+)
+
+// ErrUnsupported is returned by a solver for problems that
+// it cannot solve.
+var ErrUnsupported = errors.New("dlog: problem not supported by the solver")
+
+// ZpProblem is the problem of computing x, such that H = G^x mod P.
+type ZpProblem struct {
+	H, G, P *big.Int
+	// Order of G. If nil, P-1 is assumed.
+	Order *big.Int
+	// Bound on x, so that x is searched for in [0, Bound]. If nil,
+	// x is searched for among all the exponents.
+	Bound *big.Int
+	// If Neg is set, x is also searched for among negative integers,
+	// i.e. in [-Bound, Bound].
+	Neg bool
+}
+
+// GTProblem is the problem of computing x, such that H = G^x in
+// the BN256.GT group, where the group operation is written as
+// multiplication.
+type GTProblem struct {
+	H, G *bn256.GT
+	// Bound on x, so that x is searched for in [0, Bound]. If nil,
+	// a default bound of 2^48 is used by the solvers that need it.
+	Bound *big.Int
+	// If Neg is set, x is also searched for among negative integers,
+	// i.e. in [-Bound, Bound].
+	Neg bool
+}
+
+// Solver computes discrete logarithms. The solvers stop and return
+// the error of ctx as soon as ctx is done, and return an error
+// wrapping ErrUnsupported for problems they cannot solve.
+type Solver interface {
+	SolveZp(ctx context.Context, pr *ZpProblem) (*big.Int, error)
+	SolveGT(ctx context.Context, pr *GTProblem) (*big.Int, error)
+}
+
+// order returns the order of G in pr.
+func (pr *ZpProblem) order() *big.Int {
+	if pr.Order != nil {
+		return pr.Order
+	}
+	return new(big.Int).Sub(pr.P, big.NewInt(1))
+}
+
+// check returns an error if the problem is incomplete.
+func (pr *ZpProblem) check() error {
+	if pr == nil || pr.H == nil || pr.G == nil || pr.P == nil {
+		return fmt.Errorf("dlog: H, G and P should be set")
+	}
+	return checkBound(pr.Bound)
+}
+
+// check returns an error if the problem is incomplete.
+func (pr *GTProblem) check() error {
+	if pr == nil || pr.H == nil || pr.G == nil {
+		return fmt.Errorf("dlog: H and G should be set")
+	}
+	return checkBound(pr.Bound)
+}
+
+func checkBound(bound *big.Int) error {
+	if bound != nil && bound.Sign() < 0 {
+		return fmt.Errorf("dlog: bound should not be negative")
+	}
+	return nil
+}
+
+// inBound reports whether x is within the bounds of the problem.
+func inBound(x, bound *big.Int, neg bool) bool {
+	if x.Sign() < 0 && !neg {
+		return false
+	}
+	return bound == nil || new(big.Int).Abs(x).Cmp(bound) <= 0
+}
+
+// unsupported returns an error wrapping ErrUnsupported.
+func unsupported(solver, reason string) error {
+	return fmt.Errorf("%w: %s %s", ErrUnsupported, solver, reason)
+}
+
+// errNotFound is returned when the logarithm is not within the bound.
+var errNotFound = errors.New("dlog: failed to find the discrete logarithm within bound")
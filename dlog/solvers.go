@@ -0,0 +1,360 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dlog
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/internal/dlog"
+)
+
+// MaxBound is the largest bound supported by BabyStepGiantStep,
+// and the bound used for problems in BN256.GT without a bound.
+var MaxBound = dlog.MaxBound
+
+// bound returns the bound of pr, or MaxBound if it is not set.
+func (pr *GTProblem) bound() *big.Int {
+	if pr.Bound != nil {
+		return pr.Bound
+	}
+	return MaxBound
+}
+
+// searchBound returns a positive bound to be passed to the
+// underlying algorithms. The results are checked against the
+// original bound.
+func searchBound(bound *big.Int) *big.Int {
+	if bound.Sign() == 0 {
+		return big.NewInt(1)
+	}
+	return bound
+}
+
+// BabyStepGiantStep solves problems with the baby-step giant-step
+// method. It needs time and memory proportional to the square root
+// of the bound, thus bounds of at most MaxBound are supported.
+type BabyStepGiantStep struct {
+	// Table, if set and computed for the generator of the problem,
+	// is used for the baby steps.
+	Table *Table
+}
+
+// SolveZp solves a problem in Z_p. If the bound is not set, the
+// order of G must be at most MaxBound.
+func (s BabyStepGiantStep) SolveZp(ctx context.Context, pr *ZpProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	bound := pr.Bound
+	if bound == nil {
+		bound = pr.order()
+	}
+	if bound.Cmp(MaxBound) >= 0 {
+		return nil, unsupported("baby-step giant-step", "requires a bound below MaxBound")
+	}
+	calc, err := dlog.NewCalc().InZp(pr.P, pr.Order)
+	if err != nil {
+		return nil, err
+	}
+	calc = calc.WithBound(searchBound(bound))
+	if pr.Neg {
+		calc = calc.WithNeg()
+	}
+	if s.Table != nil && s.Table.IsZp(pr.P, pr.G) {
+		if calc, err = calc.WithTable(s.Table); err != nil {
+			return nil, err
+		}
+	}
+	x, err := calc.BabyStepGiantStepContext(ctx, pr.H, pr.G)
+	if err != nil {
+		return nil, err
+	}
+	if !inBound(x, pr.Bound, pr.Neg) {
+		return nil, errNotFound
+	}
+
+	return x, nil
+}
+
+// SolveGT solves a problem in BN256.GT.
+func (s BabyStepGiantStep) SolveGT(ctx context.Context, pr *GTProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	bound := pr.bound()
+	if bound.Cmp(MaxBound) > 0 {
+		return nil, unsupported("baby-step giant-step", "requires a bound of at most MaxBound")
+	}
+	calc := dlog.NewCalc().InBN256().WithBound(searchBound(bound))
+	if pr.Neg {
+		calc = calc.WithNeg()
+	}
+	if s.Table != nil && s.Table.IsBN256(pr.G) {
+		var err error
+		if calc, err = calc.WithTable(s.Table); err != nil {
+			return nil, err
+		}
+	}
+	x, err := calc.BabyStepGiantStepContext(ctx, pr.H, pr.G)
+	if err != nil {
+		return nil, err
+	}
+	if !inBound(x, bound, pr.Neg) {
+		return nil, errNotFound
+	}
+
+	return x, nil
+}
+
+// Kangaroo solves problems with the parallel version of Pollard's
+// kangaroo method by van Oorschot and Wiener. It needs time
+// proportional to the square root of the bound, divided among
+// the workers, but only a small amount of memory for the
+// distinguished points. The method is probabilistic: it gives up
+// after a few unsuccessful attempts, which in practice only happens
+// if the logarithm is not within the bound.
+type Kangaroo struct {
+	// Workers is the number of goroutines searching for the
+	// logarithm. If not positive, GOMAXPROCS is used.
+	Workers int
+}
+
+// SolveZp solves a problem in Z_p. The bound must be set.
+func (s Kangaroo) SolveZp(ctx context.Context, pr *ZpProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	if pr.Bound == nil {
+		return nil, unsupported("kangaroo", "requires a bound")
+	}
+	x, err := dlog.KangarooZp(ctx, pr.H, pr.G, pr.P, searchBound(pr.Bound), pr.Neg, s.Workers)
+	if err != nil {
+		return nil, err
+	}
+	if !inBound(x, pr.Bound, pr.Neg) {
+		return nil, errNotFound
+	}
+
+	return x, nil
+}
+
+// SolveGT solves a problem in BN256.GT.
+func (s Kangaroo) SolveGT(ctx context.Context, pr *GTProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	bound := pr.bound()
+	x, err := dlog.KangarooBN256(ctx, pr.H, pr.G, searchBound(bound), pr.Neg, s.Workers)
+	if err != nil {
+		return nil, err
+	}
+	if !inBound(x, bound, pr.Neg) {
+		return nil, errNotFound
+	}
+
+	return x, nil
+}
+
+// PollardRho solves problems in Z_p with Pollard's rho method.
+// It does not need a bound, but it takes about the square root of
+// the order of G steps, so it is only practical in small groups.
+// The order of G should be prime. Problems in BN256.GT are not
+// supported.
+type PollardRho struct {
+	// Parallel, if set, runs a random walk on each CPU.
+	Parallel bool
+}
+
+// SolveZp solves a problem in Z_p. If Neg is set, the result is
+// the representative of the logarithm with the smallest absolute
+// value.
+func (s PollardRho) SolveZp(ctx context.Context, pr *ZpProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	order := pr.order()
+	rho := dlog.PollardRho
+	if s.Parallel {
+		rho = dlog.PollardRhoParallel
+	}
+	x, err := rho(ctx, pr.H, pr.G, pr.P, order)
+	if err != nil {
+		return nil, err
+	}
+	x.Mod(x, order)
+	if pr.Neg && x.Cmp(new(big.Int).Rsh(order, 1)) > 0 {
+		x.Sub(x, order)
+	}
+	if !inBound(x, pr.Bound, pr.Neg) {
+		return nil, errNotFound
+	}
+
+	return x, nil
+}
+
+// SolveGT returns an error wrapping ErrUnsupported.
+func (s PollardRho) SolveGT(ctx context.Context, pr *GTProblem) (*big.Int, error) {
+	return nil, unsupported("pollard rho", "does not support BN256.GT")
+}
+
+// TableLookup solves problems by looking up the logarithm in
+// a precomputed table, which takes constant time. Only problems
+// with the generator of the table and a bound below 2^bits of
+// the table are supported.
+type TableLookup struct {
+	Table *Table
+}
+
+// covers reports whether the table contains all the logarithms
+// within bound.
+func covers(t *Table, bound *big.Int) bool {
+	return t != nil && bound != nil && bound.BitLen() <= t.Bits()
+}
+
+// SolveZp solves a problem in Z_p.
+func (s TableLookup) SolveZp(ctx context.Context, pr *ZpProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	if !covers(s.Table, pr.Bound) || !s.Table.IsZp(pr.P, pr.G) {
+		return nil, unsupported("table lookup", "requires a table for G covering the bound")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	check := func(x *big.Int) bool {
+		return new(big.Int).Exp(pr.G, x, pr.P).Cmp(pr.H) == 0
+	}
+	if x, ok := s.Table.LookupZp(pr.H); ok && check(x) && inBound(x, pr.Bound, false) {
+		return x, nil
+	}
+	if pr.Neg {
+		hInv := new(big.Int).ModInverse(pr.H, pr.P)
+		if hInv == nil {
+			return nil, errNotFound
+		}
+		if x, ok := s.Table.LookupZp(hInv); ok && check(x.Neg(x)) && inBound(x, pr.Bound, true) {
+			return x, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+// SolveGT solves a problem in BN256.GT.
+func (s TableLookup) SolveGT(ctx context.Context, pr *GTProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	bound := pr.bound()
+	if !covers(s.Table, bound) || !s.Table.IsBN256(pr.G) {
+		return nil, unsupported("table lookup", "requires a table for G covering the bound")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	check := func(x *big.Int) bool {
+		e := new(bn256.GT).ScalarMult(pr.G, new(big.Int).Mod(x, bn256.Order))
+		return e.String() == pr.H.String()
+	}
+	if x, ok := s.Table.LookupBN256(pr.H); ok && check(x) && inBound(x, bound, false) {
+		return x, nil
+	}
+	if pr.Neg {
+		hInv := new(bn256.GT).Neg(pr.H)
+		if x, ok := s.Table.LookupBN256(hInv); ok && check(x.Neg(x)) && inBound(x, bound, true) {
+			return x, nil
+		}
+	}
+
+	return nil, errNotFound
+}
+
+// autoBSGSBound is the largest bound for which Auto uses the
+// baby-step giant-step method. For larger bounds the memory
+// needed by it becomes impractical.
+var autoBSGSBound = new(big.Int).Lsh(big.NewInt(1), 40)
+
+// autoRhoOrder is the largest order of G for which Auto uses
+// Pollard's rho method for problems without a bound. For larger
+// orders the method does not finish in practical time.
+var autoRhoOrder = new(big.Int).Lsh(big.NewInt(1), 64)
+
+// Auto chooses a solver for each problem: TableLookup if the table
+// covers the bound, BabyStepGiantStep for bounds up to 2^40, Kangaroo
+// for larger bounds, and PollardRho for problems in Z_p without
+// a bound whose order is too large for BabyStepGiantStep, but at
+// most 2^64. Other problems without a bound are not supported.
+type Auto struct {
+	// Table, if set, is used by the chosen solver when it was
+	// computed for the generator of the problem.
+	Table *Table
+}
+
+// SolveZp solves a problem in Z_p.
+func (s Auto) SolveZp(ctx context.Context, pr *ZpProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	solver := s.solverZp(pr)
+	if solver == nil {
+		return nil, unsupported("auto", "does not support problems without a bound in groups of order larger than 2^64")
+	}
+	return solver.SolveZp(ctx, pr)
+}
+
+// solverZp returns the solver for pr, or nil if pr is not supported.
+func (s Auto) solverZp(pr *ZpProblem) Solver {
+	if covers(s.Table, pr.Bound) && s.Table.IsZp(pr.P, pr.G) {
+		return TableLookup{Table: s.Table}
+	}
+	bound := pr.Bound
+	if bound == nil {
+		bound = pr.order()
+	}
+	switch {
+	case bound.Cmp(autoBSGSBound) <= 0:
+		return BabyStepGiantStep{Table: s.Table}
+	case pr.Bound == nil && bound.Cmp(autoRhoOrder) <= 0:
+		return PollardRho{Parallel: true}
+	case pr.Bound == nil:
+		return nil
+	default:
+		return Kangaroo{}
+	}
+}
+
+// SolveGT solves a problem in BN256.GT.
+func (s Auto) SolveGT(ctx context.Context, pr *GTProblem) (*big.Int, error) {
+	if err := pr.check(); err != nil {
+		return nil, err
+	}
+	return s.solverGT(pr).SolveGT(ctx, pr)
+}
+
+func (s Auto) solverGT(pr *GTProblem) Solver {
+	bound := pr.bound()
+	switch {
+	case covers(s.Table, bound) && s.Table.IsBN256(pr.G):
+		return TableLookup{Table: s.Table}
+	case bound.Cmp(autoBSGSBound) <= 0:
+		return BabyStepGiantStep{Table: s.Table}
+	default:
+		return Kangaroo{}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dlog
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/fentec-project/gofe/internal/dlog"
+)
+
+// Table is a precomputed table of discrete logarithms of the
+// powers g^x for x in [0, 2^bits). It can be saved to a file and
+// loaded back, so that it is computed only once.
+type Table = dlog.Table
+
+// NewTableZp computes a table of the logarithms of g^x mod p
+// for x in [0, 2^bits).
+func NewTableZp(p, g *big.Int, bits int) (*Table, error) {
+	return dlog.NewTableZp(p, g, bits)
+}
+
+// NewTableGT computes a table of the logarithms of g^x for
+// x in [0, 2^bits), where g is the generator of BN256.GT.
+func NewTableGT(bits int) (*Table, error) {
+	return dlog.NewTableBN256(bits)
+}
+
+// LoadTable loads a table saved with Table.Save from the file
+// at path, checking its integrity.
+func LoadTable(path string) (*Table, error) {
+	return dlog.LoadTable(path)
+}
+
+// ReadTable reads a table written with Table.WriteTo from r,
+// checking its integrity.
+func ReadTable(r io.Reader) (*Table, error) {
+	return dlog.ReadTable(r)
+}
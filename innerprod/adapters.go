@@ -0,0 +1,548 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package innerprod
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod/fullysec"
+	"github.com/fentec-project/gofe/innerprod/simple"
+	gofe "github.com/fentec-project/gofe/internal"
+)
+
+// ddhScheme adapts simple.DDH to the Scheme interface.
+type ddhScheme struct {
+	s *simple.DDH
+}
+
+// FromDDH returns a Scheme backed by the simple.DDH scheme s.
+func FromDDH(s *simple.DDH) Scheme {
+	return &ddhScheme{s: s}
+}
+
+// Name returns "simple.DDH".
+func (a *ddhScheme) Name() string {
+	return "simple.DDH"
+}
+
+// Params returns the length and the bounds of the vectors
+// accepted by the scheme.
+func (a *ddhScheme) Params() Params {
+	return Params{L: a.s.Params.L, BoundX: a.s.Params.Bound, BoundY: a.s.Params.Bound}
+}
+
+// GenerateMasterKeys generates a master secret key and
+// a master public key for the scheme.
+func (a *ddhScheme) GenerateMasterKeys() (*MasterSecKey, *MasterPubKey, error) {
+	msk, mpk, err := a.s.GenerateMasterKeys()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &MasterSecKey{a.Name(), msk}, &MasterPubKey{a.Name(), mpk}, nil
+}
+
+// DeriveKey derives a functional encryption key for vector y.
+func (a *ddhScheme) DeriveKey(msk *MasterSecKey, y data.Vector) (*DerivedKey, error) {
+	sk, ok := msk.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("master secret key", a.Name())
+	}
+	key, err := a.s.DeriveKey(sk, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedKey{a.Name(), key}, nil
+}
+
+// Encrypt encrypts vector x with the master public key.
+func (a *ddhScheme) Encrypt(x data.Vector, mpk *MasterPubKey) (*Ciphertext, error) {
+	pk, ok := mpk.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("master public key", a.Name())
+	}
+	cipher, err := a.s.Encrypt(x, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ciphertext{a.Name(), cipher}, nil
+}
+
+// Decrypt decrypts the inner product of the encrypted vector and y.
+func (a *ddhScheme) Decrypt(c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	return a.DecryptContext(context.Background(), c, key, y)
+}
+
+// DecryptContext decrypts the inner product of the encrypted vector
+// and y, unless ctx is done first.
+func (a *ddhScheme) DecryptContext(ctx context.Context, c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	cipher, ok := c.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("ciphertext", a.Name())
+	}
+	dk, ok := key.get(a.Name()).(*big.Int)
+	if !ok {
+		return nil, errMismatch("derived key", a.Name())
+	}
+
+	return a.s.DecryptContext(ctx, cipher, dk, y)
+}
+
+// simpleLWEScheme adapts simple.LWE to the Scheme interface.
+type simpleLWEScheme struct {
+	s *simple.LWE
+}
+
+// FromSimpleLWE returns a Scheme backed by the simple.LWE scheme s.
+func FromSimpleLWE(s *simple.LWE) Scheme {
+	return &simpleLWEScheme{s: s}
+}
+
+// Name returns "simple.LWE".
+func (a *simpleLWEScheme) Name() string {
+	return "simple.LWE"
+}
+
+// Params returns the length and the bounds of the vectors
+// accepted by the scheme.
+func (a *simpleLWEScheme) Params() Params {
+	return Params{L: a.s.Params.L, BoundX: a.s.Params.BoundX, BoundY: a.s.Params.BoundY}
+}
+
+// GenerateMasterKeys generates a master secret key and
+// a master public key for the scheme.
+func (a *simpleLWEScheme) GenerateMasterKeys() (*MasterSecKey, *MasterPubKey, error) {
+	msk, err := a.s.GenerateSecretKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	mpk, err := a.s.GeneratePublicKey(msk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &MasterSecKey{a.Name(), msk}, &MasterPubKey{a.Name(), mpk}, nil
+}
+
+// DeriveKey derives a functional encryption key for vector y.
+func (a *simpleLWEScheme) DeriveKey(msk *MasterSecKey, y data.Vector) (*DerivedKey, error) {
+	sk, ok := msk.get(a.Name()).(data.Matrix)
+	if !ok {
+		return nil, errMismatch("master secret key", a.Name())
+	}
+	key, err := a.s.DeriveKey(y, sk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedKey{a.Name(), key}, nil
+}
+
+// Encrypt encrypts vector x with the master public key.
+func (a *simpleLWEScheme) Encrypt(x data.Vector, mpk *MasterPubKey) (*Ciphertext, error) {
+	pk, ok := mpk.get(a.Name()).(data.Matrix)
+	if !ok {
+		return nil, errMismatch("master public key", a.Name())
+	}
+	cipher, err := a.s.Encrypt(x, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ciphertext{a.Name(), cipher}, nil
+}
+
+// DecryptContext decrypts the inner product of the encrypted vector
+// and y, unless ctx is already done.
+func (a *simpleLWEScheme) DecryptContext(ctx context.Context, c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.Decrypt(c, key, y)
+}
+
+// Decrypt decrypts the inner product of the encrypted vector and y.
+func (a *simpleLWEScheme) Decrypt(c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	cipher, ok := c.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("ciphertext", a.Name())
+	}
+	dk, ok := key.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("derived key", a.Name())
+	}
+
+	return a.s.Decrypt(cipher, dk, y)
+}
+
+// damgardScheme adapts fullysec.Damgard to the Scheme interface.
+type damgardScheme struct {
+	s *fullysec.Damgard
+}
+
+// FromDamgard returns a Scheme backed by the fullysec.Damgard scheme s.
+func FromDamgard(s *fullysec.Damgard) Scheme {
+	return &damgardScheme{s: s}
+}
+
+// Name returns "fullysec.Damgard".
+func (a *damgardScheme) Name() string {
+	return "fullysec.Damgard"
+}
+
+// Params returns the length and the bounds of the vectors
+// accepted by the scheme.
+func (a *damgardScheme) Params() Params {
+	return Params{L: a.s.Params.L, BoundX: a.s.Params.Bound, BoundY: a.s.Params.Bound}
+}
+
+// GenerateMasterKeys generates a master secret key and
+// a master public key for the scheme.
+func (a *damgardScheme) GenerateMasterKeys() (*MasterSecKey, *MasterPubKey, error) {
+	msk, mpk, err := a.s.GenerateMasterKeys()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &MasterSecKey{a.Name(), msk}, &MasterPubKey{a.Name(), mpk}, nil
+}
+
+// DeriveKey derives a functional encryption key for vector y.
+func (a *damgardScheme) DeriveKey(msk *MasterSecKey, y data.Vector) (*DerivedKey, error) {
+	sk, ok := msk.get(a.Name()).(*fullysec.DamgardSecKey)
+	if !ok {
+		return nil, errMismatch("master secret key", a.Name())
+	}
+	key, err := a.s.DeriveKey(sk, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedKey{a.Name(), key}, nil
+}
+
+// Encrypt encrypts vector x with the master public key.
+func (a *damgardScheme) Encrypt(x data.Vector, mpk *MasterPubKey) (*Ciphertext, error) {
+	pk, ok := mpk.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("master public key", a.Name())
+	}
+	cipher, err := a.s.Encrypt(x, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ciphertext{a.Name(), cipher}, nil
+}
+
+// Decrypt decrypts the inner product of the encrypted vector and y.
+func (a *damgardScheme) Decrypt(c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	return a.DecryptContext(context.Background(), c, key, y)
+}
+
+// DecryptContext decrypts the inner product of the encrypted vector
+// and y, unless ctx is done first.
+func (a *damgardScheme) DecryptContext(ctx context.Context, c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	cipher, ok := c.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("ciphertext", a.Name())
+	}
+	dk, ok := key.get(a.Name()).(*fullysec.DamgardDerivedKey)
+	if !ok {
+		return nil, errMismatch("derived key", a.Name())
+	}
+
+	return a.s.DecryptContext(ctx, cipher, dk, y)
+}
+
+// paillierScheme adapts fullysec.Paillier to the Scheme interface.
+type paillierScheme struct {
+	s *fullysec.Paillier
+}
+
+// FromPaillier returns a Scheme backed by the fullysec.Paillier scheme s.
+func FromPaillier(s *fullysec.Paillier) Scheme {
+	return &paillierScheme{s: s}
+}
+
+// Name returns "fullysec.Paillier".
+func (a *paillierScheme) Name() string {
+	return "fullysec.Paillier"
+}
+
+// Params returns the length and the bounds of the vectors
+// accepted by the scheme.
+func (a *paillierScheme) Params() Params {
+	return Params{L: a.s.Params.L, BoundX: a.s.Params.BoundX, BoundY: a.s.Params.BoundY}
+}
+
+// GenerateMasterKeys generates a master secret key and
+// a master public key for the scheme.
+func (a *paillierScheme) GenerateMasterKeys() (*MasterSecKey, *MasterPubKey, error) {
+	msk, mpk, err := a.s.GenerateMasterKeys()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &MasterSecKey{a.Name(), msk}, &MasterPubKey{a.Name(), mpk}, nil
+}
+
+// DeriveKey derives a functional encryption key for vector y.
+func (a *paillierScheme) DeriveKey(msk *MasterSecKey, y data.Vector) (*DerivedKey, error) {
+	sk, ok := msk.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("master secret key", a.Name())
+	}
+	key, err := a.s.DeriveKey(sk, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedKey{a.Name(), key}, nil
+}
+
+// Encrypt encrypts vector x with the master public key.
+func (a *paillierScheme) Encrypt(x data.Vector, mpk *MasterPubKey) (*Ciphertext, error) {
+	pk, ok := mpk.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("master public key", a.Name())
+	}
+	cipher, err := a.s.Encrypt(x, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ciphertext{a.Name(), cipher}, nil
+}
+
+// DecryptContext decrypts the inner product of the encrypted vector
+// and y, unless ctx is already done.
+func (a *paillierScheme) DecryptContext(ctx context.Context, c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.Decrypt(c, key, y)
+}
+
+// Decrypt decrypts the inner product of the encrypted vector and y.
+func (a *paillierScheme) Decrypt(c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	cipher, ok := c.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("ciphertext", a.Name())
+	}
+	dk, ok := key.get(a.Name()).(*big.Int)
+	if !ok {
+		return nil, errMismatch("derived key", a.Name())
+	}
+
+	return a.s.Decrypt(cipher, dk, y)
+}
+
+// fullySecLWEScheme adapts fullysec.LWE to the Scheme interface.
+type fullySecLWEScheme struct {
+	s *fullysec.LWE
+}
+
+// FromFullySecLWE returns a Scheme backed by the fullysec.LWE scheme s.
+func FromFullySecLWE(s *fullysec.LWE) Scheme {
+	return &fullySecLWEScheme{s: s}
+}
+
+// Name returns "fullysec.LWE".
+func (a *fullySecLWEScheme) Name() string {
+	return "fullysec.LWE"
+}
+
+// Params returns the length and the bounds of the vectors
+// accepted by the scheme.
+func (a *fullySecLWEScheme) Params() Params {
+	return Params{L: a.s.Params.L, BoundX: a.s.Params.BoundX, BoundY: a.s.Params.BoundY}
+}
+
+// GenerateMasterKeys generates a master secret key and
+// a master public key for the scheme.
+func (a *fullySecLWEScheme) GenerateMasterKeys() (*MasterSecKey, *MasterPubKey, error) {
+	msk, err := a.s.GenerateSecretKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	mpk, err := a.s.GeneratePublicKey(msk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &MasterSecKey{a.Name(), msk}, &MasterPubKey{a.Name(), mpk}, nil
+}
+
+// DeriveKey derives a functional encryption key for vector y.
+func (a *fullySecLWEScheme) DeriveKey(msk *MasterSecKey, y data.Vector) (*DerivedKey, error) {
+	sk, ok := msk.get(a.Name()).(data.Matrix)
+	if !ok {
+		return nil, errMismatch("master secret key", a.Name())
+	}
+	key, err := a.s.DeriveKey(y, sk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedKey{a.Name(), key}, nil
+}
+
+// Encrypt encrypts vector x with the master public key.
+func (a *fullySecLWEScheme) Encrypt(x data.Vector, mpk *MasterPubKey) (*Ciphertext, error) {
+	pk, ok := mpk.get(a.Name()).(data.Matrix)
+	if !ok {
+		return nil, errMismatch("master public key", a.Name())
+	}
+	cipher, err := a.s.Encrypt(x, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ciphertext{a.Name(), cipher}, nil
+}
+
+// DecryptContext decrypts the inner product of the encrypted vector
+// and y, unless ctx is already done.
+func (a *fullySecLWEScheme) DecryptContext(ctx context.Context, c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.Decrypt(c, key, y)
+}
+
+// Decrypt decrypts the inner product of the encrypted vector and y.
+func (a *fullySecLWEScheme) Decrypt(c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	cipher, ok := c.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("ciphertext", a.Name())
+	}
+	dk, ok := key.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("derived key", a.Name())
+	}
+
+	return a.s.Decrypt(cipher, dk, y)
+}
+
+// ringLWEScheme adapts simple.RingLWE to the Scheme interface.
+type ringLWEScheme struct {
+	s *simple.RingLWE
+}
+
+// FromRingLWE returns a Scheme backed by the simple.RingLWE scheme s.
+// The RingLWE scheme encrypts n vectors at once, hence a vector x is
+// encrypted as the first column of an otherwise zero l x n matrix.
+func FromRingLWE(s *simple.RingLWE) Scheme {
+	return &ringLWEScheme{s: s}
+}
+
+// Name returns "simple.RingLWE".
+func (a *ringLWEScheme) Name() string {
+	return "simple.RingLWE"
+}
+
+// Params returns the length and the bounds of the vectors
+// accepted by the scheme.
+func (a *ringLWEScheme) Params() Params {
+	return Params{L: a.s.Params.L, BoundX: a.s.Params.Bound, BoundY: a.s.Params.Bound}
+}
+
+// GenerateMasterKeys generates a master secret key and
+// a master public key for the scheme.
+func (a *ringLWEScheme) GenerateMasterKeys() (*MasterSecKey, *MasterPubKey, error) {
+	msk, err := a.s.GenerateSecretKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	mpk, err := a.s.GeneratePublicKey(msk)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &MasterSecKey{a.Name(), msk}, &MasterPubKey{a.Name(), mpk}, nil
+}
+
+// DeriveKey derives a functional encryption key for vector y.
+func (a *ringLWEScheme) DeriveKey(msk *MasterSecKey, y data.Vector) (*DerivedKey, error) {
+	sk, ok := msk.get(a.Name()).(data.Matrix)
+	if !ok {
+		return nil, errMismatch("master secret key", a.Name())
+	}
+	key, err := a.s.DeriveKey(y, sk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivedKey{a.Name(), key}, nil
+}
+
+// Encrypt encrypts vector x with the master public key.
+func (a *ringLWEScheme) Encrypt(x data.Vector, mpk *MasterPubKey) (*Ciphertext, error) {
+	pk, ok := mpk.get(a.Name()).(data.Matrix)
+	if !ok {
+		return nil, errMismatch("master public key", a.Name())
+	}
+	if len(x) != a.s.Params.L {
+		return nil, gofe.ErrMalformedInput
+	}
+	X := data.NewConstantMatrix(a.s.Params.L, a.s.Params.N, big.NewInt(0))
+	for i, c := range x {
+		X[i][0] = c
+	}
+	cipher, err := a.s.Encrypt(X, pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ciphertext{a.Name(), cipher}, nil
+}
+
+// DecryptContext decrypts the inner product of the encrypted vector
+// and y, unless ctx is already done.
+func (a *ringLWEScheme) DecryptContext(ctx context.Context, c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.Decrypt(c, key, y)
+}
+
+// Decrypt decrypts the inner product of the encrypted vector and y.
+func (a *ringLWEScheme) Decrypt(c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error) {
+	cipher, ok := c.get(a.Name()).(data.Matrix)
+	if !ok {
+		return nil, errMismatch("ciphertext", a.Name())
+	}
+	dk, ok := key.get(a.Name()).(data.Vector)
+	if !ok {
+		return nil, errMismatch("derived key", a.Name())
+	}
+	xy, err := a.s.Decrypt(cipher, dk, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return xy[0], nil
+}
@@ -25,4 +25,20 @@
 // well as multi input schemes. Construction of all multi input
 // schemes is based on the work of Abdalla et. al (see paper:
 // https://eprint.iacr.org/2017/972.pdf)
+//
+// The single input schemes can be used through the common Scheme
+// interface, which allows to swap schemes behind one code path.
+// Adapters such as FromDDH or FromPaillier wrap a configured scheme
+// into a Scheme, whose keys and ciphertexts are opaque.
+//
+// Schemes can also be constructed from named presets, such as
+// "ddh-128" or "ringlwe-128", which choose the parameters for a
+// security level given only the length of vectors and the bounds on
+// their coordinates (see New and Presets). The security level of a
+// constructed scheme is estimated from its parameters. Note that the
+// LWE presets need big public matrices for these security levels,
+// which take a lot of time and memory to generate, and that the
+// Paillier presets generate new safe primes of 1024 or 1536 bits for
+// each scheme, which can take minutes. To reuse the parameters of a
+// Paillier scheme, wrap fullysec.NewPaillierFromParams in FromPaillier.
 package innerprod
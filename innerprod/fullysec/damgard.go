@@ -17,6 +17,7 @@
 package fullysec
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -289,10 +290,73 @@ func (d *Damgard) Encrypt(x, masterPubKey data.Vector) (data.Vector, error) {
 	return data.NewVector(ciphertext), nil
 }
 
+// EncryptBatch encrypts input vectors xs with the provided
+// master public key. It returns the ciphertexts in the same order,
+// each of them as if produced by Encrypt.
+//
+// If the batch is large enough, the powers of the generators and of the
+// elements of the master public key are computed from precomputed
+// fixed-base tables shared by all the vectors, as far as their memory
+// allows. The vectors are encrypted concurrently, so that encrypting
+// many vectors is much faster than calling Encrypt for each of them. If any of the vectors violates the
+// bound or has a wrong length, no vector is encrypted and an
+// error is returned.
+func (d *Damgard) EncryptBatch(xs []data.Vector, masterPubKey data.Vector) ([]data.Vector, error) {
+	for _, x := range xs {
+		if err := x.CheckBound(d.Params.Bound); err != nil {
+			return nil, err
+		}
+		if len(x) != len(masterPubKey) {
+			return nil, internal.ErrMalformedInput
+		}
+	}
+
+	bits := d.Params.Q.BitLen()
+	bases := internal.NewFixedBases(append([]*big.Int{d.Params.G, d.Params.H}, masterPubKey...),
+		d.Params.P, bits, len(xs))
+	g, h, mpk := bases[0], bases[1], bases[2:]
+
+	sampler := sample.NewUniformRange(big.NewInt(2), d.Params.Q)
+	ciphertexts := make([]data.Vector, len(xs))
+	err := internal.Parallel(len(xs), func(j int) error {
+		r, err := sampler.Sample()
+		if err != nil {
+			return err
+		}
+
+		x := xs[j]
+		ciphertext := make(data.Vector, len(x)+2)
+		// c = g^r
+		// dd = h^r
+		ciphertext[0] = g.Exp(r)
+		ciphertext[1] = h.Exp(r)
+		for i := 0; i < len(x); i++ {
+			// e_i = mpk[i]^r * g^x_i
+			ct := mpk[i].Exp(r)
+			ct.Mul(ct, g.Exp(x[i]))
+			ciphertext[i+2] = ct.Mod(ct, d.Params.P)
+		}
+		ciphertexts[j] = ciphertext
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ciphertexts, nil
+}
+
 // Decrypt accepts the encrypted vector, functional encryption key, and
 // a plaintext vector y. It returns the inner product of x and y.
 // If decryption failed, error is returned.
 func (d *Damgard) Decrypt(cipher data.Vector, key *DamgardDerivedKey, y data.Vector) (*big.Int, error) {
+	return d.DecryptContext(context.Background(), cipher, key, y)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (d *Damgard) DecryptContext(ctx context.Context, cipher data.Vector, key *DamgardDerivedKey, y data.Vector) (*big.Int, error) {
 	if err := y.CheckBound(d.Params.Bound); err != nil {
 		return nil, err
 	}
@@ -319,6 +383,75 @@ func (d *Damgard) Decrypt(cipher data.Vector, key *DamgardDerivedKey, y data.Vec
 	}
 	calc = calc.WithNeg()
 
-	res, err := calc.WithBound(bound).BabyStepGiantStep(r, d.Params.G)
+	res, err := calc.WithBound(bound).BabyStepGiantStepContext(ctx, r, d.Params.G)
 	return res, err
 }
+
+// DecryptMany decrypts the ciphertext with each of the derived keys,
+// where keys[i] was derived for the vector ys[i], and returns the
+// inner products of x with each of ys. It is faster than calling
+// Decrypt for each key: the products of powers of the ciphertext
+// elements are computed with multi-exponentiation, which shares
+// precomputed powers of the ciphertext among all the keys, and the
+// discrete logarithms are computed in parallel with one calculator,
+// which shares the baby steps among them.
+func (d *Damgard) DecryptMany(cipher data.Vector, keys []*DamgardDerivedKey, ys []data.Vector) ([]*big.Int, error) {
+	return d.DecryptManyContext(context.Background(), cipher, keys, ys)
+}
+
+// DecryptManyContext is like DecryptMany, but aborts the computation
+// of the discrete logarithms and returns the error of ctx as soon as
+// ctx is done.
+func (d *Damgard) DecryptManyContext(ctx context.Context, cipher data.Vector, keys []*DamgardDerivedKey, ys []data.Vector) ([]*big.Int, error) {
+	if len(keys) != len(ys) {
+		return nil, internal.ErrMalformedDecKey
+	}
+	for i, y := range ys {
+		if err := y.CheckBound(d.Params.Bound); err != nil {
+			return nil, err
+		}
+		if len(y) != len(cipher)-2 {
+			return nil, internal.ErrMalformedInput
+		}
+		if keys[i] == nil || keys[i].Key1 == nil || keys[i].Key2 == nil {
+			return nil, internal.ErrMalformedDecKey
+		}
+	}
+
+	bSquared := new(big.Int).Exp(d.Params.Bound, big.NewInt(2), big.NewInt(0))
+	bound := new(big.Int).Mul(big.NewInt(int64(d.Params.L)), bSquared)
+	calc, err := dlog.NewCalc().InZp(d.Params.P, d.Params.Q)
+	if err != nil {
+		return nil, err
+	}
+	calc = calc.WithNeg().WithBound(bound)
+	// the baby steps are computed once for all the keys
+	table, err := dlog.NewTableZpForBound(d.Params.P, d.Params.G, bound)
+	if err != nil {
+		return nil, err
+	}
+	defer table.Close()
+	if calc, err = calc.WithTable(table); err != nil {
+		return nil, err
+	}
+
+	// ct_0^(-key1) * ct_1^(-key2) * prod ct_i^y_i
+	multiExp := internal.NewMultiExp(cipher, d.Params.P)
+	res := make([]*big.Int, len(keys))
+	err = internal.Parallel(len(keys), func(k int) error {
+		exps := make([]*big.Int, len(cipher))
+		exps[0] = new(big.Int).Neg(keys[k].Key1)
+		exps[1] = new(big.Int).Neg(keys[k].Key2)
+		copy(exps[2:], ys[k])
+		r := multiExp.Exp(exps)
+
+		var err error
+		res[k], err = calc.BabyStepGiantStepContext(ctx, r, d.Params.G)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -17,6 +17,7 @@
 package fullysec
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"math/big"
@@ -202,6 +203,12 @@ func NewDamgardDecMultiDec(damgardMulti *DamgardMulti) *DamgardDecMultiDec {
 // the inner-product vectors. It returns the sum of inner products.
 // If decryption failed, an error is returned.
 func (dc *DamgardDecMultiDec) Decrypt(cipher []data.Vector, partKeys []*DamgardDecMultiDerivedKeyPart, y data.Matrix) (*big.Int, error) {
+	return dc.DecryptContext(context.Background(), cipher, partKeys, y)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (dc *DamgardDecMultiDec) DecryptContext(ctx context.Context, cipher []data.Vector, partKeys []*DamgardDecMultiDerivedKeyPart, y data.Matrix) (*big.Int, error) {
 	if err := y.CheckBound(dc.Params.Bound); err != nil {
 		return nil, err
 	}
@@ -220,5 +227,5 @@ func (dc *DamgardDecMultiDec) Decrypt(cipher []data.Vector, partKeys []*DamgardD
 		Z: z,
 	}
 
-	return dc.DamgardMulti.Decrypt(cipher, key, y)
+	return dc.DamgardMulti.DecryptContext(ctx, cipher, key, y)
 }
@@ -17,6 +17,7 @@
 package fullysec
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -237,6 +238,12 @@ func (dm *DamgardMulti) DeriveKey(secKey *DamgardMultiSecKeys, y data.Matrix) (*
 // It returns the sum of inner products Σ_i <x_i, y_i>.
 // If decryption failed, error is returned.
 func (dm *DamgardMulti) Decrypt(cipher []data.Vector, key *DamgardMultiDerivedKey, y data.Matrix) (*big.Int, error) {
+	return dm.DecryptContext(context.Background(), cipher, key, y)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (dm *DamgardMulti) DecryptContext(ctx context.Context, cipher []data.Vector, key *DamgardMultiDerivedKey, y data.Matrix) (*big.Int, error) {
 	if err := y.CheckBound(dm.Bound); err != nil {
 		return nil, err
 	}
@@ -273,7 +280,7 @@ func (dm *DamgardMulti) Decrypt(cipher []data.Vector, key *DamgardMultiDerivedKe
 
 	bound := new(big.Int).Mul(dm.Bound, dm.Bound)
 	bound.Mul(bound, big.NewInt(int64(dm.Params.L*dm.NumClients)))
-	res, err := calc.WithBound(bound).BabyStepGiantStep(r, dm.Params.G)
+	res, err := calc.WithBound(bound).BabyStepGiantStepContext(ctx, r, dm.Params.G)
 
 	return res, err
 }
@@ -105,3 +105,170 @@ func TestFullySec_DamgardDDH(t *testing.T) {
 		})
 	}
 }
+
+func TestFullySec_DamgardDDHEncryptBatch(t *testing.T) {
+	l := 3
+	// large enough for the fixed-base tables to be used
+	n := 20
+	bound := big.NewInt(1024)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), new(big.Int).Add(bound, big.NewInt(1)))
+
+	damgard, err := fullysec.NewDamgardPrecomp(l, 2048, bound)
+	if err != nil {
+		t.Fatalf("Error during fully secure inner product creation: %v", err)
+	}
+	masterSecKey, masterPubKey, err := damgard.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	y, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	key, err := damgard.DeriveKey(masterSecKey, y)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+
+	xs := make([]data.Vector, n)
+	for j := range xs {
+		xs[j], err = data.NewRandomVector(l, sampler)
+		if err != nil {
+			t.Fatalf("Error during random generation: %v", err)
+		}
+	}
+	// coordinates on the bounds
+	xs[0] = data.NewConstantVector(l, bound)
+	xs[1] = data.NewConstantVector(l, new(big.Int).Neg(bound))
+
+	ciphertexts, err := damgard.EncryptBatch(xs, masterPubKey)
+	if err != nil {
+		t.Fatalf("Error during batch encryption: %v", err)
+	}
+	assert.Equal(t, n, len(ciphertexts))
+
+	for j, ciphertext := range ciphertexts {
+		xyCheck, err := xs[j].Dot(y)
+		if err != nil {
+			t.Fatalf("Error during inner product calculation: %v", err)
+		}
+		xy, err := damgard.Decrypt(ciphertext, key, y)
+		if err != nil {
+			t.Fatalf("Error during decryption: %v", err)
+		}
+		assert.Equal(t, 0, xy.Cmp(xyCheck), "Original and decrypted values should match")
+	}
+
+	// a vector out of bounds fails the whole batch
+	xs[n-1] = data.NewConstantVector(l, new(big.Int).Add(bound, big.NewInt(1)))
+	_, err = damgard.EncryptBatch(xs, masterPubKey)
+	assert.Error(t, err)
+	xs[n-1] = data.NewConstantVector(l+1, bound)
+	_, err = damgard.EncryptBatch(xs, masterPubKey)
+	assert.Error(t, err)
+}
+
+func TestFullySec_DamgardDDHDecryptMany(t *testing.T) {
+	l := 3
+	n := 10
+	bound := big.NewInt(1024)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), new(big.Int).Add(bound, big.NewInt(1)))
+
+	damgard, err := fullysec.NewDamgardPrecomp(l, 2048, bound)
+	if err != nil {
+		t.Fatalf("Error during fully secure inner product creation: %v", err)
+	}
+	masterSecKey, masterPubKey, err := damgard.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	x, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	ciphertext, err := damgard.Encrypt(x, masterPubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+
+	ys := make([]data.Vector, n)
+	keys := make([]*fullysec.DamgardDerivedKey, n)
+	for j := range ys {
+		ys[j], err = data.NewRandomVector(l, sampler)
+		if err != nil {
+			t.Fatalf("Error during random generation: %v", err)
+		}
+		keys[j], err = damgard.DeriveKey(masterSecKey, ys[j])
+		if err != nil {
+			t.Fatalf("Error during key derivation: %v", err)
+		}
+	}
+
+	xys, err := damgard.DecryptMany(ciphertext, keys, ys)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, n, len(xys))
+	for j, xy := range xys {
+		xyCheck, err := x.Dot(ys[j])
+		if err != nil {
+			t.Fatalf("Error during inner product calculation: %v", err)
+		}
+		assert.Equal(t, 0, xy.Cmp(xyCheck), "Original and decrypted values should match")
+	}
+
+	_, err = damgard.DecryptMany(ciphertext, keys[1:], ys)
+	assert.Error(t, err)
+	ys[n-1] = data.NewConstantVector(l, new(big.Int).Add(bound, big.NewInt(1)))
+	_, err = damgard.DecryptMany(ciphertext, keys, ys)
+	assert.Error(t, err)
+}
+
+func benchmarkDamgard(b *testing.B, n int) (*fullysec.Damgard, []data.Vector, data.Vector) {
+	l := 10
+	bound := big.NewInt(1000)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+
+	damgard, err := fullysec.NewDamgardPrecomp(l, 2048, bound)
+	if err != nil {
+		b.Fatalf("Error during fully secure inner product creation: %v", err)
+	}
+	_, masterPubKey, err := damgard.GenerateMasterKeys()
+	if err != nil {
+		b.Fatalf("Error during master key generation: %v", err)
+	}
+	xs := make([]data.Vector, n)
+	for j := range xs {
+		xs[j], err = data.NewRandomVector(l, sampler)
+		if err != nil {
+			b.Fatalf("Error during random generation: %v", err)
+		}
+	}
+
+	return damgard, xs, masterPubKey
+}
+
+// BenchmarkFullySec_DamgardDDHEncrypt and BenchmarkFullySec_DamgardDDHEncryptBatch
+// encrypt the same number of vectors, so that their
+// throughput can be compared.
+func BenchmarkFullySec_DamgardDDHEncrypt(b *testing.B) {
+	damgard, xs, masterPubKey := benchmarkDamgard(b, 100)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for _, x := range xs {
+			if _, err := damgard.Encrypt(x, masterPubKey); err != nil {
+				b.Fatalf("Error during encryption: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkFullySec_DamgardDDHEncryptBatch(b *testing.B) {
+	damgard, xs, masterPubKey := benchmarkDamgard(b, 100)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, err := damgard.EncryptBatch(xs, masterPubKey); err != nil {
+			b.Fatalf("Error during batch encryption: %v", err)
+		}
+	}
+}
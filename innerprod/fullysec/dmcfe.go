@@ -17,6 +17,7 @@
 package fullysec
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -156,6 +157,13 @@ func (c *DMCFEClient) DeriveKeyShare(y data.Vector) (data.VectorG2, error) {
 // a string under which vector x has been encrypted (each client encrypted x_i under this label). The value bound
 // specifies the bound of the output (solution will be in the interval (-bound, bound)) and can be nil.
 func DMCFEDecrypt(ciphers []*bn256.G1, keyShares []data.VectorG2, y data.Vector, label string,
+	bound *big.Int) (*big.Int, error) {
+	return DMCFEDecryptContext(context.Background(), ciphers, keyShares, y, label, bound)
+}
+
+// DMCFEDecryptContext is like DMCFEDecrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func DMCFEDecryptContext(ctx context.Context, ciphers []*bn256.G1, keyShares []data.VectorG2, y data.Vector, label string,
 	bound *big.Int) (*big.Int, error) {
 	key1 := new(bn256.G2).ScalarBaseMult(big.NewInt(0))
 	key2 := new(bn256.G2).ScalarBaseMult(big.NewInt(0))
@@ -200,7 +208,7 @@ func DMCFEDecrypt(ciphers []*bn256.G1, keyShares []data.VectorG2, y data.Vector,
 	g2gen := new(bn256.G2).ScalarBaseMult(big.NewInt(1))
 	g := bn256.Pair(g1gen, g2gen)
 
-	dec, err := dlog.NewCalc().InBN256().WithNeg().WithBound(bound).BabyStepGiantStep(s, g)
+	dec, err := dlog.NewCalc().InBN256().WithNeg().SearchContext(ctx, s, g, bound)
 
 	return dec, err
 }
@@ -17,6 +17,7 @@
 package fullysec
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/fentec-project/bn256"
@@ -222,6 +223,12 @@ func (f FHMultiIPE) Encrypt(x data.Vector, partSecKey data.Matrix) (data.VectorG
 // It returns the sum of inner products <x_1,y_1> + ... + <x_m, y_m>. If decryption
 // failed, an error is returned.
 func (f *FHMultiIPE) Decrypt(cipher data.MatrixG1, key data.MatrixG2, pubKey *bn256.GT) (*big.Int, error) {
+	return f.DecryptContext(context.Background(), cipher, key, pubKey)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (f *FHMultiIPE) DecryptContext(ctx context.Context, cipher data.MatrixG1, key data.MatrixG2, pubKey *bn256.GT) (*big.Int, error) {
 	sum := new(bn256.GT).ScalarBaseMult(big.NewInt(0))
 	for i := 0; i < f.Params.NumClients; i++ {
 		for j := 0; j < 2*f.Params.VecLen+2*f.Params.SecLevel+1; j++ {
@@ -233,7 +240,7 @@ func (f *FHMultiIPE) Decrypt(cipher data.MatrixG1, key data.MatrixG2, pubKey *bn
 	boundXY := new(big.Int).Mul(f.Params.BoundX, f.Params.BoundY)
 	bound := new(big.Int).Mul(big.NewInt(int64(f.Params.NumClients*f.Params.VecLen)), boundXY)
 
-	dec, err := dlog.NewCalc().InBN256().WithNeg().WithBound(bound).BabyStepGiantStep(sum, pubKey)
+	dec, err := dlog.NewCalc().InBN256().WithNeg().SearchContext(ctx, sum, pubKey, bound)
 
 	return dec, err
 }
@@ -17,6 +17,7 @@
 package fullysec
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -212,6 +213,12 @@ func (d *FHIPE) Encrypt(x data.Vector, masterKey *FHIPESecKey) (*FHIPECipher, er
 // It returns the inner product of x and y. If decryption failed,
 // an error is returned.
 func (d *FHIPE) Decrypt(cipher *FHIPECipher, key *FHIPEDerivedKey) (*big.Int, error) {
+	return d.DecryptContext(context.Background(), cipher, key)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (d *FHIPE) DecryptContext(ctx context.Context, cipher *FHIPECipher, key *FHIPEDerivedKey) (*big.Int, error) {
 	if len(cipher.C2) != d.Params.L || len(key.K2) != d.Params.L {
 		return nil, fmt.Errorf("key or cipher length error")
 	}
@@ -229,6 +236,6 @@ func (d *FHIPE) Decrypt(cipher *FHIPECipher, key *FHIPEDerivedKey) (*big.Int, er
 	boundXY := new(big.Int).Mul(d.Params.BoundX, d.Params.BoundY)
 	bound := new(big.Int).Mul(big.NewInt(int64(d.Params.L)), boundXY)
 
-	dec, err := dlog.NewCalc().InBN256().WithNeg().WithBound(bound).BabyStepGiantStep(d2, d1)
+	dec, err := dlog.NewCalc().InBN256().WithNeg().SearchContext(ctx, d2, d1, bound)
 	return dec, err
 }
@@ -0,0 +1,501 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fullysec
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+type damgardSecKeyJSON struct {
+	serial.Header
+	S data.Vector `json:"s"`
+	T data.Vector `json:"t"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *DamgardSecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(damgardSecKeyJSON{
+		Header: serial.NewHeader("fullysec.DamgardSecKey"),
+		S:      k.S,
+		T:      k.T,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *DamgardSecKey) UnmarshalJSON(b []byte) error {
+	var enc damgardSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.DamgardSecKey"); err != nil {
+		return err
+	}
+	*k = DamgardSecKey{
+		S: enc.S,
+		T: enc.T,
+	}
+
+	return nil
+}
+
+type damgardDerivedKeyJSON struct {
+	serial.Header
+	Key1 *serial.BigInt `json:"key1"`
+	Key2 *serial.BigInt `json:"key2"`
+}
+
+// MarshalJSON encodes the derived key as a JSON object.
+func (k *DamgardDerivedKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(damgardDerivedKeyJSON{
+		Header: serial.NewHeader("fullysec.DamgardDerivedKey"),
+		Key1:   (*serial.BigInt)(k.Key1),
+		Key2:   (*serial.BigInt)(k.Key2),
+	})
+}
+
+// UnmarshalJSON decodes the derived key encoded with MarshalJSON into k.
+func (k *DamgardDerivedKey) UnmarshalJSON(b []byte) error {
+	var enc damgardDerivedKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.DamgardDerivedKey"); err != nil {
+		return err
+	}
+	*k = DamgardDerivedKey{
+		Key1: (*big.Int)(enc.Key1),
+		Key2: (*big.Int)(enc.Key2),
+	}
+
+	return nil
+}
+
+type damgardMultiSecKeysJSON struct {
+	serial.Header
+	Msk []*DamgardSecKey `json:"msk"`
+	Mpk data.Matrix      `json:"mpk"`
+	Otp data.Matrix      `json:"otp"`
+}
+
+// MarshalJSON encodes the secret keys as a JSON object.
+func (k *DamgardMultiSecKeys) MarshalJSON() ([]byte, error) {
+	return json.Marshal(damgardMultiSecKeysJSON{
+		Header: serial.NewHeader("fullysec.DamgardMultiSecKeys"),
+		Msk:    k.Msk,
+		Mpk:    k.Mpk,
+		Otp:    k.Otp,
+	})
+}
+
+// UnmarshalJSON decodes the secret keys encoded with MarshalJSON into k.
+func (k *DamgardMultiSecKeys) UnmarshalJSON(b []byte) error {
+	var enc damgardMultiSecKeysJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.DamgardMultiSecKeys"); err != nil {
+		return err
+	}
+	*k = DamgardMultiSecKeys{
+		Msk: enc.Msk,
+		Mpk: enc.Mpk,
+		Otp: enc.Otp,
+	}
+
+	return nil
+}
+
+type damgardMultiDerivedKeyJSON struct {
+	serial.Header
+	Keys []*DamgardDerivedKey `json:"keys"`
+	Z    *serial.BigInt       `json:"z"`
+}
+
+// MarshalJSON encodes the derived key as a JSON object.
+func (k *DamgardMultiDerivedKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(damgardMultiDerivedKeyJSON{
+		Header: serial.NewHeader("fullysec.DamgardMultiDerivedKey"),
+		Keys:   k.Keys,
+		Z:      (*serial.BigInt)(k.Z),
+	})
+}
+
+// UnmarshalJSON decodes the derived key encoded with MarshalJSON into k.
+func (k *DamgardMultiDerivedKey) UnmarshalJSON(b []byte) error {
+	var enc damgardMultiDerivedKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.DamgardMultiDerivedKey"); err != nil {
+		return err
+	}
+	*k = DamgardMultiDerivedKey{
+		Keys: enc.Keys,
+		Z:    (*big.Int)(enc.Z),
+	}
+
+	return nil
+}
+
+type damgardDecMultiSecKeyJSON struct {
+	serial.Header
+	SecKey *DamgardSecKey `json:"secKey"`
+	PubKey data.Vector    `json:"pubKey"`
+	OtpKey data.Vector    `json:"otpKey"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *DamgardDecMultiSecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(damgardDecMultiSecKeyJSON{
+		Header: serial.NewHeader("fullysec.DamgardDecMultiSecKey"),
+		SecKey: k.sk,
+		PubKey: k.pk,
+		OtpKey: k.OtpKey,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *DamgardDecMultiSecKey) UnmarshalJSON(b []byte) error {
+	var enc damgardDecMultiSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.DamgardDecMultiSecKey"); err != nil {
+		return err
+	}
+	*k = DamgardDecMultiSecKey{
+		sk:     enc.SecKey,
+		pk:     enc.PubKey,
+		OtpKey: enc.OtpKey,
+	}
+
+	return nil
+}
+
+type damgardDecMultiDerivedKeyPartJSON struct {
+	serial.Header
+	KeyPart    *DamgardDerivedKey `json:"keyPart"`
+	OTPKeyPart *serial.BigInt     `json:"otpKeyPart"`
+}
+
+// MarshalJSON encodes the derived key part as a JSON object.
+func (k *DamgardDecMultiDerivedKeyPart) MarshalJSON() ([]byte, error) {
+	return json.Marshal(damgardDecMultiDerivedKeyPartJSON{
+		Header:     serial.NewHeader("fullysec.DamgardDecMultiDerivedKeyPart"),
+		KeyPart:    k.KeyPart,
+		OTPKeyPart: (*serial.BigInt)(k.OTPKeyPart),
+	})
+}
+
+// UnmarshalJSON decodes the derived key part encoded with MarshalJSON into k.
+func (k *DamgardDecMultiDerivedKeyPart) UnmarshalJSON(b []byte) error {
+	var enc damgardDecMultiDerivedKeyPartJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.DamgardDecMultiDerivedKeyPart"); err != nil {
+		return err
+	}
+	*k = DamgardDecMultiDerivedKeyPart{
+		KeyPart:    enc.KeyPart,
+		OTPKeyPart: (*big.Int)(enc.OTPKeyPart),
+	}
+
+	return nil
+}
+
+type paillierMultiSecKeysJSON struct {
+	serial.Header
+	Msk data.Matrix `json:"msk"`
+	Mpk data.Matrix `json:"mpk"`
+	Otp data.Matrix `json:"otp"`
+}
+
+// MarshalJSON encodes the secret keys as a JSON object.
+func (k *PaillierMultiSecKeys) MarshalJSON() ([]byte, error) {
+	return json.Marshal(paillierMultiSecKeysJSON{
+		Header: serial.NewHeader("fullysec.PaillierMultiSecKeys"),
+		Msk:    k.Msk,
+		Mpk:    k.Mpk,
+		Otp:    k.Otp,
+	})
+}
+
+// UnmarshalJSON decodes the secret keys encoded with MarshalJSON into k.
+func (k *PaillierMultiSecKeys) UnmarshalJSON(b []byte) error {
+	var enc paillierMultiSecKeysJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.PaillierMultiSecKeys"); err != nil {
+		return err
+	}
+	*k = PaillierMultiSecKeys{
+		Msk: enc.Msk,
+		Mpk: enc.Mpk,
+		Otp: enc.Otp,
+	}
+
+	return nil
+}
+
+type paillierMultiDerivedKeyJSON struct {
+	serial.Header
+	Keys data.Vector    `json:"keys"`
+	Z    *serial.BigInt `json:"z"`
+}
+
+// MarshalJSON encodes the derived key as a JSON object.
+func (k *PaillierMultiDerivedKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(paillierMultiDerivedKeyJSON{
+		Header: serial.NewHeader("fullysec.PaillierMultiDerivedKey"),
+		Keys:   data.Vector(k.Keys),
+		Z:      (*serial.BigInt)(k.Z),
+	})
+}
+
+// UnmarshalJSON decodes the derived key encoded with MarshalJSON into k.
+func (k *PaillierMultiDerivedKey) UnmarshalJSON(b []byte) error {
+	var enc paillierMultiDerivedKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.PaillierMultiDerivedKey"); err != nil {
+		return err
+	}
+	*k = PaillierMultiDerivedKey{
+		Keys: []*big.Int(enc.Keys),
+		Z:    (*big.Int)(enc.Z),
+	}
+
+	return nil
+}
+
+type fhipeSecKeyJSON struct {
+	serial.Header
+	G1    *serial.G1  `json:"g1"`
+	G2    *serial.G2  `json:"g2"`
+	B     data.Matrix `json:"b"`
+	BStar data.Matrix `json:"bStar"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *FHIPESecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fhipeSecKeyJSON{
+		Header: serial.NewHeader("fullysec.FHIPESecKey"),
+		G1:     (*serial.G1)(k.G1),
+		G2:     (*serial.G2)(k.G2),
+		B:      k.B,
+		BStar:  k.BStar,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *FHIPESecKey) UnmarshalJSON(b []byte) error {
+	var enc fhipeSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.FHIPESecKey"); err != nil {
+		return err
+	}
+	*k = FHIPESecKey{
+		G1:    (*bn256.G1)(enc.G1),
+		G2:    (*bn256.G2)(enc.G2),
+		B:     enc.B,
+		BStar: enc.BStar,
+	}
+
+	return nil
+}
+
+type fhipeDerivedKeyJSON struct {
+	serial.Header
+	K1 *serial.G1    `json:"k1"`
+	K2 data.VectorG1 `json:"k2"`
+}
+
+// MarshalJSON encodes the derived key as a JSON object.
+func (k *FHIPEDerivedKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fhipeDerivedKeyJSON{
+		Header: serial.NewHeader("fullysec.FHIPEDerivedKey"),
+		K1:     (*serial.G1)(k.K1),
+		K2:     k.K2,
+	})
+}
+
+// UnmarshalJSON decodes the derived key encoded with MarshalJSON into k.
+func (k *FHIPEDerivedKey) UnmarshalJSON(b []byte) error {
+	var enc fhipeDerivedKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.FHIPEDerivedKey"); err != nil {
+		return err
+	}
+	*k = FHIPEDerivedKey{
+		K1: (*bn256.G1)(enc.K1),
+		K2: enc.K2,
+	}
+
+	return nil
+}
+
+type fhipeCipherJSON struct {
+	serial.Header
+	C1 *serial.G2    `json:"c1"`
+	C2 data.VectorG2 `json:"c2"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *FHIPECipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fhipeCipherJSON{
+		Header: serial.NewHeader("fullysec.FHIPECipher"),
+		C1:     (*serial.G2)(c.C1),
+		C2:     c.C2,
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *FHIPECipher) UnmarshalJSON(b []byte) error {
+	var enc fhipeCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.FHIPECipher"); err != nil {
+		return err
+	}
+	*c = FHIPECipher{
+		C1: (*bn256.G2)(enc.C1),
+		C2: enc.C2,
+	}
+
+	return nil
+}
+
+type fhMultiIPESecKeyJSON struct {
+	serial.Header
+	BHat     []data.Matrix `json:"bHat"`
+	BStarHat []data.Matrix `json:"bStarHat"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *FHMultiIPESecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fhMultiIPESecKeyJSON{
+		Header:   serial.NewHeader("fullysec.FHMultiIPESecKey"),
+		BHat:     k.BHat,
+		BStarHat: k.BStarHat,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *FHMultiIPESecKey) UnmarshalJSON(b []byte) error {
+	var enc fhMultiIPESecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.FHMultiIPESecKey"); err != nil {
+		return err
+	}
+	*k = FHMultiIPESecKey{
+		BHat:     enc.BHat,
+		BStarHat: enc.BStarHat,
+	}
+
+	return nil
+}
+
+type partFHIPESecKeyJSON struct {
+	serial.Header
+	B data.Vector `json:"b"`
+	V data.Matrix `json:"v"`
+	U data.Matrix `json:"u"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *PartFHIPESecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(partFHIPESecKeyJSON{
+		Header: serial.NewHeader("fullysec.PartFHIPESecKey"),
+		B:      k.B,
+		V:      k.V,
+		U:      k.U,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *PartFHIPESecKey) UnmarshalJSON(b []byte) error {
+	var enc partFHIPESecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.PartFHIPESecKey"); err != nil {
+		return err
+	}
+	*k = PartFHIPESecKey{
+		B: enc.B,
+		V: enc.V,
+		U: enc.U,
+	}
+
+	return nil
+}
+
+type partFHIPEPubKeyJSON struct {
+	serial.Header
+	A   data.VectorG1 `json:"a"`
+	Ua  data.VectorG1 `json:"ua"`
+	VtM data.MatrixG1 `json:"vtM"`
+	M   data.Matrix   `json:"m"`
+	MG1 data.MatrixG1 `json:"mg1"`
+}
+
+// MarshalJSON encodes the public key as a JSON object.
+func (k *PartFHIPEPubKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(partFHIPEPubKeyJSON{
+		Header: serial.NewHeader("fullysec.PartFHIPEPubKey"),
+		A:      k.A,
+		Ua:     k.Ua,
+		VtM:    k.VtM,
+		M:      k.M,
+		MG1:    k.MG1,
+	})
+}
+
+// UnmarshalJSON decodes the public key encoded with MarshalJSON into k.
+func (k *PartFHIPEPubKey) UnmarshalJSON(b []byte) error {
+	var enc partFHIPEPubKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("fullysec.PartFHIPEPubKey"); err != nil {
+		return err
+	}
+	*k = PartFHIPEPubKey{
+		A:   enc.A,
+		Ua:  enc.Ua,
+		VtM: enc.VtM,
+		M:   enc.M,
+		MG1: enc.MG1,
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package fullysec_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod/fullysec"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFHIPE_JSON(t *testing.T) {
+	l := 4
+	bound := big.NewInt(64)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+
+	fhipe, err := fullysec.NewFHIPE(l, bound, bound)
+	if err != nil {
+		t.Fatalf("Error during scheme creation: %v", err)
+	}
+	masterSecKey, err := fhipe.GenerateMasterKey()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	x, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	y, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+
+	secKeyJSON, err := json.Marshal(masterSecKey)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var secKey fullysec.FHIPESecKey
+	if err := json.Unmarshal(secKeyJSON, &secKey); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	assert.Equal(t, masterSecKey.B, secKey.B)
+	assert.Equal(t, masterSecKey.BStar, secKey.BStar)
+
+	ciphertext, err := fhipe.Encrypt(x, &secKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	key, err := fhipe.DeriveKey(y, &secKey)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+
+	cipherJSON, err := json.Marshal(ciphertext)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var decCipher fullysec.FHIPECipher
+	if err := json.Unmarshal(cipherJSON, &decCipher); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	var decKey fullysec.FHIPEDerivedKey
+	if err := json.Unmarshal(keyJSON, &decKey); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+
+	xy, err := fhipe.Decrypt(&decCipher, &decKey)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	xyCheck, err := x.Dot(y)
+	if err != nil {
+		t.Fatalf("Error during inner product calculation: %v", err)
+	}
+	assert.Equal(t, xyCheck, xy)
+
+	// a ciphertext cannot be decoded as a derived key
+	assert.Error(t, json.Unmarshal(cipherJSON, &decKey))
+}
+
+func TestDamgardMulti_JSON(t *testing.T) {
+	damgardMulti, err := fullysec.NewDamgardMulti(2, 2, 512, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("Error during scheme creation: %v", err)
+	}
+	secKeys, err := damgardMulti.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	y := data.NewConstantMatrix(2, 2, big.NewInt(3))
+	key, err := damgardMulti.DeriveKey(secKeys, y)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+
+	secKeysJSON, err := json.Marshal(secKeys)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var decSecKeys fullysec.DamgardMultiSecKeys
+	if err := json.Unmarshal(secKeysJSON, &decSecKeys); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	// small values, such as the one-time pad keys, can be zero,
+	// whose internal representation changes when decoded
+	secKeysJSONCheck, err := json.Marshal(&decSecKeys)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	assert.Equal(t, secKeysJSON, secKeysJSONCheck)
+
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var decKey fullysec.DamgardMultiDerivedKey
+	if err := json.Unmarshal(keyJSON, &decKey); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	keyJSONCheck, err := json.Marshal(&decKey)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	assert.Equal(t, keyJSON, keyJSONCheck)
+}
@@ -85,9 +85,29 @@ type LWE struct {
 // It returns an error in case public parameters of the scheme could
 // not be generated.
 func NewLWE(l, n int, boundX, boundY *big.Int) (*LWE, error) {
-	// K = 2 * l * boundX * boundY
+	params, err := NewLWEParams(l, n, boundX, boundY)
+	if err != nil {
+		return nil, err
+	}
+
+	params.A, err = data.NewRandomMatrix(params.M, params.N, sample.NewUniform(params.Q))
+	if err != nil {
+		return nil, err
+	}
+
+	return &LWE{Params: params}, nil
+}
+
+// NewLWEParams generates the public parameters of the scheme as
+// NewLWE does, except for the random matrix A, which is left nil.
+// This allows to inspect the parameters, for example to estimate
+// their security, without generating the M*N elements of A.
+func NewLWEParams(l, n int, boundX, boundY *big.Int) (*LWEParams, error) {
+	// K = 2 * l * boundX * boundY + 1, so that all the inner products
+	// in [-l * boundX * boundY, l * boundX * boundY] are distinct modulo K
 	K := new(big.Int).Mul(boundX, boundY)
 	K.Mul(K, big.NewInt(int64(l*2)))
+	K.Add(K, big.NewInt(1))
 	kF := new(big.Float).SetInt(K)
 	SquaredF := new(big.Float).Mul(kF, kF)
 
@@ -184,27 +204,20 @@ func NewLWE(l, n int, boundX, boundY *big.Int) (*LWE, error) {
 	lSigmaQ, _ := lSigmaQF.Int(nil)
 	sigmaQ.Mul(sample.SigmaCDT, lSigmaQF)
 
-	randMat, err := data.NewRandomMatrix(m, n, sample.NewUniform(q))
-	if err != nil {
-		return nil, err
-	}
-	return &LWE{
-		Params: &LWEParams{
-			L:       l,
-			N:       n,
-			M:       m,
-			BoundX:  boundX,
-			BoundY:  boundY,
-			Q:       q,
-			K:       K,
-			SigmaQ:  sigmaQ,
-			LSigmaQ: lSigmaQ,
-			Sigma1:  sigma1,
-			LSigma1: lSigma1,
-			Sigma2:  sigma2,
-			LSigma2: lSigma2,
-			A:       randMat,
-		},
+	return &LWEParams{
+		L:       l,
+		N:       n,
+		M:       m,
+		BoundX:  boundX,
+		BoundY:  boundY,
+		Q:       q,
+		K:       K,
+		SigmaQ:  sigmaQ,
+		LSigmaQ: lSigmaQ,
+		Sigma1:  sigma1,
+		LSigma1: lSigma1,
+		Sigma2:  sigma2,
+		LSigma2: lSigma2,
 	}, nil
 }
 
@@ -90,3 +90,51 @@ func testVectorData(len int, boundX, boundY *big.Int) (data.Vector, data.Vector,
 
 	return x, y, xy
 }
+
+func TestFullySec_LWE_Bound(t *testing.T) {
+	l := 4
+	n := 64
+	boundX := big.NewInt(1000)
+	boundY := big.NewInt(1000)
+
+	fsLWE, err := fullysec.NewLWE(l, n, boundX, boundY)
+	assert.NoError(t, err)
+	Z, err := fsLWE.GenerateSecretKey()
+	assert.NoError(t, err)
+	U, err := fsLWE.GeneratePublicKey(Z)
+	assert.NoError(t, err)
+
+	// the inner products -l * boundX * boundY and l * boundX * boundY
+	// must not be equal modulo K, otherwise the centering of the
+	// decrypted value maps one of them to the other
+	x := data.NewConstantVector(l, boundX)
+	for _, c := range []int64{-1, 1} {
+		y := data.NewConstantVector(l, new(big.Int).Mul(boundY, big.NewInt(c)))
+		xy, _ := x.Dot(y)
+		zY, err := fsLWE.DeriveKey(y, Z)
+		assert.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			cipher, err := fsLWE.Encrypt(x, U)
+			assert.NoError(t, err)
+			xyDecrypted, err := fsLWE.Decrypt(cipher, zY, y)
+			assert.NoError(t, err)
+			assert.Equal(t, xy.Cmp(xyDecrypted), 0, "obtained incorrect inner product")
+		}
+	}
+}
+
+func TestFullySec_LWEParams(t *testing.T) {
+	l := 4
+	n := 64
+	b := big.NewInt(100)
+
+	params, err := fullysec.NewLWEParams(l, n, b, b)
+	assert.NoError(t, err)
+	assert.Nil(t, params.A)
+	assert.Equal(t, n, params.N)
+	assert.True(t, params.M > n)
+
+	fsLWE, err := fullysec.NewLWE(l, n, b, b)
+	assert.NoError(t, err)
+	assert.True(t, fsLWE.Params.A.CheckDims(fsLWE.Params.M, n))
+}
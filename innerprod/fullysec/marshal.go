@@ -0,0 +1,598 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fullysec
+
+import (
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *DamgardParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.DamgardParams")
+	e.Int(p.L)
+	e.BigInt(p.Bound)
+	e.BigInt(p.G)
+	e.BigInt(p.H)
+	e.BigInt(p.P)
+	e.BigInt(p.Q)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters encoded with MarshalBinary into p.
+func (p *DamgardParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.DamgardParams")
+	var params DamgardParams
+	params.L = d.Int()
+	params.Bound = d.BigInt()
+	params.G = d.BigInt()
+	params.H = d.BigInt()
+	params.P = d.BigInt()
+	params.Q = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *DamgardSecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.DamgardSecKey")
+	e.Value(k.S, k.S != nil)
+	e.Value(k.T, k.T != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *DamgardSecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.DamgardSecKey")
+	var key DamgardSecKey
+	d.Value(&key.S)
+	d.Value(&key.T)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the derived key into a canonical binary form.
+func (k *DamgardDerivedKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.DamgardDerivedKey")
+	e.BigInt(k.Key1)
+	e.BigInt(k.Key2)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the derived key encoded with MarshalBinary into k.
+func (k *DamgardDerivedKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.DamgardDerivedKey")
+	var key DamgardDerivedKey
+	key.Key1 = d.BigInt()
+	key.Key2 = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the secret keys into a canonical binary form.
+func (k *DamgardMultiSecKeys) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.DamgardMultiSecKeys")
+	e.Len(len(k.Msk))
+	for _, sk := range k.Msk {
+		e.Value(sk, sk != nil)
+	}
+	e.Value(k.Mpk, k.Mpk != nil)
+	e.Value(k.Otp, k.Otp != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret keys encoded with MarshalBinary into k.
+func (k *DamgardMultiSecKeys) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.DamgardMultiSecKeys")
+	var key DamgardMultiSecKeys
+	n := d.Len()
+	key.Msk = make([]*DamgardSecKey, 0)
+	for i := 0; i < n && !d.Failed(); i++ {
+		sk := new(DamgardSecKey)
+		if !d.Value(sk) {
+			sk = nil
+		}
+		key.Msk = append(key.Msk, sk)
+	}
+	d.Value(&key.Mpk)
+	d.Value(&key.Otp)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the derived key into a canonical binary form.
+func (k *DamgardMultiDerivedKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.DamgardMultiDerivedKey")
+	e.Len(len(k.Keys))
+	for _, dk := range k.Keys {
+		e.Value(dk, dk != nil)
+	}
+	e.BigInt(k.Z)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the derived key encoded with MarshalBinary into k.
+func (k *DamgardMultiDerivedKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.DamgardMultiDerivedKey")
+	var key DamgardMultiDerivedKey
+	n := d.Len()
+	key.Keys = make([]*DamgardDerivedKey, 0)
+	for i := 0; i < n && !d.Failed(); i++ {
+		dk := new(DamgardDerivedKey)
+		if !d.Value(dk) {
+			dk = nil
+		}
+		key.Keys = append(key.Keys, dk)
+	}
+	key.Z = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+// Besides the OTP key, the encoding also includes the underlying
+// Damgard key pair of the client.
+func (k *DamgardDecMultiSecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.DamgardDecMultiSecKey")
+	e.Value(k.sk, k.sk != nil)
+	e.Value(k.pk, k.pk != nil)
+	e.Value(k.OtpKey, k.OtpKey != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *DamgardDecMultiSecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.DamgardDecMultiSecKey")
+	var key DamgardDecMultiSecKey
+	key.sk = new(DamgardSecKey)
+	if !d.Value(key.sk) {
+		key.sk = nil
+	}
+	d.Value(&key.pk)
+	d.Value(&key.OtpKey)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the derived key part into a canonical binary form.
+func (k *DamgardDecMultiDerivedKeyPart) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.DamgardDecMultiDerivedKeyPart")
+	e.Value(k.KeyPart, k.KeyPart != nil)
+	e.BigInt(k.OTPKeyPart)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the derived key part encoded with MarshalBinary into k.
+func (k *DamgardDecMultiDerivedKeyPart) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.DamgardDecMultiDerivedKeyPart")
+	var key DamgardDecMultiDerivedKeyPart
+	key.KeyPart = new(DamgardDerivedKey)
+	if !d.Value(key.KeyPart) {
+		key.KeyPart = nil
+	}
+	key.OTPKeyPart = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *PaillierParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.PaillierParams")
+	e.Int(p.L)
+	e.BigInt(p.N)
+	e.BigInt(p.NSquare)
+	e.BigInt(p.BoundX)
+	e.BigInt(p.BoundY)
+	e.BigFloat(p.Sigma)
+	e.BigInt(p.LSigma)
+	e.Int(p.Lambda)
+	e.BigInt(p.G)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters encoded with MarshalBinary into p.
+func (p *PaillierParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.PaillierParams")
+	var params PaillierParams
+	params.L = d.Int()
+	params.N = d.BigInt()
+	params.NSquare = d.BigInt()
+	params.BoundX = d.BigInt()
+	params.BoundY = d.BigInt()
+	params.Sigma = d.BigFloat()
+	params.LSigma = d.BigInt()
+	params.Lambda = d.Int()
+	params.G = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the secret keys into a canonical binary form.
+func (k *PaillierMultiSecKeys) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.PaillierMultiSecKeys")
+	e.Value(k.Msk, k.Msk != nil)
+	e.Value(k.Mpk, k.Mpk != nil)
+	e.Value(k.Otp, k.Otp != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret keys encoded with MarshalBinary into k.
+func (k *PaillierMultiSecKeys) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.PaillierMultiSecKeys")
+	var key PaillierMultiSecKeys
+	d.Value(&key.Msk)
+	d.Value(&key.Mpk)
+	d.Value(&key.Otp)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the derived key into a canonical binary form.
+func (k *PaillierMultiDerivedKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.PaillierMultiDerivedKey")
+	e.BigInts(k.Keys)
+	e.BigInt(k.Z)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the derived key encoded with MarshalBinary into k.
+func (k *PaillierMultiDerivedKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.PaillierMultiDerivedKey")
+	var key PaillierMultiDerivedKey
+	key.Keys = d.BigInts()
+	key.Z = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *LWEParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.LWEParams")
+	e.Int(p.L)
+	e.Int(p.N)
+	e.Int(p.M)
+	e.BigInt(p.BoundX)
+	e.BigInt(p.BoundY)
+	e.BigInt(p.K)
+	e.BigInt(p.Q)
+	e.BigFloat(p.SigmaQ)
+	e.BigInt(p.LSigmaQ)
+	e.BigFloat(p.Sigma1)
+	e.BigInt(p.LSigma1)
+	e.BigFloat(p.Sigma2)
+	e.BigInt(p.LSigma2)
+	e.Value(p.A, p.A != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters encoded with MarshalBinary into p.
+func (p *LWEParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.LWEParams")
+	var params LWEParams
+	params.L = d.Int()
+	params.N = d.Int()
+	params.M = d.Int()
+	params.BoundX = d.BigInt()
+	params.BoundY = d.BigInt()
+	params.K = d.BigInt()
+	params.Q = d.BigInt()
+	params.SigmaQ = d.BigFloat()
+	params.LSigmaQ = d.BigInt()
+	params.Sigma1 = d.BigFloat()
+	params.LSigma1 = d.BigInt()
+	params.Sigma2 = d.BigFloat()
+	params.LSigma2 = d.BigInt()
+	d.Value(&params.A)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *FHIPEParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.FHIPEParams")
+	e.Int(p.L)
+	e.BigInt(p.BoundX)
+	e.BigInt(p.BoundY)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters encoded with MarshalBinary into p.
+func (p *FHIPEParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.FHIPEParams")
+	var params FHIPEParams
+	params.L = d.Int()
+	params.BoundX = d.BigInt()
+	params.BoundY = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *FHIPESecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.FHIPESecKey")
+	e.G1(k.G1)
+	e.G2(k.G2)
+	e.Value(k.B, k.B != nil)
+	e.Value(k.BStar, k.BStar != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *FHIPESecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.FHIPESecKey")
+	var key FHIPESecKey
+	key.G1 = d.G1()
+	key.G2 = d.G2()
+	d.Value(&key.B)
+	d.Value(&key.BStar)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the derived key into a canonical binary form.
+func (k *FHIPEDerivedKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.FHIPEDerivedKey")
+	e.G1(k.K1)
+	e.Value(k.K2, k.K2 != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the derived key encoded with MarshalBinary into k.
+func (k *FHIPEDerivedKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.FHIPEDerivedKey")
+	var key FHIPEDerivedKey
+	key.K1 = d.G1()
+	d.Value(&key.K2)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *FHIPECipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.FHIPECipher")
+	e.G2(c.C1)
+	e.Value(c.C2, c.C2 != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *FHIPECipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.FHIPECipher")
+	var cipher FHIPECipher
+	cipher.C1 = d.G2()
+	d.Value(&cipher.C2)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *FHMultiIPEParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.FHMultiIPEParams")
+	e.Int(p.SecLevel)
+	e.Int(p.NumClients)
+	e.Int(p.VecLen)
+	e.BigInt(p.BoundX)
+	e.BigInt(p.BoundY)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters encoded with MarshalBinary into p.
+func (p *FHMultiIPEParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.FHMultiIPEParams")
+	var params FHMultiIPEParams
+	params.SecLevel = d.Int()
+	params.NumClients = d.Int()
+	params.VecLen = d.Int()
+	params.BoundX = d.BigInt()
+	params.BoundY = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *FHMultiIPESecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.FHMultiIPESecKey")
+	e.Len(len(k.BHat))
+	for _, m := range k.BHat {
+		e.Value(m, m != nil)
+	}
+	e.Len(len(k.BStarHat))
+	for _, m := range k.BStarHat {
+		e.Value(m, m != nil)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *FHMultiIPESecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.FHMultiIPESecKey")
+	var key FHMultiIPESecKey
+	n := d.Len()
+	key.BHat = make([]data.Matrix, n)
+	for i := 0; i < n && !d.Failed(); i++ {
+		d.Value(&key.BHat[i])
+	}
+	n = d.Len()
+	key.BStarHat = make([]data.Matrix, n)
+	for i := 0; i < n && !d.Failed(); i++ {
+		d.Value(&key.BStarHat[i])
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *PartFHIPEParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.PartFHIPEParams")
+	e.Int(p.L)
+	e.BigInt(p.Bound)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters encoded with MarshalBinary into p.
+func (p *PartFHIPEParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.PartFHIPEParams")
+	var params PartFHIPEParams
+	params.L = d.Int()
+	params.Bound = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *PartFHIPESecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.PartFHIPESecKey")
+	e.Value(k.B, k.B != nil)
+	e.Value(k.V, k.V != nil)
+	e.Value(k.U, k.U != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *PartFHIPESecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.PartFHIPESecKey")
+	var key PartFHIPESecKey
+	d.Value(&key.B)
+	d.Value(&key.V)
+	d.Value(&key.U)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the public key into a canonical binary form.
+func (k *PartFHIPEPubKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("fullysec.PartFHIPEPubKey")
+	e.Value(k.A, k.A != nil)
+	e.Value(k.Ua, k.Ua != nil)
+	e.Value(k.VtM, k.VtM != nil)
+	e.Value(k.M, k.M != nil)
+	e.Value(k.MG1, k.MG1 != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the public key encoded with MarshalBinary into k.
+func (k *PartFHIPEPubKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "fullysec.PartFHIPEPubKey")
+	var key PartFHIPEPubKey
+	d.Value(&key.A)
+	d.Value(&key.Ua)
+	d.Value(&key.VtM)
+	d.Value(&key.M)
+	d.Value(&key.MG1)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package fullysec_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod/fullysec"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDamgard_MarshalBinary(t *testing.T) {
+	l := 3
+	bound := big.NewInt(1024)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+
+	damgard, err := fullysec.NewDamgard(l, 512, bound)
+	if err != nil {
+		t.Fatalf("Error during scheme creation: %v", err)
+	}
+	masterSecKey, masterPubKey, err := damgard.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	y, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+
+	paramsBytes, err := damgard.Params.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	params := new(fullysec.DamgardParams)
+	if err := params.UnmarshalBinary(paramsBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	assert.Equal(t, damgard.Params, params)
+
+	secKeyBytes, err := masterSecKey.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	secKey := new(fullysec.DamgardSecKey)
+	if err := secKey.UnmarshalBinary(secKeyBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	assert.Equal(t, masterSecKey, secKey)
+
+	// derive the key with the decoded parameters and secret key
+	// and send it to the decryptor
+	decoded := fullysec.NewDamgardFromParams(params)
+	key, err := decoded.DeriveKey(secKey, y)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+	keyBytes, err := key.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	funcKey := new(fullysec.DamgardDerivedKey)
+	if err := funcKey.UnmarshalBinary(keyBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+
+	x, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	ciphertext, err := damgard.Encrypt(x, masterPubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	xy, err := decoded.Decrypt(ciphertext, funcKey, y)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	xyCheck, err := x.Dot(y)
+	if err != nil {
+		t.Fatalf("Error during inner product calculation: %v", err)
+	}
+	assert.Equal(t, xyCheck, xy)
+}
+
+func TestDamgardMulti_MarshalBinary(t *testing.T) {
+	damgardMulti, err := fullysec.NewDamgardMulti(3, 2, 512, big.NewInt(1024))
+	if err != nil {
+		t.Fatalf("Error during scheme creation: %v", err)
+	}
+	secKeys, err := damgardMulti.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+
+	secKeysBytes, err := secKeys.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	decoded := new(fullysec.DamgardMultiSecKeys)
+	if err := decoded.UnmarshalBinary(secKeysBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	// the one-time pad keys can be zero, whose internal
+	// representation changes when decoded
+	checkBytes, err := decoded.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	assert.Equal(t, secKeysBytes, checkBytes)
+
+	client, err := fullysec.NewDamgardDecMultiClient(0, damgardMulti)
+	if err != nil {
+		t.Fatalf("Error during client creation: %v", err)
+	}
+	if err := client.SetShare([]*big.Int{client.ClientPubKey}); err != nil {
+		t.Fatalf("Error during share generation: %v", err)
+	}
+	secKey, err := client.GenerateKeys()
+	if err != nil {
+		t.Fatalf("Error during key generation: %v", err)
+	}
+	secKeyBytes, err := secKey.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	decodedSecKey := new(fullysec.DamgardDecMultiSecKey)
+	if err := decodedSecKey.UnmarshalBinary(secKeyBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	assert.Equal(t, secKey, decodedSecKey)
+}
+
+func TestFHIPE_MarshalBinary(t *testing.T) {
+	l := 5
+	bound := big.NewInt(128)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+
+	fhipe, err := fullysec.NewFHIPE(l, bound, bound)
+	if err != nil {
+		t.Fatalf("Error during scheme creation: %v", err)
+	}
+	masterSecKey, err := fhipe.GenerateMasterKey()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	x, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	y, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	ciphertext, err := fhipe.Encrypt(x, masterSecKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	key, err := fhipe.DeriveKey(y, masterSecKey)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+
+	paramsBytes, err := fhipe.Params.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	cipherBytes, err := ciphertext.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	keyBytes, err := key.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+
+	params := new(fullysec.FHIPEParams)
+	if err := params.UnmarshalBinary(paramsBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	decodedCipher := new(fullysec.FHIPECipher)
+	if err := decodedCipher.UnmarshalBinary(cipherBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	decodedKey := new(fullysec.FHIPEDerivedKey)
+	if err := decodedKey.UnmarshalBinary(keyBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+
+	// the encoding is canonical
+	checkBytes, err := decodedCipher.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	assert.Equal(t, cipherBytes, checkBytes)
+
+	decryptor := fullysec.NewFHIPEFromParams(params)
+	xy, err := decryptor.Decrypt(decodedCipher, decodedKey)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	xyCheck, err := x.Dot(y)
+	if err != nil {
+		t.Fatalf("Error during inner product calculation: %v", err)
+	}
+	assert.Equal(t, xyCheck, xy)
+}
@@ -17,6 +17,7 @@
 package fullysec
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -303,6 +304,12 @@ func (d *PartFHIPE) PartDecrypt(cipher data.VectorG1, feKey data.VectorG2) (*bn2
 // Decrypt accepts the encrypted vector and functional encryption key.
 // It returns the inner product of x and y. If decryption failed, error is returned.
 func (d *PartFHIPE) Decrypt(cipher data.VectorG1, feKey data.VectorG2) (*big.Int, error) {
+	return d.DecryptContext(context.Background(), cipher, feKey)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (d *PartFHIPE) DecryptContext(ctx context.Context, cipher data.VectorG1, feKey data.VectorG2) (*big.Int, error) {
 	dec, err := d.PartDecrypt(cipher, feKey)
 	if err != nil {
 		return nil, err
@@ -315,7 +322,7 @@ func (d *PartFHIPE) Decrypt(cipher data.VectorG1, feKey data.VectorG2) (*big.Int
 		calc = calc.WithBound(bound)
 	}
 
-	res, err := calc.BabyStepGiantStep(dec, new(bn256.GT).ScalarBaseMult(big.NewInt(1)))
+	res, err := calc.BabyStepGiantStepContext(ctx, dec, new(bn256.GT).ScalarBaseMult(big.NewInt(1)))
 
 	return res, err
 }
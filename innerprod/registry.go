@@ -0,0 +1,446 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package innerprod
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod/fullysec"
+	"github.com/fentec-project/gofe/innerprod/simple"
+	"github.com/fentec-project/gofe/sample"
+)
+
+// Preset is a named choice of parameters of a scheme, which
+// targets a security level. A preset only needs the length of
+// vectors and the bounds on their coordinates to construct a
+// scheme.
+type Preset struct {
+	Name     string // name of the preset, for example "ddh-128"
+	Scheme   string // name of the scheme, as returned by Scheme.Name
+	SecLevel int    // targeted security level in bits
+
+	// New constructs the scheme for vectors of length l with
+	// coordinates bounded by boundX and boundY. It returns the
+	// scheme and its estimated security level in bits, or an
+	// error if the bounds do not fit the parameters of the scheme.
+	New func(l int, boundX, boundY *big.Int) (Scheme, int, error)
+}
+
+// Instance is a Scheme constructed from a preset.
+type Instance struct {
+	Scheme
+	Preset   string // name of the preset
+	SecLevel int    // estimated security level in bits
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Preset)
+)
+
+// Register adds preset p to the registry. It returns an error if
+// a preset with the same name is already registered.
+func Register(p Preset) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if p.New == nil {
+		return fmt.Errorf("preset %q has no constructor", p.Name)
+	}
+	if _, ok := registry[p.Name]; ok {
+		return fmt.Errorf("preset %q is already registered", p.Name)
+	}
+	registry[p.Name] = p
+
+	return nil
+}
+
+// Lookup returns the preset with the given name.
+func Lookup(name string) (Preset, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[name]
+
+	return p, ok
+}
+
+// Presets returns the names of all the registered presets,
+// sorted alphabetically.
+func Presets() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// New constructs a scheme from the preset with the given name,
+// for vectors of length l with coordinates bounded by boundX and
+// boundY. It returns an error if there is no such preset, or if the
+// bounds do not fit the parameters of the preset.
+func New(preset string, l int, boundX, boundY *big.Int) (*Instance, error) {
+	p, ok := Lookup(preset)
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q", preset)
+	}
+	if l <= 0 {
+		return nil, fmt.Errorf("preset %s: vector length should be positive", preset)
+	}
+	if boundX == nil || boundY == nil || boundX.Sign() <= 0 || boundY.Sign() <= 0 {
+		return nil, fmt.Errorf("preset %s: bounds should be positive", preset)
+	}
+	s, secLevel, err := p.New(l, boundX, boundY)
+	if err != nil {
+		return nil, fmt.Errorf("preset %s: %v", preset, err)
+	}
+
+	return &Instance{Scheme: s, Preset: preset, SecLevel: secLevel}, nil
+}
+
+func init() {
+	// the moduli of the schemes based on the discrete logarithm
+	// and factorization follow the recommendations of NIST SP 800-57,
+	// the security levels are estimated from the generated moduli
+	for _, c := range []struct {
+		secLevel      int
+		modulusLength int
+	}{{112, 2048}, {128, 3072}} {
+		modulusLength, secLevel := c.modulusLength, c.secLevel
+		mustRegister(Preset{
+			Name:     fmt.Sprintf("ddh-%d", secLevel),
+			Scheme:   "simple.DDH",
+			SecLevel: secLevel,
+			New: func(l int, boundX, boundY *big.Int) (Scheme, int, error) {
+				s, err := simple.NewDDHPrecomp(l, modulusLength, maxBound(boundX, boundY))
+				if err != nil {
+					return nil, 0, err
+				}
+				return FromDDH(s), estimateModulus(s.Params.P.BitLen()), nil
+			},
+		})
+		mustRegister(Preset{
+			Name:     fmt.Sprintf("damgard-%d", secLevel),
+			Scheme:   "fullysec.Damgard",
+			SecLevel: secLevel,
+			New: func(l int, boundX, boundY *big.Int) (Scheme, int, error) {
+				s, err := fullysec.NewDamgardPrecomp(l, modulusLength, maxBound(boundX, boundY))
+				if err != nil {
+					return nil, 0, err
+				}
+				return FromDamgard(s), estimateModulus(s.Params.P.BitLen()), nil
+			},
+		})
+		// the paillier presets generate two safe primes of half the
+		// modulus length, i.e. of 1024 or 1536 bits, each time a
+		// scheme is constructed, which can take minutes; to reuse the
+		// parameters, wrap fullysec.NewPaillierFromParams in FromPaillier
+		mustRegister(Preset{
+			Name:     fmt.Sprintf("paillier-%d", secLevel),
+			Scheme:   "fullysec.Paillier",
+			SecLevel: secLevel,
+			New: func(l int, boundX, boundY *big.Int) (Scheme, int, error) {
+				// N is a product of two safe primes of half the
+				// modulus length, hence N >= 2^(modulusLength-2);
+				// the bounds are checked before generating the
+				// primes, which takes long
+				minN := new(big.Int).Lsh(big.NewInt(1), uint(modulusLength-2))
+				if err := checkProductBound(l, boundX, minN); err != nil {
+					return nil, 0, err
+				}
+				if err := checkProductBound(l, boundY, minN); err != nil {
+					return nil, 0, err
+				}
+				s, err := fullysec.NewPaillier(l, secLevel, modulusLength/2, boundX, boundY)
+				if err != nil {
+					return nil, 0, err
+				}
+				// the secret keys are sampled for the security
+				// parameter lambda, which bounds the level as well
+				est := estimateModulus(s.Params.N.BitLen())
+				if s.Params.Lambda < est {
+					est = s.Params.Lambda
+				}
+				return FromPaillier(s), est, nil
+			},
+		})
+	}
+
+	for _, secLevel := range []int{128, 192, 256} {
+		secLevel := secLevel
+		mustRegister(Preset{
+			Name:     fmt.Sprintf("ringlwe-%d", secLevel),
+			Scheme:   "simple.RingLWE",
+			SecLevel: secLevel,
+			New: func(l int, boundX, boundY *big.Int) (Scheme, int, error) {
+				return newRingLWE(l, maxBound(boundX, boundY), secLevel)
+			},
+		})
+		mustRegister(Preset{
+			Name:     fmt.Sprintf("lwe-simple-%d", secLevel),
+			Scheme:   "simple.LWE",
+			SecLevel: secLevel,
+			New: func(l int, boundX, boundY *big.Int) (Scheme, int, error) {
+				var params *simple.LWEParams
+				est, err := searchLWE(l, boundX, boundY, secLevel, func(n int) (int, *big.Int, *big.Float, error) {
+					var err error
+					params, err = simple.NewLWEParams(l, boundX, boundY, n)
+					if err != nil {
+						return 0, nil, nil, err
+					}
+					return params.M, params.Q, params.SigmaQ, nil
+				})
+				if err != nil {
+					return nil, 0, err
+				}
+				params.A, err = data.NewRandomMatrix(params.M, params.N, sample.NewUniform(params.Q))
+				if err != nil {
+					return nil, 0, err
+				}
+				return FromSimpleLWE(&simple.LWE{Params: params}), est, nil
+			},
+		})
+		mustRegister(Preset{
+			Name:     fmt.Sprintf("lwe-fullysec-%d", secLevel),
+			Scheme:   "fullysec.LWE",
+			SecLevel: secLevel,
+			New: func(l int, boundX, boundY *big.Int) (Scheme, int, error) {
+				var params *fullysec.LWEParams
+				est, err := searchLWE(l, boundX, boundY, secLevel, func(n int) (int, *big.Int, *big.Float, error) {
+					var err error
+					params, err = fullysec.NewLWEParams(l, n, boundX, boundY)
+					if err != nil {
+						return 0, nil, nil, err
+					}
+					return params.M, params.Q, params.SigmaQ, nil
+				})
+				if err != nil {
+					return nil, 0, err
+				}
+				params.A, err = data.NewRandomMatrix(params.M, params.N, sample.NewUniform(params.Q))
+				if err != nil {
+					return nil, 0, err
+				}
+				return FromFullySecLWE(&fullysec.LWE{Params: params}), est, nil
+			},
+		})
+	}
+}
+
+func mustRegister(p Preset) {
+	if err := Register(p); err != nil {
+		panic(err)
+	}
+}
+
+func maxBound(boundX, boundY *big.Int) *big.Int {
+	if boundX.Cmp(boundY) > 0 {
+		return boundX
+	}
+	return boundY
+}
+
+// checkProductBound returns an error if 2 * l * bound^2 >= max.
+func checkProductBound(l int, bound, max *big.Int) error {
+	prod := new(big.Int).Mul(bound, bound)
+	prod.Mul(prod, big.NewInt(int64(2*l)))
+	if prod.Cmp(max) >= 0 {
+		return fmt.Errorf("2 * l * bound^2 should be smaller than the modulus")
+	}
+
+	return nil
+}
+
+// nistModulusLevels holds the security levels in bits of the discrete
+// logarithm and factorization problems with moduli of the given bit
+// lengths, as given in NIST SP 800-57.
+var nistModulusLevels = []struct {
+	modulusLength int
+	secLevel      int
+}{
+	{1024, 80}, {2048, 112}, {3072, 128}, {7680, 192}, {15360, 256},
+}
+
+// estimateModulus returns the security level in bits of the discrete
+// logarithm or factorization problem with a modulus of bitLen bits,
+// interpolated linearly between the levels in nistModulusLevels.
+func estimateModulus(bitLen int) int {
+	prevLength, prevLevel := 0, 0
+	for _, c := range nistModulusLevels {
+		if bitLen < c.modulusLength {
+			return prevLevel + (c.secLevel-prevLevel)*(bitLen-prevLength)/(c.modulusLength-prevLength)
+		}
+		prevLength, prevLevel = c.modulusLength, c.secLevel
+	}
+
+	return prevLevel
+}
+
+// ringLWESigma is the standard deviation of the noise in the
+// RingLWE presets; the security estimates below assume it.
+const ringLWESigma = 3.2
+
+// ringLWEMaxLogQ holds the maximal bit lengths of modulus q, for
+// which the ring LWE problem in the ring of degree n is estimated to
+// achieve 128, 192 or 256 bits of security, as given in the
+// Homomorphic Encryption Security Standard (2018) for a noise with
+// standard deviation 3.2.
+var ringLWEMaxLogQ = []struct {
+	n       int
+	maxLogQ map[int]int
+}{
+	{1024, map[int]int{128: 27, 192: 19, 256: 14}},
+	{2048, map[int]int{128: 54, 192: 37, 256: 29}},
+	{4096, map[int]int{128: 109, 192: 75, 256: 58}},
+	{8192, map[int]int{128: 218, 192: 152, 256: 118}},
+	{16384, map[int]int{128: 438, 192: 305, 256: 237}},
+	{32768, map[int]int{128: 881, 192: 611, 256: 476}},
+}
+
+// newRingLWE constructs a RingLWE scheme for vectors of length l
+// with coordinates bounded by bound, that achieves the given security
+// level. The modulus p is chosen as small as possible, the modulus
+// q big enough for the decryption to be correct, and the degree n as
+// the smallest one for which q is small enough for the security level.
+func newRingLWE(l int, bound *big.Int, secLevel int) (Scheme, int, error) {
+	// p >= 2 * l * bound^2
+	p := new(big.Int).Mul(bound, bound)
+	p.Mul(p, big.NewInt(int64(2*l)))
+	p.Add(p, big.NewInt(1))
+
+	for _, c := range ringLWEMaxLogQ {
+		// the noise in a coordinate of the decrypted vector is the sum
+		// of l products of a coordinate of y with a noise term
+		// bounded by 2 * n * (6 * sigma)^2 + 6 * sigma, while flooring
+		// the message adds at most l * bound; decryption is correct if
+		// p/q times the noise is smaller than 1/2
+		tail := 6 * ringLWESigma
+		term := big.NewInt(int64(math.Ceil(2*float64(c.n)*tail*tail + tail + 1)))
+		noise := new(big.Int).Mul(term, bound)
+		noise.Mul(noise, big.NewInt(int64(l)))
+		q := new(big.Int).Mul(noise, p)
+		q.Lsh(q, 1)
+		q.Add(q, big.NewInt(1))
+
+		if q.BitLen() > c.maxLogQ[secLevel] {
+			continue
+		}
+		s, err := simple.NewRingLWE(l, c.n, bound, p, q, big.NewFloat(ringLWESigma))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return FromRingLWE(s), estimateRingLWE(c.n, q.BitLen()), nil
+	}
+
+	return nil, 0, fmt.Errorf("bounds too big for the security level")
+}
+
+// estimateRingLWE returns the highest security level that the
+// ring LWE problem with degree n and modulus of logQ bits achieves
+// according to ringLWEMaxLogQ.
+func estimateRingLWE(n, logQ int) int {
+	for _, c := range ringLWEMaxLogQ {
+		if c.n != n {
+			continue
+		}
+		for _, secLevel := range []int{256, 192, 128} {
+			if logQ <= c.maxLogQ[secLevel] {
+				return secLevel
+			}
+		}
+	}
+
+	return 0
+}
+
+// lweMaxBound bounds 2 * l * bound^2 in the LWE presets, so that
+// the moduli stay small enough to be generated quickly; the LWE
+// problem in the dimensions up to lweMaxDim is not hard enough for
+// the presets with bigger moduli anyway.
+var lweMaxBound = new(big.Int).Lsh(big.NewInt(1), 64)
+
+// lweMaxDim is the biggest dimension n of the LWE problem, that the
+// LWE presets choose from the multiples of 256. Note that the public
+// matrix A of an LWE scheme has about n * n * log(q) elements, thus
+// the schemes for high security levels need a lot of memory.
+const lweMaxDim = 8192
+
+// searchLWE finds the smallest dimension n of the LWE problem, for
+// which the parameters generated by gen for vectors of length l with
+// coordinates bounded by boundX and boundY achieve the given security
+// level. Function gen generates the parameters, without the public
+// matrix, for dimension n and returns the number of samples m, the
+// modulus q and the standard deviation of the noise. The parameters
+// from the last call to gen are the chosen ones. It returns their
+// estimated security level.
+func searchLWE(l int, boundX, boundY *big.Int, secLevel int,
+	gen func(n int) (int, *big.Int, *big.Float, error)) (int, error) {
+	if checkProductBound(l, maxBound(boundX, boundY), lweMaxBound) != nil {
+		return 0, fmt.Errorf("bounds too big for the LWE presets")
+	}
+	for n := 256; n <= lweMaxDim; n += 256 {
+		m, q, sigma, err := gen(n)
+		if err != nil {
+			return 0, err
+		}
+		if est := estimateLWE(n, m, q, sigma); est >= secLevel {
+			return est, nil
+		}
+	}
+
+	return 0, fmt.Errorf("bounds too big for the security level")
+}
+
+// estimateLWE returns the security level in bits of the LWE problem
+// of dimension n with modulus q, noise of standard deviation sigma and
+// at most m samples. It is estimated by the core-SVP hardness of the
+// primal attack, that is as 0.292 * b for the smallest block size b of
+// BKZ that recovers the noise, as in Alkim, Ducas, Poppelmann, Schwabe:
+// "Post-quantum key exchange - a new hope".
+func estimateLWE(n, m int, q *big.Int, sigma *big.Float) int {
+	logQ := log2(new(big.Float).SetInt(q))
+	logSigma := log2(sigma)
+	for b := 50; b <= n+m; b++ {
+		bF := float64(b)
+		logDelta := math.Log2(math.Pow(math.Pi*bF, 1/bF)*bF/(2*math.Pi*math.E)) / (2 * (bF - 1))
+		// the number of samples k that is optimal for the attack
+		k := math.Sqrt(float64(n+1)*logQ/logDelta) - float64(n+1)
+		k = math.Max(1, math.Min(k, float64(m)))
+		d := float64(n+1) + k
+		if logSigma+math.Log2(bF)/2 <= (2*bF-d)*logDelta+k/d*logQ {
+			return int(0.292 * bF)
+		}
+	}
+
+	return int(0.292 * float64(n+m))
+}
+
+// log2 returns the binary logarithm of a positive x.
+func log2(x *big.Float) float64 {
+	mant := new(big.Float)
+	exp := x.MantExp(mant)
+	f, _ := mant.Float64()
+
+	return float64(exp) + math.Log2(f)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package innerprod
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/innerprod/fullysec"
+	"github.com/fentec-project/gofe/innerprod/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateModulus(t *testing.T) {
+	for bitLen, secLevel := range map[int]int{512: 40, 1024: 80, 2048: 112,
+		3071: 127, 3072: 128, 7680: 192, 15360: 256, 20000: 256} {
+		assert.Equal(t, secLevel, estimateModulus(bitLen), "modulus of %d bits", bitLen)
+	}
+}
+
+func TestEstimateLWE(t *testing.T) {
+	// the parameters of New Hope, whose primal attack is estimated
+	// to 281 bits of core-SVP hardness in the paper
+	q := big.NewInt(12289)
+	est := estimateLWE(1024, 1024, q, big.NewFloat(math.Sqrt(8)))
+	assert.InDelta(t, 281, est, 5)
+
+	// more noise makes the problem harder
+	assert.True(t, estimateLWE(1024, 1024, q, big.NewFloat(8)) > est)
+	// and so does a bigger dimension
+	assert.True(t, estimateLWE(1280, 1024, q, big.NewFloat(math.Sqrt(8))) > est)
+}
+
+func TestSearchLWE(t *testing.T) {
+	l := 4
+	bound := big.NewInt(100)
+
+	var simpleParams *simple.LWEParams
+	est, err := searchLWE(l, bound, bound, 128, func(n int) (int, *big.Int, *big.Float, error) {
+		var err error
+		simpleParams, err = simple.NewLWEParams(l, bound, bound, n)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		return simpleParams.M, simpleParams.Q, simpleParams.SigmaQ, nil
+	})
+	if err != nil {
+		t.Fatalf("Error during parameters search: %v", err)
+	}
+	assert.True(t, est >= 128)
+	assert.Equal(t, est, estimateLWE(simpleParams.N, simpleParams.M, simpleParams.Q, simpleParams.SigmaQ))
+	// the dimension is not much bigger than needed for the level
+	smaller, err := simple.NewLWEParams(l, bound, bound, simpleParams.N-512)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	assert.True(t, estimateLWE(smaller.N, smaller.M, smaller.Q, smaller.SigmaQ) < 128)
+
+	var fsParams *fullysec.LWEParams
+	est, err = searchLWE(l, bound, bound, 128, func(n int) (int, *big.Int, *big.Float, error) {
+		var err error
+		fsParams, err = fullysec.NewLWEParams(l, n, bound, bound)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		return fsParams.M, fsParams.Q, fsParams.SigmaQ, nil
+	})
+	if err != nil {
+		t.Fatalf("Error during parameters search: %v", err)
+	}
+	assert.True(t, est >= 128)
+	assert.True(t, fsParams.N > simpleParams.N)
+
+	_, err = searchLWE(l, new(big.Int).Lsh(bound, 64), bound, 128, func(n int) (int, *big.Int, *big.Float, error) {
+		t.Fatalf("Parameters generated for bounds that are too big")
+		return 0, nil, nil, nil
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package innerprod_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Presets(t *testing.T) {
+	names := innerprod.Presets()
+	for _, name := range []string{"ddh-112", "ddh-128", "damgard-112", "damgard-128",
+		"paillier-112", "paillier-128", "ringlwe-128", "ringlwe-192", "ringlwe-256",
+		"lwe-simple-128", "lwe-simple-192", "lwe-simple-256",
+		"lwe-fullysec-128", "lwe-fullysec-192", "lwe-fullysec-256"} {
+		assert.Contains(t, names, name)
+		p, ok := innerprod.Lookup(name)
+		assert.True(t, ok)
+		assert.Equal(t, name, p.Name)
+	}
+
+	err := innerprod.Register(innerprod.Preset{
+		Name: "ddh-128",
+		New: func(int, *big.Int, *big.Int) (innerprod.Scheme, int, error) {
+			return nil, 0, nil
+		},
+	})
+	assert.Error(t, err, "registering a preset twice should fail")
+}
+
+func TestRegistry_New(t *testing.T) {
+	l := 4
+	bound := big.NewInt(100)
+
+	for _, preset := range []string{"ddh-112", "damgard-112", "ringlwe-128"} {
+		t.Run(preset, func(t *testing.T) {
+			s, err := innerprod.New(preset, l, bound, bound)
+			if err != nil {
+				t.Fatalf("Error during scheme creation: %v", err)
+			}
+			p, _ := innerprod.Lookup(preset)
+			assert.Equal(t, preset, s.Preset)
+			assert.Equal(t, p.Scheme, s.Name())
+			assert.True(t, s.SecLevel >= p.SecLevel)
+
+			msk, mpk, err := s.GenerateMasterKeys()
+			if err != nil {
+				t.Fatalf("Error during master key generation: %v", err)
+			}
+			x := data.NewConstantVector(l, bound)
+			y := data.NewConstantVector(l, new(big.Int).Neg(bound))
+			testDecrypt(t, s, msk, mpk, x, y)
+		})
+	}
+}
+
+func TestRegistry_NewInvalid(t *testing.T) {
+	bound := big.NewInt(100)
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 2048)
+
+	_, err := innerprod.New("ddh-1", 4, bound, bound)
+	assert.Error(t, err, "unknown preset should be rejected")
+	_, err = innerprod.New("ddh-112", 0, bound, bound)
+	assert.Error(t, err, "empty vectors should be rejected")
+	_, err = innerprod.New("ddh-112", 4, bound, big.NewInt(0))
+	assert.Error(t, err, "non-positive bounds should be rejected")
+
+	for _, preset := range innerprod.Presets() {
+		_, err = innerprod.New(preset, 4, tooBig, bound)
+		assert.Error(t, err, "%s should reject bounds that do not fit the modulus", preset)
+	}
+}
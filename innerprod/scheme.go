@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package innerprod
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/fentec-project/gofe/data"
+)
+
+// Params describes the inputs accepted by a Scheme: the length
+// of the vectors and the bounds on the absolute values of their
+// coordinates.
+type Params struct {
+	L      int      // length of vectors x and y
+	BoundX *big.Int // bound on the coordinates of encrypted vectors x
+	BoundY *big.Int // bound on the coordinates of inner product vectors y
+}
+
+// KeyGenerator is implemented by the party that generates the
+// master keys of a scheme and derives functional encryption keys
+// from the master secret key.
+type KeyGenerator interface {
+	// GenerateMasterKeys generates a master secret key and
+	// a master public key for the scheme.
+	GenerateMasterKeys() (*MasterSecKey, *MasterPubKey, error)
+	// DeriveKey derives a functional encryption key for vector y.
+	DeriveKey(msk *MasterSecKey, y data.Vector) (*DerivedKey, error)
+}
+
+// Encryptor is implemented by the party that encrypts vectors x
+// with the master public key.
+type Encryptor interface {
+	Encrypt(x data.Vector, mpk *MasterPubKey) (*Ciphertext, error)
+}
+
+// Decryptor is implemented by the party that obtains the inner
+// product <x, y> from an encryption of x and a functional
+// encryption key derived for y.
+type Decryptor interface {
+	Decrypt(c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error)
+	// DecryptContext is like Decrypt, but returns the error of ctx
+	// as soon as ctx is done. Schemes that do not compute discrete
+	// logarithms only check ctx before decrypting, as their
+	// decryption does not take long.
+	DecryptContext(ctx context.Context, c *Ciphertext, key *DerivedKey, y data.Vector) (*big.Int, error)
+}
+
+// Scheme is a single input inner product functional encryption
+// scheme. The schemes from the simple and fullysec packages can
+// be used as a Scheme through the adapters provided by this
+// package, for example FromDDH. Keys and ciphertexts are opaque
+// and can only be used with a scheme of the same kind as the
+// one that produced them.
+type Scheme interface {
+	// Name returns the name of the scheme, for example "simple.DDH".
+	Name() string
+	// Params returns the length and the bounds of the vectors
+	// accepted by the scheme.
+	Params() Params
+
+	KeyGenerator
+	Encryptor
+	Decryptor
+}
+
+// MasterSecKey is a master secret key of a Scheme.
+type MasterSecKey struct {
+	scheme string
+	key    interface{}
+}
+
+// Scheme returns the name of the scheme that generated the key.
+func (k *MasterSecKey) Scheme() string {
+	return k.scheme
+}
+
+// Value returns the key in the representation of the underlying
+// scheme, for example data.Vector for simple.DDH.
+func (k *MasterSecKey) Value() interface{} {
+	return k.key
+}
+
+func (k *MasterSecKey) get(scheme string) interface{} {
+	if k == nil || k.scheme != scheme {
+		return nil
+	}
+	return k.key
+}
+
+// MasterPubKey is a master public key of a Scheme.
+type MasterPubKey struct {
+	scheme string
+	key    interface{}
+}
+
+// Scheme returns the name of the scheme that generated the key.
+func (k *MasterPubKey) Scheme() string {
+	return k.scheme
+}
+
+// Value returns the key in the representation of the underlying
+// scheme, for example data.Vector for simple.DDH.
+func (k *MasterPubKey) Value() interface{} {
+	return k.key
+}
+
+func (k *MasterPubKey) get(scheme string) interface{} {
+	if k == nil || k.scheme != scheme {
+		return nil
+	}
+	return k.key
+}
+
+// DerivedKey is a functional encryption key of a Scheme.
+type DerivedKey struct {
+	scheme string
+	key    interface{}
+}
+
+// Scheme returns the name of the scheme that derived the key.
+func (k *DerivedKey) Scheme() string {
+	return k.scheme
+}
+
+// Value returns the key in the representation of the underlying
+// scheme, for example *big.Int for simple.DDH.
+func (k *DerivedKey) Value() interface{} {
+	return k.key
+}
+
+func (k *DerivedKey) get(scheme string) interface{} {
+	if k == nil || k.scheme != scheme {
+		return nil
+	}
+	return k.key
+}
+
+// Ciphertext is an encryption of a vector with a Scheme.
+type Ciphertext struct {
+	scheme string
+	cipher interface{}
+}
+
+// Scheme returns the name of the scheme that produced the ciphertext.
+func (c *Ciphertext) Scheme() string {
+	return c.scheme
+}
+
+// Value returns the ciphertext in the representation of the
+// underlying scheme, for example data.Vector for simple.DDH.
+func (c *Ciphertext) Value() interface{} {
+	return c.cipher
+}
+
+func (c *Ciphertext) get(scheme string) interface{} {
+	if c == nil || c.scheme != scheme {
+		return nil
+	}
+	return c.cipher
+}
+
+// errMismatch is returned when a key or a ciphertext is used with
+// a scheme of a different kind than the one that produced it.
+func errMismatch(what, scheme string) error {
+	return fmt.Errorf("%s was not produced by a %s scheme", what, scheme)
+}
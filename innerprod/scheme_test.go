@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package innerprod_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod"
+	"github.com/fentec-project/gofe/innerprod/fullysec"
+	"github.com/fentec-project/gofe/innerprod/simple"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+// schemes returns an instance of every single input scheme
+// that implements the innerprod.Scheme interface.
+func schemes(t *testing.T) []innerprod.Scheme {
+	l := 4
+	bound := big.NewInt(100)
+
+	ddh, err := simple.NewDDH(l, 512, bound)
+	if err != nil {
+		t.Fatalf("Error during simple.DDH creation: %v", err)
+	}
+	simpleLWE, err := simple.NewLWE(l, bound, bound, 128)
+	if err != nil {
+		t.Fatalf("Error during simple.LWE creation: %v", err)
+	}
+	damgard, err := fullysec.NewDamgard(l, 512, bound)
+	if err != nil {
+		t.Fatalf("Error during fullysec.Damgard creation: %v", err)
+	}
+	paillier, err := fullysec.NewPaillier(l, 128, 512, bound, bound)
+	if err != nil {
+		t.Fatalf("Error during fullysec.Paillier creation: %v", err)
+	}
+	fsLWE, err := fullysec.NewLWE(l, 64, bound, bound)
+	if err != nil {
+		t.Fatalf("Error during fullysec.LWE creation: %v", err)
+	}
+	ringLWE, err := simple.NewRingLWE(l, 128, bound, big.NewInt(80001),
+		new(big.Int).Lsh(big.NewInt(1), 60), big.NewFloat(3.2))
+	if err != nil {
+		t.Fatalf("Error during simple.RingLWE creation: %v", err)
+	}
+
+	return []innerprod.Scheme{
+		innerprod.FromDDH(ddh),
+		innerprod.FromSimpleLWE(simpleLWE),
+		innerprod.FromDamgard(damgard),
+		innerprod.FromPaillier(paillier),
+		innerprod.FromFullySecLWE(fsLWE),
+		innerprod.FromRingLWE(ringLWE),
+	}
+}
+
+// testDecrypt encrypts x, derives a key for y and checks that
+// the decryption yields the inner product of x and y.
+func testDecrypt(t *testing.T, s innerprod.Scheme, msk *innerprod.MasterSecKey,
+	mpk *innerprod.MasterPubKey, x, y data.Vector) {
+	c, err := s.Encrypt(x, mpk)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	key, err := s.DeriveKey(msk, y)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+	xy, err := s.Decrypt(c, key, y)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	xyCheck, err := x.Dot(y)
+	if err != nil {
+		t.Fatalf("Error during inner product calculation: %v", err)
+	}
+	assert.Equal(t, 0, xyCheck.Cmp(xy), "decryption should yield the inner product")
+}
+
+func TestScheme_Conformance(t *testing.T) {
+	for _, s := range schemes(t) {
+		t.Run(s.Name(), func(t *testing.T) {
+			params := s.Params()
+			msk, mpk, err := s.GenerateMasterKeys()
+			if err != nil {
+				t.Fatalf("Error during master key generation: %v", err)
+			}
+			assert.Equal(t, s.Name(), msk.Scheme())
+			assert.Equal(t, s.Name(), mpk.Scheme())
+
+			// random vectors within the bounds
+			samplerX := sample.NewUniformRange(new(big.Int).Neg(params.BoundX), params.BoundX)
+			samplerY := sample.NewUniformRange(new(big.Int).Neg(params.BoundY), params.BoundY)
+			x, err := data.NewRandomVector(params.L, samplerX)
+			if err != nil {
+				t.Fatalf("Error during random generation: %v", err)
+			}
+			y, err := data.NewRandomVector(params.L, samplerY)
+			if err != nil {
+				t.Fatalf("Error during random generation: %v", err)
+			}
+			testDecrypt(t, s, msk, mpk, x, y)
+
+			// vectors on the bounds, so that the inner product
+			// is the smallest possible
+			x = data.NewConstantVector(params.L, params.BoundX)
+			y = data.NewConstantVector(params.L, new(big.Int).Neg(params.BoundY))
+			testDecrypt(t, s, msk, mpk, x, y)
+
+			// zero vectors
+			zero := data.NewConstantVector(params.L, big.NewInt(0))
+			testDecrypt(t, s, msk, mpk, zero, y)
+
+			// vectors out of bounds are rejected
+			tooBig := data.NewConstantVector(params.L, new(big.Int).Add(params.BoundX, big.NewInt(1)))
+			_, err = s.Encrypt(tooBig, mpk)
+			assert.Error(t, err)
+
+			// decryption is aborted when the context is done
+			c, err := s.Encrypt(x, mpk)
+			if err != nil {
+				t.Fatalf("Error during encryption: %v", err)
+			}
+			key, err := s.DeriveKey(msk, y)
+			if err != nil {
+				t.Fatalf("Error during key derivation: %v", err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = s.DecryptContext(ctx, c, key, y)
+			assert.Equal(t, context.Canceled, err)
+
+			// missing keys are rejected
+			_, err = s.Encrypt(x, nil)
+			assert.Error(t, err)
+			_, err = s.DeriveKey(nil, y)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScheme_Mismatch(t *testing.T) {
+	all := schemes(t)
+	for i, s := range all {
+		other := all[(i+1)%len(all)]
+		msk, mpk, err := s.GenerateMasterKeys()
+		if err != nil {
+			t.Fatalf("Error during master key generation: %v", err)
+		}
+		x := data.NewConstantVector(s.Params().L, big.NewInt(1))
+		c, err := s.Encrypt(x, mpk)
+		if err != nil {
+			t.Fatalf("Error during encryption: %v", err)
+		}
+		key, err := s.DeriveKey(msk, x)
+		if err != nil {
+			t.Fatalf("Error during key derivation: %v", err)
+		}
+
+		// keys and ciphertexts cannot be used with another scheme
+		_, err = other.Encrypt(x, mpk)
+		assert.Error(t, err)
+		_, err = other.DeriveKey(msk, x)
+		assert.Error(t, err)
+		_, err = other.Decrypt(c, key, x)
+		assert.Error(t, err)
+	}
+}
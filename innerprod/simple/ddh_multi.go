@@ -17,6 +17,7 @@
 package simple
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -215,13 +216,19 @@ func (dm *DDHMulti) DeriveKey(secKey *DDHMultiSecKey, y data.Matrix) (*DDHMultiD
 // It returns the sum of inner products.
 // If decryption failed, error is returned.
 func (dm *DDHMulti) Decrypt(cipher []data.Vector, key *DDHMultiDerivedKey, y data.Matrix) (*big.Int, error) {
+	return dm.DecryptContext(context.Background(), cipher, key, y)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (dm *DDHMulti) DecryptContext(ctx context.Context, cipher []data.Vector, key *DDHMultiDerivedKey, y data.Matrix) (*big.Int, error) {
 	if err := y.CheckBound(dm.Params.Bound); err != nil {
 		return nil, err
 	}
 
 	sum := big.NewInt(0)
 	for i := 0; i < dm.Slots; i++ {
-		c, err := dm.DDH.Decrypt(cipher[i], key.Keys[i], y[i])
+		c, err := dm.DDH.DecryptContext(ctx, cipher[i], key.Keys[i], y[i])
 		if err != nil {
 			return nil, err
 		}
@@ -17,8 +17,10 @@
 package simple_test
 
 import (
+	"context"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/fentec-project/gofe/data"
 	"github.com/fentec-project/gofe/innerprod/simple"
@@ -105,3 +107,256 @@ func TestSimple_DDH(t *testing.T) {
 		})
 	}
 }
+
+func TestSimple_DDHEncryptBatch(t *testing.T) {
+	l := 3
+	// large enough for the fixed-base tables to be used
+	n := 20
+	bound := big.NewInt(1024)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), new(big.Int).Add(bound, big.NewInt(1)))
+
+	simpleDDH, err := simple.NewDDHPrecomp(l, 2048, bound)
+	if err != nil {
+		t.Fatalf("Error during simple inner product creation: %v", err)
+	}
+	masterSecKey, masterPubKey, err := simpleDDH.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	y, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	funcKey, err := simpleDDH.DeriveKey(masterSecKey, y)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+
+	xs := make([]data.Vector, n)
+	for j := range xs {
+		xs[j], err = data.NewRandomVector(l, sampler)
+		if err != nil {
+			t.Fatalf("Error during random generation: %v", err)
+		}
+	}
+	// coordinates on the bounds
+	xs[0] = data.NewConstantVector(l, bound)
+	xs[1] = data.NewConstantVector(l, new(big.Int).Neg(bound))
+
+	ciphertexts, err := simpleDDH.EncryptBatch(xs, masterPubKey)
+	if err != nil {
+		t.Fatalf("Error during batch encryption: %v", err)
+	}
+	assert.Equal(t, n, len(ciphertexts))
+
+	for j, ciphertext := range ciphertexts {
+		xyCheck, err := xs[j].Dot(y)
+		if err != nil {
+			t.Fatalf("Error during inner product calculation: %v", err)
+		}
+		xy, err := simpleDDH.Decrypt(ciphertext, funcKey, y)
+		if err != nil {
+			t.Fatalf("Error during decryption: %v", err)
+		}
+		assert.Equal(t, 0, xy.Cmp(xyCheck), "Original and decrypted values should match")
+	}
+
+	// a vector out of bounds fails the whole batch
+	xs[n-1] = data.NewConstantVector(l, new(big.Int).Add(bound, big.NewInt(1)))
+	_, err = simpleDDH.EncryptBatch(xs, masterPubKey)
+	assert.Error(t, err)
+	xs[n-1] = data.NewConstantVector(l+1, bound)
+	_, err = simpleDDH.EncryptBatch(xs, masterPubKey)
+	assert.Error(t, err)
+}
+
+func TestSimple_DDHDecryptMany(t *testing.T) {
+	l := 3
+	n := 10
+	bound := big.NewInt(1024)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), new(big.Int).Add(bound, big.NewInt(1)))
+
+	simpleDDH, err := simple.NewDDHPrecomp(l, 2048, bound)
+	if err != nil {
+		t.Fatalf("Error during simple inner product creation: %v", err)
+	}
+	masterSecKey, masterPubKey, err := simpleDDH.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	x, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	ciphertext, err := simpleDDH.Encrypt(x, masterPubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+
+	ys := make([]data.Vector, n)
+	keys := make([]*big.Int, n)
+	for j := range ys {
+		ys[j], err = data.NewRandomVector(l, sampler)
+		if err != nil {
+			t.Fatalf("Error during random generation: %v", err)
+		}
+		keys[j], err = simpleDDH.DeriveKey(masterSecKey, ys[j])
+		if err != nil {
+			t.Fatalf("Error during key derivation: %v", err)
+		}
+	}
+
+	xys, err := simpleDDH.DecryptMany(ciphertext, keys, ys)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, n, len(xys))
+	for j, xy := range xys {
+		xyCheck, err := x.Dot(ys[j])
+		if err != nil {
+			t.Fatalf("Error during inner product calculation: %v", err)
+		}
+		assert.Equal(t, 0, xy.Cmp(xyCheck), "Original and decrypted values should match")
+	}
+
+	_, err = simpleDDH.DecryptMany(ciphertext, keys[1:], ys)
+	assert.Error(t, err)
+	ys[n-1] = data.NewConstantVector(l, new(big.Int).Add(bound, big.NewInt(1)))
+	_, err = simpleDDH.DecryptMany(ciphertext, keys, ys)
+	assert.Error(t, err)
+}
+
+func benchmarkDDH(b *testing.B, n int) (*simple.DDH, []data.Vector, data.Vector) {
+	l := 10
+	bound := big.NewInt(1000)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+
+	simpleDDH, err := simple.NewDDHPrecomp(l, 2048, bound)
+	if err != nil {
+		b.Fatalf("Error during simple inner product creation: %v", err)
+	}
+	_, masterPubKey, err := simpleDDH.GenerateMasterKeys()
+	if err != nil {
+		b.Fatalf("Error during master key generation: %v", err)
+	}
+	xs := make([]data.Vector, n)
+	for j := range xs {
+		xs[j], err = data.NewRandomVector(l, sampler)
+		if err != nil {
+			b.Fatalf("Error during random generation: %v", err)
+		}
+	}
+
+	return simpleDDH, xs, masterPubKey
+}
+
+// BenchmarkSimple_DDHEncrypt and BenchmarkSimple_DDHEncryptBatch
+// encrypt the same number of vectors, so that their
+// throughput can be compared.
+func BenchmarkSimple_DDHEncrypt(b *testing.B) {
+	simpleDDH, xs, masterPubKey := benchmarkDDH(b, 100)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for _, x := range xs {
+			if _, err := simpleDDH.Encrypt(x, masterPubKey); err != nil {
+				b.Fatalf("Error during encryption: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkSimple_DDHEncryptBatch(b *testing.B) {
+	simpleDDH, xs, masterPubKey := benchmarkDDH(b, 100)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, err := simpleDDH.EncryptBatch(xs, masterPubKey); err != nil {
+			b.Fatalf("Error during batch encryption: %v", err)
+		}
+	}
+}
+
+func TestSimple_DDHDecryptContext(t *testing.T) {
+	l := 3
+	bound := big.NewInt(1000)
+	simpleDDH, err := simple.NewDDHPrecomp(l, 2048, bound)
+	if err != nil {
+		t.Fatalf("Error during simple inner product creation: %v", err)
+	}
+	masterSecKey, masterPubKey, err := simpleDDH.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	y := data.NewConstantVector(l, bound)
+	funcKey, err := simpleDDH.DeriveKey(masterSecKey, y)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+	x := data.NewConstantVector(l, big.NewInt(-7))
+	ciphertext, err := simpleDDH.Encrypt(x, masterPubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+
+	xy, err := simpleDDH.DecryptContext(context.Background(), ciphertext, funcKey, y)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	assert.Equal(t, int64(-21000), xy.Int64(), "Original and decrypted values should match")
+
+	// a decryption under a derived key for another vector
+	// has no solution within the bound and is aborted
+	otherKey, err := simpleDDH.DeriveKey(masterSecKey, data.NewConstantVector(l, big.NewInt(1)))
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = simpleDDH.DecryptContext(ctx, ciphertext, otherKey, y)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+// BenchmarkSimple_DDHDecrypt and BenchmarkSimple_DDHDecryptMany
+// decrypt a ciphertext with the same number of keys, so that
+// their throughput can be compared.
+func BenchmarkSimple_DDHDecrypt(b *testing.B) {
+	simpleDDH, ciphertext, keys, ys := benchmarkDDHDecrypt(b, 20)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		for j := range keys {
+			if _, err := simpleDDH.Decrypt(ciphertext, keys[j], ys[j]); err != nil {
+				b.Fatalf("Error during decryption: %v", err)
+			}
+		}
+	}
+}
+
+func BenchmarkSimple_DDHDecryptMany(b *testing.B) {
+	simpleDDH, ciphertext, keys, ys := benchmarkDDHDecrypt(b, 20)
+	b.ResetTimer()
+	for k := 0; k < b.N; k++ {
+		if _, err := simpleDDH.DecryptMany(ciphertext, keys, ys); err != nil {
+			b.Fatalf("Error during decryption: %v", err)
+		}
+	}
+}
+
+func benchmarkDDHDecrypt(b *testing.B, n int) (*simple.DDH, data.Vector, []*big.Int, []data.Vector) {
+	simpleDDH, xs, _ := benchmarkDDH(b, n+1)
+	masterSecKey, masterPubKey, err := simpleDDH.GenerateMasterKeys()
+	if err != nil {
+		b.Fatalf("Error during master key generation: %v", err)
+	}
+	ciphertext, err := simpleDDH.Encrypt(xs[n], masterPubKey)
+	if err != nil {
+		b.Fatalf("Error during encryption: %v", err)
+	}
+	ys := xs[:n]
+	keys := make([]*big.Int, n)
+	for j, y := range ys {
+		if keys[j], err = simpleDDH.DeriveKey(masterSecKey, y); err != nil {
+			b.Fatalf("Error during key derivation: %v", err)
+		}
+	}
+
+	return simpleDDH, ciphertext, keys, ys
+}
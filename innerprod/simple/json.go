@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simple
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+type ddhMultiSecKeyJSON struct {
+	serial.Header
+	Msk    data.Matrix `json:"msk"`
+	OtpKey data.Matrix `json:"otpKey"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *DDHMultiSecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ddhMultiSecKeyJSON{
+		Header: serial.NewHeader("simple.DDHMultiSecKey"),
+		Msk:    k.Msk,
+		OtpKey: k.OtpKey,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *DDHMultiSecKey) UnmarshalJSON(b []byte) error {
+	var enc ddhMultiSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("simple.DDHMultiSecKey"); err != nil {
+		return err
+	}
+	*k = DDHMultiSecKey{
+		Msk:    enc.Msk,
+		OtpKey: enc.OtpKey,
+	}
+
+	return nil
+}
+
+type ddhMultiDerivedKeyJSON struct {
+	serial.Header
+	Keys   data.Vector    `json:"keys"`
+	OTPKey *serial.BigInt `json:"otpKey"`
+}
+
+// MarshalJSON encodes the derived key as a JSON object.
+func (k *DDHMultiDerivedKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ddhMultiDerivedKeyJSON{
+		Header: serial.NewHeader("simple.DDHMultiDerivedKey"),
+		Keys:   k.Keys,
+		OTPKey: (*serial.BigInt)(k.OTPKey),
+	})
+}
+
+// UnmarshalJSON decodes the derived key encoded with MarshalJSON into k.
+func (k *DDHMultiDerivedKey) UnmarshalJSON(b []byte) error {
+	var enc ddhMultiDerivedKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("simple.DDHMultiDerivedKey"); err != nil {
+		return err
+	}
+	*k = DDHMultiDerivedKey{
+		Keys:   enc.Keys,
+		OTPKey: (*big.Int)(enc.OTPKey),
+	}
+
+	return nil
+}
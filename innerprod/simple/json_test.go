@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package simple_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod/simple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDDHMulti_JSON(t *testing.T) {
+	ddhMulti, err := simple.NewDDHMulti(2, 2, 512, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("Error during scheme creation: %v", err)
+	}
+	_, secKey, err := ddhMulti.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+	y := data.NewConstantMatrix(2, 2, big.NewInt(2))
+	key, err := ddhMulti.DeriveKey(secKey, y)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+
+	secKeyJSON, err := json.Marshal(secKey)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var decSecKey simple.DDHMultiSecKey
+	if err := json.Unmarshal(secKeyJSON, &decSecKey); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	// small values, such as the one-time pad keys, can be zero,
+	// whose internal representation changes when decoded
+	secKeyJSONCheck, err := json.Marshal(&decSecKey)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	assert.Equal(t, secKeyJSON, secKeyJSONCheck)
+
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var decKey simple.DDHMultiDerivedKey
+	if err := json.Unmarshal(keyJSON, &decKey); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	keyJSONCheck, err := json.Marshal(&decKey)
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	assert.Equal(t, keyJSON, keyJSONCheck)
+	assert.Error(t, json.Unmarshal(keyJSON, &decSecKey))
+}
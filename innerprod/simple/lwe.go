@@ -75,6 +75,25 @@ type LWE struct {
 // It returns an error in case public parameters of the scheme could
 // not be generated.
 func NewLWE(l int, boundX, boundY *big.Int, n int) (*LWE, error) {
+	params, err := NewLWEParams(l, boundX, boundY, n)
+	if err != nil {
+		return nil, err
+	}
+
+	// generate a random matrix
+	params.A, err = data.NewRandomMatrix(params.M, params.N, sample.NewUniform(params.Q))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot generate public parameters")
+	}
+
+	return &LWE{Params: params}, nil
+}
+
+// NewLWEParams generates the public parameters of the scheme as
+// NewLWE does, except for the random matrix A, which is left nil.
+// This allows to inspect the parameters, for example to estimate
+// their security, without generating the M*N elements of A.
+func NewLWEParams(l int, boundX, boundY *big.Int, n int) (*LWEParams, error) {
 	// generate parameters
 	// p > boundX * boundY * l * 2
 	nBitsP := boundX.BitLen() + boundY.BitLen() + bits.Len(uint(l)) + 2
@@ -125,24 +144,16 @@ func NewLWE(l int, boundX, boundY *big.Int, n int) (*LWE, error) {
 		return nil, fmt.Errorf("parameters generation faliled, sigmaQ too small")
 	}
 
-	// generate a random matrix
-	A, err := data.NewRandomMatrix(m, n, sample.NewUniform(q))
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot generate public parameters")
-	}
-	return &LWE{
-		Params: &LWEParams{
-			L:      l,
-			BoundX: boundX,
-			BoundY: boundY,
-			N:      n,
-			M:      m,
-			P:      p,
-			Q:      q,
-			A:      A,
-			SigmaQ: sigmaQ,
-			LSigma: lSigma,
-		},
+	return &LWEParams{
+		L:      l,
+		BoundX: boundX,
+		BoundY: boundY,
+		N:      n,
+		M:      m,
+		P:      p,
+		Q:      q,
+		SigmaQ: sigmaQ,
+		LSigma: lSigma,
 	}, nil
 }
 
@@ -83,3 +83,19 @@ func testVectorData(len int, boundX, boundY *big.Int) (data.Vector, data.Vector,
 
 	return x, y, xy
 }
+
+func TestSimple_LWEParams(t *testing.T) {
+	l := 4
+	n := 128
+	b := big.NewInt(100)
+
+	params, err := simple.NewLWEParams(l, b, b, n)
+	assert.NoError(t, err)
+	assert.Nil(t, params.A)
+	assert.Equal(t, n, params.N)
+	assert.True(t, params.M > n)
+
+	simpleLWE, err := simple.NewLWE(l, b, b, n)
+	assert.NoError(t, err)
+	assert.True(t, simpleLWE.Params.A.CheckDims(simpleLWE.Params.M, n))
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package simple
+
+import (
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *DDHParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("simple.DDHParams")
+	e.Int(p.L)
+	e.BigInt(p.Bound)
+	e.BigInt(p.G)
+	e.BigInt(p.P)
+	e.BigInt(p.Q)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes parameters encoded with MarshalBinary into p.
+func (p *DDHParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "simple.DDHParams")
+	params := DDHParams{
+		L:     d.Int(),
+		Bound: d.BigInt(),
+		G:     d.BigInt(),
+		P:     d.BigInt(),
+		Q:     d.BigInt(),
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *DDHMultiSecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("simple.DDHMultiSecKey")
+	e.Value(k.Msk, k.Msk != nil)
+	e.Value(k.OtpKey, k.OtpKey != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a secret key encoded with MarshalBinary into k.
+func (k *DDHMultiSecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "simple.DDHMultiSecKey")
+	var key DDHMultiSecKey
+	d.Value(&key.Msk)
+	d.Value(&key.OtpKey)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the derived key into a canonical binary form.
+func (k *DDHMultiDerivedKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("simple.DDHMultiDerivedKey")
+	e.Value(k.Keys, k.Keys != nil)
+	e.BigInt(k.OTPKey)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes a derived key encoded with MarshalBinary into k.
+func (k *DDHMultiDerivedKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "simple.DDHMultiDerivedKey")
+	var key DDHMultiDerivedKey
+	d.Value(&key.Keys)
+	key.OTPKey = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *LWEParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("simple.LWEParams")
+	e.Int(p.L)
+	e.Int(p.N)
+	e.Int(p.M)
+	e.BigInt(p.BoundX)
+	e.BigInt(p.BoundY)
+	e.BigInt(p.P)
+	e.BigInt(p.Q)
+	e.BigFloat(p.SigmaQ)
+	e.BigInt(p.LSigma)
+	e.Value(p.A, p.A != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes parameters encoded with MarshalBinary into p.
+func (p *LWEParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "simple.LWEParams")
+	params := LWEParams{
+		L:      d.Int(),
+		N:      d.Int(),
+		M:      d.Int(),
+		BoundX: d.BigInt(),
+		BoundY: d.BigInt(),
+		P:      d.BigInt(),
+		Q:      d.BigInt(),
+		SigmaQ: d.BigFloat(),
+		LSigma: d.BigInt(),
+	}
+	d.Value(&params.A)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+func (p *RingLWEParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("simple.RingLWEParams")
+	e.Int(p.L)
+	e.Int(p.N)
+	e.BigFloat(p.Sigma)
+	e.BigInt(p.Bound)
+	e.BigInt(p.P)
+	e.BigInt(p.Q)
+	e.Value(p.A, p.A != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes parameters encoded with MarshalBinary into p.
+func (p *RingLWEParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "simple.RingLWEParams")
+	params := RingLWEParams{
+		L:     d.Int(),
+		N:     d.Int(),
+		Sigma: d.BigFloat(),
+		Bound: d.BigInt(),
+		P:     d.BigInt(),
+		Q:     d.BigInt(),
+	}
+	d.Value(&params.A)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package simple_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod/simple"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDDH_MarshalBinary(t *testing.T) {
+	l := 3
+	bound := big.NewInt(1024)
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+
+	simpleDDH, err := simple.NewDDH(l, 512, bound)
+	if err != nil {
+		t.Fatalf("Error during simple inner product creation: %v", err)
+	}
+	masterSecKey, masterPubKey, err := simpleDDH.GenerateMasterKeys()
+	if err != nil {
+		t.Fatalf("Error during master key generation: %v", err)
+	}
+
+	// the parameters and the public key are sent to the encryptor
+	paramsBytes, err := simpleDDH.Params.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	pubKeyBytes, err := masterPubKey.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+
+	params := new(simple.DDHParams)
+	if err := params.UnmarshalBinary(paramsBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	assert.Equal(t, simpleDDH.Params, params)
+	var pubKey data.Vector
+	if err := pubKey.UnmarshalBinary(pubKeyBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+
+	x, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	y, err := data.NewRandomVector(l, sampler)
+	if err != nil {
+		t.Fatalf("Error during random generation: %v", err)
+	}
+	funcKey, err := simpleDDH.DeriveKey(masterSecKey, y)
+	if err != nil {
+		t.Fatalf("Error during key derivation: %v", err)
+	}
+
+	encryptor := simple.NewDDHFromParams(params)
+	ciphertext, err := encryptor.Encrypt(x, pubKey)
+	if err != nil {
+		t.Fatalf("Error during encryption: %v", err)
+	}
+	xy, err := simpleDDH.Decrypt(ciphertext, funcKey, y)
+	if err != nil {
+		t.Fatalf("Error during decryption: %v", err)
+	}
+	xyCheck, err := x.Dot(y)
+	if err != nil {
+		t.Fatalf("Error during inner product calculation: %v", err)
+	}
+	assert.Equal(t, xyCheck, xy)
+
+	// parameters of one type cannot be decoded as another
+	assert.Error(t, new(simple.LWEParams).UnmarshalBinary(paramsBytes))
+}
+
+func TestLWE_MarshalBinary(t *testing.T) {
+	b := big.NewInt(10000)
+	simpleLWE, err := simple.NewLWE(4, b, b, 128)
+	if err != nil {
+		t.Fatalf("Error during simple inner product creation: %v", err)
+	}
+
+	paramsBytes, err := simpleLWE.Params.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	params := new(simple.LWEParams)
+	if err := params.UnmarshalBinary(paramsBytes); err != nil {
+		t.Fatalf("Error during unmarshaling: %v", err)
+	}
+	checkBytes, err := params.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Error during marshaling: %v", err)
+	}
+	assert.Equal(t, paramsBytes, checkBytes)
+	assert.Equal(t, simpleLWE.Params.A, params.A)
+	assert.Equal(t, 0, simpleLWE.Params.SigmaQ.Cmp(params.SigmaQ))
+}
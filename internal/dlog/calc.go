@@ -17,9 +17,12 @@
 package dlog
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha1"
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/fentec-project/bn256"
 )
@@ -46,6 +49,7 @@ type CalcZp struct {
 	bound *big.Int
 	m     *big.Int
 	neg   bool
+	table *Table
 }
 
 // InZp builds parameters needed to calculate a discrete
@@ -88,6 +92,7 @@ func (c *CalcZp) WithBound(bound *big.Int) *CalcZp {
 			m:     m,
 			p:     c.p,
 			neg:   c.neg,
+			table: c.table,
 		}
 	}
 	return c
@@ -101,9 +106,28 @@ func (c *CalcZp) WithNeg() *CalcZp {
 		m:     c.m,
 		p:     c.p,
 		neg:   true,
+		table: c.table,
 	}
 }
 
+// WithTable sets a precomputed table of small steps, which is
+// used when computing discrete logarithms to the base of the
+// generator of the table. It returns an error if the table
+// was not computed in the same group.
+func (c *CalcZp) WithTable(t *Table) (*CalcZp, error) {
+	if t.group != GroupZp || t.p.Cmp(c.p) != 0 {
+		return nil, fmt.Errorf("table was not computed in Z_p")
+	}
+
+	return &CalcZp{
+		bound: c.bound,
+		m:     c.m,
+		p:     c.p,
+		neg:   c.neg,
+		table: t,
+	}, nil
+}
+
 // BabyStepGiantStep uses the baby-step giant-step method to
 // compute the discrete logarithm in the Zp group. If c.neg is
 // set to true it searches for the answer within [-bound, bound].
@@ -112,25 +136,49 @@ func (c *CalcZp) WithNeg() *CalcZp {
 // only one goroutine is started, searching for the answer
 // within [0, bound].
 func (c *CalcZp) BabyStepGiantStep(h, g *big.Int) (*big.Int, error) {
+	return c.BabyStepGiantStepContext(context.Background(), h, g)
+}
+
+// BabyStepGiantStepContext is like BabyStepGiantStep, but stops
+// the search and returns the error of ctx as soon as ctx is done.
+// All the goroutines are terminated when it returns.
+func (c *CalcZp) BabyStepGiantStepContext(ctx context.Context, h, g *big.Int) (*big.Int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// create goroutines calculating positive and possibly negative
 	// result if c.neg is set to true
-	retChan := make(chan *big.Int)
-	errChan := make(chan error)
-	go c.runBabyStepGiantStepIterative(h, g, retChan, errChan)
+	resChan := make(chan result, 2)
+	quit := ctx.Done()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.runBabyStepGiantStepIterative(h, g, resChan, quit)
+	}()
 	if c.neg {
-		gInv := new(big.Int).ModInverse(g, c.p)
-		go c.runBabyStepGiantStepIterative(h, gInv, retChan, errChan)
+		// search for the logarithm of h^-1 rather than for the
+		// logarithm to the base g^-1, so that a table of the
+		// powers of g can be used
+		hInv := new(big.Int).ModInverse(h, c.p)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.runBabyStepGiantStepIterative(hInv, g, resChan, quit)
+		}()
 	}
 
 	// catch a value when the first routine finishes
-	ret := <-retChan
-	err := <-errChan
+	ret, err := receive(ctx, resChan)
 	// prevent the situation when one routine exhausted all possibilities
 	// before the second found the solution
-	if c.neg && err != nil {
-		ret = <-retChan
-		err = <-errChan
+	if c.neg && err != nil && ctx.Err() == nil {
+		ret, err = receive(ctx, resChan)
 	}
+	// stop the other routine and wait for it, so that the table
+	// is no longer in use when this function returns
+	cancel()
+	wg.Wait()
 	// if both routines give an error, return an error
 	if err != nil {
 		return nil, err
@@ -144,6 +192,25 @@ func (c *CalcZp) BabyStepGiantStep(h, g *big.Int) (*big.Int, error) {
 	return ret, nil
 }
 
+// result is the outcome of a search for a discrete logarithm run as
+// a goroutine. The logarithm and the error are sent together, so that
+// the outcomes of concurrent searches cannot be mixed up.
+type result struct {
+	x   *big.Int
+	err error
+}
+
+// receive returns the result of the first routine that finishes,
+// or the error of ctx if ctx is done before.
+func receive(ctx context.Context, resChan <-chan result) (*big.Int, error) {
+	select {
+	case res := <-resChan:
+		return res.x, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // runBabyStepGiantStep implements the baby-step giant-step method to
 // compute the discrete logarithm in the Zp group. It is meant to be run
 // as a goroutine.
@@ -188,12 +255,29 @@ func (c *CalcZp) runBabyStepGiantStep(h, g *big.Int, retChan chan *big.Int, errC
 // within the provided bound, it returns an error. In contrast to the usual
 // implementation of the method, this one proceeds iteratively, meaning that
 // smaller the solution is, faster the algorithm finishes.
-func (c *CalcZp) runBabyStepGiantStepIterative(h, g *big.Int, retChan chan *big.Int, errChan chan error) {
+func (c *CalcZp) runBabyStepGiantStepIterative(h, g *big.Int, resChan chan<- result, quit <-chan struct{}) {
 	one := big.NewInt(1)
 	two := big.NewInt(2)
 
 	// big.Int cannot be a key, thus we use a stringified bytes representation of the integer
 	T := make(map[string]*big.Int)
+	// small steps below 2^tableBits are taken from the table
+	var table *Table
+	tableBits := int64(0)
+	if c.table != nil && c.table.generatorZp().Cmp(g) == 0 {
+		table = c.table
+		tableBits = int64(table.bits)
+	}
+	lookup := func(y *big.Int) (*big.Int, bool) {
+		if e, ok := T[string(y.Bytes())]; ok {
+			return e, true
+		}
+		if table != nil {
+			return table.lookup(keyZp(y))
+		}
+		return nil, false
+	}
+
 	// prepare values for the loop
 	x := big.NewInt(1)
 	y := new(big.Int).Set(h)
@@ -202,12 +286,25 @@ func (c *CalcZp) runBabyStepGiantStepIterative(h, g *big.Int, retChan chan *big.
 
 	bits := int64(c.m.BitLen())
 
-	T[string(x.Bytes())] = big.NewInt(0)
-	x.Mod(x.Mul(x, g), c.p)
+	// with a table, the loop starts with the giant steps of
+	// size 2^tableBits, as all the smaller steps are known
+	start := int64(0)
+	if table != nil {
+		start = tableBits - 1
+		if start > bits-1 {
+			start = bits - 1
+		}
+		z.ModInverse(g, c.p)
+		z.Exp(z, new(big.Int).Exp(two, big.NewInt(start+1), nil), c.p)
+		x.Exp(g, new(big.Int).Exp(two, big.NewInt(tableBits), nil), c.p)
+	} else {
+		T[string(x.Bytes())] = big.NewInt(0)
+		x.Mod(x.Mul(x, g), c.p)
+	}
 	j := big.NewInt(0)
 	giantStep := new(big.Int)
 	bound := new(big.Int)
-	for i := int64(0); i < bits; i++ {
+	for i := start; i < bits; i++ {
 		// iteratively increasing giant step up to maximal value c.m
 		giantStep.Exp(two, big.NewInt(i+1), nil)
 		if giantStep.Cmp(c.m) > 0 {
@@ -216,16 +313,29 @@ func (c *CalcZp) runBabyStepGiantStepIterative(h, g *big.Int, retChan chan *big.
 			z.Exp(z, c.m, c.p)
 		}
 		// for the selected giant step, add all the needed small steps
-		for k := new(big.Int).Exp(two, big.NewInt(i), nil); k.Cmp(giantStep) < 0; k.Add(k, one) {
+		k := new(big.Int).Exp(two, big.NewInt(i), nil)
+		if i < tableBits {
+			k.Exp(two, big.NewInt(tableBits), nil)
+		}
+		for ; k.Cmp(giantStep) < 0; k.Add(k, one) {
+			select {
+			case <-quit:
+				return
+			default:
+			}
 			T[string(x.Bytes())] = new(big.Int).Set(k)
 			x = x.Mod(x.Mul(x, g), c.p)
 		}
 		// make giant steps and search for the solution
 		bound.Exp(two, big.NewInt(2*(i+1)), nil)
 		for ; j.Cmp(bound) < 0; j.Add(j, giantStep) {
-			if e, ok := T[string(y.Bytes())]; ok {
-				retChan <- new(big.Int).Add(j, e)
-				errChan <- nil
+			select {
+			case <-quit:
+				return
+			default:
+			}
+			if e, ok := lookup(y); ok {
+				resChan <- result{x: new(big.Int).Add(j, e)}
 				return
 			}
 			y.Mod(y.Mul(y, z), c.p)
@@ -234,18 +344,18 @@ func (c *CalcZp) runBabyStepGiantStepIterative(h, g *big.Int, retChan chan *big.
 		z.Mod(z, c.p)
 	}
 
-	retChan <- nil
-	errChan <- fmt.Errorf("failed to find the discrete logarithm within bound")
+	resChan <- result{err: fmt.Errorf("failed to find the discrete logarithm within bound")}
 }
 
 // CalcBN256 represents a calculator for discrete logarithms
 // that operates in the BN256 group.
 type CalcBN256 struct {
-	bound   *big.Int
-	m       *big.Int
-	Precomp map[string]*big.Int
+	bound          *big.Int
+	m              *big.Int
+	Precomp        map[string]*big.Int
 	precompMaxBits int
-	neg     bool
+	neg            bool
+	table          *Table
 }
 
 // InBN256 builds parameters needed to calculate a discrete
@@ -267,11 +377,12 @@ func (c *CalcBN256) WithBound(bound *big.Int) *CalcBN256 {
 		m.Add(m, big.NewInt(1))
 
 		return &CalcBN256{
-			bound:   bound,
-			m:       m,
-			Precomp: c.Precomp,
+			bound:          bound,
+			m:              m,
+			Precomp:        c.Precomp,
 			precompMaxBits: c.precompMaxBits,
-			neg:     c.neg,
+			neg:            c.neg,
+			table:          c.table,
 		}
 	}
 	return c
@@ -281,12 +392,42 @@ func (c *CalcBN256) WithBound(bound *big.Int) *CalcBN256 {
 // negative integers.
 func (c *CalcBN256) WithNeg() *CalcBN256 {
 	return &CalcBN256{
-		bound:   c.bound,
-		m:       c.m,
-		Precomp: c.Precomp,
+		bound:          c.bound,
+		m:              c.m,
+		Precomp:        c.Precomp,
 		precompMaxBits: c.precompMaxBits,
-		neg:     true,
+		neg:            true,
+		table:          c.table,
+	}
+}
+
+// WithTable sets a precomputed table of small steps, which is
+// used instead of Precomp. It returns an error if the table
+// was not computed in the BN256.GT group.
+func (c *CalcBN256) WithTable(t *Table) (*CalcBN256, error) {
+	if t.group != GroupBN256 {
+		return nil, fmt.Errorf("table was not computed in BN256.GT")
 	}
+
+	return &CalcBN256{
+		bound:          c.bound,
+		m:              c.m,
+		Precomp:        c.Precomp,
+		precompMaxBits: c.precompMaxBits,
+		neg:            c.neg,
+		table:          t,
+	}, nil
+}
+
+// lookupPrecomp returns the exponent of the element with the
+// given key from the table if set, and from Precomp otherwise.
+func (c *CalcBN256) lookupPrecomp(key []byte) (*big.Int, bool) {
+	if c.table != nil {
+		return c.table.lookup(key)
+	}
+	e, ok := c.Precomp[string(key)]
+
+	return e, ok
 }
 
 // Precompute precomputes small steps for the discrete logarithm
@@ -357,30 +498,47 @@ func (c *CalcBN256) BabyStepGiantStepStd(h, g *bn256.GT) (*big.Int, error) {
 // only one goroutine is started, searching for the answer
 // within [0, bound].
 func (c *CalcBN256) BabyStepGiantStep(h, g *bn256.GT) (*big.Int, error) {
+	return c.BabyStepGiantStepContext(context.Background(), h, g)
+}
+
+// BabyStepGiantStepContext is like BabyStepGiantStep, but stops
+// the search and returns the error of ctx as soon as ctx is done.
+// All the goroutines are terminated when it returns.
+func (c *CalcBN256) BabyStepGiantStepContext(ctx context.Context, h, g *bn256.GT) (*big.Int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// create goroutines calculating positive and possibly negative
 	// result if c.neg is set to true
-	retChan := make(chan *big.Int, 2)
-	errChan := make(chan error, 2)
-	quit := make(chan bool, 2)
-	go c.runBabyStepGiantStepIterative(h, g, retChan, errChan, quit)
+	resChan := make(chan result, 2)
+	quit := ctx.Done()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.runBabyStepGiantStepIterative(h, g, resChan, quit)
+	}()
 	if c.neg {
 		hInv := new(bn256.GT).Neg(h)
-		go c.runBabyStepGiantStepIterative(hInv, g, retChan, errChan, quit)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.runBabyStepGiantStepIterative(hInv, g, resChan, quit)
+		}()
 	}
 
 	// catch a value when the first routine finishes
-	ret := <-retChan
-	err := <-errChan
+	ret, err := receive(ctx, resChan)
 
 	// prevent the situation when one routine exhausted all possibilities
 	// before the second found the solution
-	if c.neg && err != nil {
-		ret = <-retChan
-		err = <-errChan
-	}
-	if c.neg {
-		quit <- true
+	if c.neg && err != nil && ctx.Err() == nil {
+		ret, err = receive(ctx, resChan)
 	}
+	// stop the other routine and wait for it, so that the table
+	// is no longer in use when this function returns
+	cancel()
+	wg.Wait()
 	// if both routines give an error, return an error
 	if err != nil {
 		return nil, err
@@ -403,18 +561,40 @@ func (c *CalcBN256) BabyStepGiantStep(h, g *bn256.GT) (*big.Int, error) {
 // within the provided bound, it returns an error. In contrast to the usual
 // implementation of the method, this one proceeds iteratively, meaning that
 // smaller the solution is, faster the algorithm finishes.
-func (c *CalcBN256) runBabyStepGiantStepIterative(h, g *bn256.GT, retChan chan *big.Int, errChan chan error,  quit chan bool) {
+func (c *CalcBN256) runBabyStepGiantStepIterative(h, g *bn256.GT, resChan chan<- result, quit <-chan struct{}) {
 	one := big.NewInt(1)
 	two := big.NewInt(2)
 
+	sh := sha1.New()
 
+	// the precomputed small steps are the powers of the generator of
+	// GT, thus for another base the first few of them are computed here
+	lookup := c.lookupPrecomp
 	var startBits int
-	if c.Precomp == nil {
-		_ = c.Precompute(2)
+	gen := new(bn256.GT).ScalarBaseMult(one)
+	if !bytes.Equal(g.Marshal(), gen.Marshal()) {
+		startBits = 2
+		small := make(map[string]*big.Int)
+		x := bn256.GetGTOne()
+		for k := int64(0); k < 1<<uint(startBits); k++ {
+			sh.Write([]byte(x.String()))
+			small[string(sh.Sum(nil)[:keyLen])] = big.NewInt(k)
+			sh.Reset()
+			x = new(bn256.GT).Add(x, g)
+		}
+		lookup = func(key []byte) (*big.Int, bool) {
+			e, ok := small[string(key)]
+			return e, ok
+		}
+	} else if c.table != nil {
+		startBits = c.table.bits
+	} else {
+		if c.Precomp == nil {
+			_ = c.Precompute(2)
+		}
+		startBits = c.precompMaxBits
 	}
 
-	startBits = c.precompMaxBits
-
 	// prepare values for the loop
 	y := new(bn256.GT).Set(h)
 	j := big.NewInt(0)
@@ -426,18 +606,16 @@ func (c *CalcBN256) runBabyStepGiantStepIterative(h, g *bn256.GT, retChan chan *
 	z.ScalarMult(z, giantStep)
 
 	bound := new(big.Int).Exp(two, big.NewInt(2*int64(startBits)), nil)
-	sh := sha1.New()
 	for ; j.Cmp(bound) < 0; j.Add(j, giantStep) {
 		select {
 		case <-quit:
 			return
 		default:
 			sh.Write([]byte(y.String()))
-			e, ok := c.Precomp[string(sh.Sum(nil)[:10])]
+			e, ok := lookup(sh.Sum(nil)[:keyLen])
 			sh.Reset()
 			if ok {
-				retChan <- new(big.Int).Add(j, e)
-				errChan <- nil
+				resChan <- result{x: new(big.Int).Add(j, e)}
 				return
 			}
 			y.Add(y, z)
@@ -446,10 +624,8 @@ func (c *CalcBN256) runBabyStepGiantStepIterative(h, g *bn256.GT, retChan chan *
 	z.Add(z, z)
 	x := new(bn256.GT).ScalarMult(g, new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(startBits)), nil))
 
+	// small steps beyond the precomputed ones
 	T := make(map[string]*big.Int)
-	for k,v := range c.Precomp {
-		T[k] = v
-	}
 
 	bits := int64(c.m.BitLen())
 	for i := int64(startBits); i < bits; i++ {
@@ -484,11 +660,14 @@ func (c *CalcBN256) runBabyStepGiantStepIterative(h, g *bn256.GT, retChan chan *
 					return
 				default:
 					sh.Write([]byte(y.String()))
-					e, ok := T[string(sh.Sum(nil)[:10])]
+					key := sh.Sum(nil)[:keyLen]
 					sh.Reset()
+					e, ok := T[string(key)]
+					if !ok {
+						e, ok = lookup(key)
+					}
 					if ok {
-						retChan <- new(big.Int).Add(j, e)
-						errChan <- nil
+						resChan <- result{x: new(big.Int).Add(j, e)}
 						return
 					}
 					y.Add(y, z)
@@ -497,6 +676,5 @@ func (c *CalcBN256) runBabyStepGiantStepIterative(h, g *bn256.GT, retChan chan *
 			z.Add(z, z)
 		}
 	}
-	retChan <- nil
-	errChan <- fmt.Errorf("failed to find the discrete logarithm within bound")
+	resChan <- result{err: fmt.Errorf("failed to find the discrete logarithm within bound")}
 }
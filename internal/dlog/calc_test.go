@@ -17,8 +17,11 @@
 package dlog
 
 import (
+	"context"
 	"math/big"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/fentec-project/bn256"
 	"github.com/fentec-project/gofe/internal"
@@ -107,3 +110,62 @@ func TestCalcBN256_BabyStepGiantStep(t *testing.T) {
 	}
 	assert.Equal(t, xCheck.Cmp(x), 0, "BabyStepGiantStep in BN256 returns wrong dlog")
 }
+
+func TestCalcBN256_BabyStepGiantStep_Base(t *testing.T) {
+	// the base is not the generator of GT, for which
+	// the small steps are precomputed
+	g := new(bn256.GT).ScalarBaseMult(big.NewInt(3))
+	bound := big.NewInt(81920)
+	calc := NewCalc().InBN256().WithBound(bound).WithNeg()
+	for _, xCheck := range []int64{0, 1, -1, 2, 3, -3, 13, -450, 6531, -81919} {
+		h := new(bn256.GT).ScalarMult(g, new(big.Int).Mod(big.NewInt(xCheck), bn256.Order))
+		x, err := calc.BabyStepGiantStep(h, g)
+		if err != nil {
+			t.Fatalf("Error in baby step - giant step algorithm: %v", err)
+		}
+		assert.Equal(t, xCheck, x.Int64(), "BabyStepGiantStep in BN256 returns wrong dlog")
+	}
+}
+
+func TestCalcZp_BabyStepGiantStepContext(t *testing.T) {
+	key, err := keygen.NewElGamal(128)
+	if err != nil {
+		t.Fatalf("Error in ElGamal key generation: %v", err)
+	}
+	calc, err := NewCalc().InZp(key.P, key.Q)
+	if err != nil {
+		t.Fatal("Error in creation of new CalcZp:", err)
+	}
+	calc = calc.WithBound(new(big.Int).Lsh(big.NewInt(1), 46)).WithNeg()
+
+	// the logarithm is far beyond the bound, so the search would
+	// take very long if it was not cancelled
+	h := new(big.Int).Exp(key.G, new(big.Int).Lsh(big.NewInt(1), 100), key.P)
+	goroutines := runtime.NumGoroutine()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = calc.BabyStepGiantStepContext(ctx, h, key.G)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, runtime.NumGoroutine() <= goroutines, "all goroutines should terminate")
+
+	// the result is found before the deadline
+	h = new(big.Int).Exp(key.G, big.NewInt(1000), key.P)
+	x, err := calc.BabyStepGiantStepContext(context.Background(), h, key.G)
+	if err != nil {
+		t.Fatalf("Error in baby step - giant step algorithm: %v", err)
+	}
+	assert.Equal(t, 0, big.NewInt(1000).Cmp(x))
+}
+
+func TestCalcBN256_BabyStepGiantStepContext(t *testing.T) {
+	calc := NewCalc().InBN256().WithBound(new(big.Int).Lsh(big.NewInt(1), 46)).WithNeg()
+
+	g := new(bn256.GT).ScalarBaseMult(big.NewInt(1))
+	h := new(bn256.GT).ScalarMult(g, new(big.Int).Lsh(big.NewInt(1), 100))
+	goroutines := runtime.NumGoroutine()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := calc.BabyStepGiantStepContext(ctx, h, g)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, runtime.NumGoroutine() <= goroutines, "all goroutines should terminate")
+}
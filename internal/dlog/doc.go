@@ -19,4 +19,8 @@
 // FE schemes instantiated from the Discrete Diffie-Hellman assumption
 // (DDH) all rely on efficient algorithms for calculating discrete
 // logarithms.
+//
+// The small steps of the baby-step giant-step method can be
+// precomputed into a Table, which can be saved to a file and
+// memory-mapped by other processes.
 package dlog
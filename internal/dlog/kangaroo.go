@@ -0,0 +1,415 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dlog
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/sample"
+)
+
+// kangarooGroup holds the operations of a group needed by
+// Pollard's kangaroo method. Elements are represented by
+// *big.Int in Z_p and by *bn256.GT in BN256.GT.
+type kangarooGroup interface {
+	// mul returns a new element a * b.
+	mul(a, b interface{}) interface{}
+	// exp returns a new element a^k for k >= 0.
+	exp(a interface{}, k *big.Int) interface{}
+	// bytes returns a canonical encoding of a.
+	bytes(a interface{}) []byte
+}
+
+type kangarooZp struct {
+	p *big.Int
+}
+
+func (z kangarooZp) mul(a, b interface{}) interface{} {
+	x := new(big.Int).Mul(a.(*big.Int), b.(*big.Int))
+	return x.Mod(x, z.p)
+}
+
+func (z kangarooZp) exp(a interface{}, k *big.Int) interface{} {
+	return new(big.Int).Exp(a.(*big.Int), k, z.p)
+}
+
+func (z kangarooZp) bytes(a interface{}) []byte {
+	return a.(*big.Int).Bytes()
+}
+
+type kangarooBN256 struct{}
+
+func (kangarooBN256) mul(a, b interface{}) interface{} {
+	return new(bn256.GT).Add(a.(*bn256.GT), b.(*bn256.GT))
+}
+
+func (kangarooBN256) exp(a interface{}, k *big.Int) interface{} {
+	return new(bn256.GT).ScalarMult(a.(*bn256.GT), k)
+}
+
+func (kangarooBN256) bytes(a interface{}) []byte {
+	return a.(*bn256.GT).Marshal()
+}
+
+// kangarooAttempts is the number of times the kangaroos are
+// released with new random starting points before the search
+// is given up.
+const kangarooAttempts = 4
+
+// kangaroo holds the parameters of Pollard's kangaroo method
+// for the search of x in the interval [0, n], such that h = g^x.
+type kangaroo struct {
+	grp    kangarooGroup
+	g, h   interface{}
+	n      *big.Int
+	jumps  []*big.Int    // sizes of jumps, powers of 2
+	gJumps []interface{} // g to the sizes of jumps
+	dpMask uint64        // distinguished points have hash & dpMask == 0
+	steps  int64         // steps of a kangaroo before it is released again
+}
+
+// newKangaroo sets up the search of x in [0, n], such that h = g^x,
+// for the given number of workers, each running a pair of kangaroos.
+func newKangaroo(grp kangarooGroup, h, g interface{}, n *big.Int, workers int) *kangaroo {
+	// the kangaroos of the workers split the interval, so that
+	// each tame kangaroo covers about n / workers of it; the mean
+	// jump should be about half of the square root of this, and
+	// the mean of the jumps 1, 2, ..., 2^(k-1) is (2^k - 1) / k
+	span := new(big.Int).Div(n, big.NewInt(int64(workers)))
+	span.Add(span, big.NewInt(1))
+	mean := new(big.Int).Sqrt(span)
+	mean.Rsh(mean, 1)
+	k := 1
+	for new(big.Int).Lsh(big.NewInt(1), uint(k)).Cmp(new(big.Int).Mul(mean, big.NewInt(int64(k)))) < 0 {
+		k++
+	}
+	jumps := make([]*big.Int, k)
+	gJumps := make([]interface{}, k)
+	for i := range jumps {
+		jumps[i] = new(big.Int).Lsh(big.NewInt(1), uint(i))
+		gJumps[i] = grp.exp(g, jumps[i])
+	}
+
+	// a kangaroo hits a distinguished point every 2^dpBits steps
+	// on average, which is a small fraction of the sqrt(n) steps
+	// needed to find the logarithm
+	dpBits := n.BitLen() / 4
+	// the kangaroos are released again after a few times the
+	// expected number of steps
+	steps := new(big.Int).Sqrt(span)
+	steps.Mul(steps, big.NewInt(8))
+	steps.Add(steps, big.NewInt(int64(16)<<uint(dpBits)))
+	if !steps.IsInt64() {
+		steps.SetInt64(math.MaxInt64)
+	}
+
+	return &kangaroo{
+		grp:    grp,
+		g:      g,
+		h:      h,
+		n:      n,
+		jumps:  jumps,
+		gJumps: gJumps,
+		dpMask: 1<<uint(dpBits) - 1,
+		steps:  steps.Int64(),
+	}
+}
+
+// herd is a kangaroo jumping through the group, which is
+// tame if it started at a known power of g, and wild if it
+// started at a power of h.
+type herd struct {
+	pos  interface{}
+	hash uint64   // hash of pos
+	dist *big.Int // exponent of g such that pos = g^dist (tame) or h*g^dist (wild)
+	tame bool
+}
+
+// trace is a distinguished point visited by a kangaroo.
+type trace struct {
+	dist *big.Int
+	tame bool
+}
+
+// newHerd returns a kangaroo at position pos.
+func (c *kangaroo) newHerd(pos interface{}, dist *big.Int, tame bool) *herd {
+	return &herd{pos: pos, hash: hash(c.grp.bytes(pos)), dist: dist, tame: tame}
+}
+
+// jump moves kangaroo k to the next position, which is determined
+// by the current one. It reports whether the new position is
+// a distinguished point.
+func (c *kangaroo) jump(k *herd) bool {
+	// the upper half of the hash selects the jump, the lower
+	// half distinguishes points
+	i := (k.hash >> 32) % uint64(len(c.jumps))
+	k.pos = c.grp.mul(k.pos, c.gJumps[i])
+	k.dist.Add(k.dist, c.jumps[i])
+	k.hash = hash(c.grp.bytes(k.pos))
+
+	return k.hash&c.dpMask == 0
+}
+
+func hash(b []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return h.Sum64()
+}
+
+// release returns a kangaroo at a random position: a tame one
+// anywhere in the interval, and a wild one close to h, so that
+// it does not jump out of the interval.
+func (c *kangaroo) release(tame bool) (*herd, error) {
+	d, err := sample.NewUniform(new(big.Int).Add(c.n, big.NewInt(1))).Sample()
+	if err != nil {
+		return nil, err
+	}
+	if tame {
+		return c.newHerd(c.grp.exp(c.g, d), d, true), nil
+	}
+	d.Rsh(d, 4)
+
+	return c.newHerd(c.grp.mul(c.h, c.grp.exp(c.g, d)), d, false), nil
+}
+
+// collide returns x if the two traces of distinguished points
+// are from a tame and a wild kangaroo, and the logarithm of h
+// computed from them is correct.
+func (c *kangaroo) collide(a, b trace) (*big.Int, bool) {
+	if a.tame == b.tame {
+		return nil, false
+	}
+	if b.tame {
+		a, b = b, a
+	}
+	// g^a.dist = h * g^b.dist
+	x := new(big.Int).Sub(a.dist, b.dist)
+	if x.Sign() < 0 || x.Cmp(c.n) > 0 {
+		return nil, false
+	}
+	if string(c.grp.bytes(c.grp.exp(c.g, x))) != string(c.grp.bytes(c.h)) {
+		return nil, false
+	}
+
+	return x, true
+}
+
+// traces holds the distinguished points visited by the kangaroos
+// of all the workers.
+type traces struct {
+	sync.Mutex
+	points map[string]trace
+}
+
+// visit records the distinguished point at the position of
+// kangaroo k. It returns x if the point was visited before by
+// a kangaroo of the other kind, and reports whether it was
+// visited before by a kangaroo of the same kind, in which case
+// k will follow the same path and should be released again.
+func (c *kangaroo) visit(t *traces, k *herd) (*big.Int, bool) {
+	key := string(c.grp.bytes(k.pos))
+	tr := trace{dist: new(big.Int).Set(k.dist), tame: k.tame}
+
+	t.Lock()
+	defer t.Unlock()
+	other, ok := t.points[key]
+	if !ok {
+		t.points[key] = tr
+		return nil, false
+	}
+	if x, ok := c.collide(tr, other); ok {
+		return x, false
+	}
+
+	return nil, other.tame == tr.tame
+}
+
+// run searches for the logarithm with the given number of workers,
+// following the parallel method of van Oorschot and Wiener: each
+// worker runs a tame and a wild kangaroo, and the logarithm is found
+// when a tame and a wild kangaroo of any of the workers visit the
+// same distinguished point.
+func (c *kangaroo) run(ctx context.Context, workers int) (*big.Int, error) {
+	for attempt := 0; attempt < kangarooAttempts; attempt++ {
+		x, err := c.attempt(ctx, workers)
+		if err != nil {
+			return nil, err
+		}
+		if x != nil {
+			return x, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to find the discrete logarithm within bound")
+}
+
+// attempt releases the kangaroos of the workers once and lets them
+// jump for the given number of steps. It returns nil if the logarithm
+// was not found.
+func (c *kangaroo) attempt(ctx context.Context, workers int) (*big.Int, error) {
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	t := &traces{points: make(map[string]trace)}
+	res := make(chan *big.Int, workers)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			x, err := c.work(wctx, t)
+			if x != nil {
+				res <- x
+			} else if err != nil {
+				errs <- err
+			} else {
+				return
+			}
+			// stop the other workers
+			cancel()
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case x := <-res:
+		return x, nil
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	close(errs)
+	for err := range errs {
+		if err != context.Canceled {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// work runs a pair of kangaroos until one of them finds the
+// logarithm, the steps are exhausted or ctx is done.
+func (c *kangaroo) work(ctx context.Context, t *traces) (*big.Int, error) {
+	kangaroos := make([]*herd, 2)
+	for i := range kangaroos {
+		k, err := c.release(i == 0)
+		if err != nil {
+			return nil, err
+		}
+		kangaroos[i] = k
+	}
+	for step := int64(0); step < c.steps; step++ {
+		if step%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		for i, k := range kangaroos {
+			if !c.jump(k) {
+				continue
+			}
+			x, dup := c.visit(t, k)
+			if x != nil {
+				return x, nil
+			}
+			if dup {
+				var err error
+				if kangaroos[i], err = c.release(k.tame); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// kangarooSearch searches for x in [0, bound], or in [-bound, bound]
+// if neg is true, such that h = g^x.
+func kangarooSearch(ctx context.Context, grp kangarooGroup, h, g interface{}, bound *big.Int, neg bool, workers int) (*big.Int, error) {
+	if bound == nil || bound.Sign() <= 0 {
+		return nil, fmt.Errorf("bound should be positive")
+	}
+	if h == nil || g == nil {
+		return nil, fmt.Errorf("elements cannot be nil")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	n := bound
+	if neg {
+		// search for x + bound in [0, 2 * bound]
+		h = grp.mul(h, grp.exp(g, bound))
+		n = new(big.Int).Lsh(bound, 1)
+	}
+	x, err := newKangaroo(grp, h, g, n, workers).run(ctx, workers)
+	if err != nil {
+		return nil, err
+	}
+	if neg {
+		x.Sub(x, bound)
+	}
+
+	return x, nil
+}
+
+// KangarooZp uses Pollard's kangaroo method to compute x in
+// [0, bound], or in [-bound, bound] if neg is true, such that
+// h = g^x mod p. The search is split among the given number of
+// workers, or GOMAXPROCS workers if it is not positive, each
+// making about 2 * sqrt(bound / workers) multiplications. It needs
+// memory independent of the bound, apart from the distinguished
+// points, a small fraction of all the visited ones. The method is
+// probabilistic: it returns an error if it does not find x after
+// a few attempts, which happens if x is not in the interval.
+func KangarooZp(ctx context.Context, h, g, p, bound *big.Int, neg bool, workers int) (*big.Int, error) {
+	if h == nil || g == nil || p == nil {
+		return nil, fmt.Errorf("elements cannot be nil")
+	}
+	return kangarooSearch(ctx, kangarooZp{p: p}, h, g, bound, neg, workers)
+}
+
+// KangarooBN256 is like KangarooZp, but computes x such that
+// h = g^x in the BN256.GT group.
+func KangarooBN256(ctx context.Context, h, g *bn256.GT, bound *big.Int, neg bool, workers int) (*big.Int, error) {
+	if h == nil || g == nil {
+		return nil, fmt.Errorf("elements cannot be nil")
+	}
+	return kangarooSearch(ctx, kangarooBN256{}, h, g, bound, neg, workers)
+}
+
+// SearchContext computes x in [0, bound], or in [-bound, bound] if
+// c.neg is set, such that h = g^x in the BN256.GT group. For bounds
+// below MaxBound it uses BabyStepGiantStepContext, which is fast for
+// small results, and for larger bounds KangarooBN256 with GOMAXPROCS
+// workers, which needs constant memory. If bound is nil, MaxBound is
+// used.
+func (c *CalcBN256) SearchContext(ctx context.Context, h, g *bn256.GT, bound *big.Int) (*big.Int, error) {
+	if bound == nil || bound.Cmp(MaxBound) < 0 {
+		return c.WithBound(bound).BabyStepGiantStepContext(ctx, h, g)
+	}
+	return KangarooBN256(ctx, h, g, bound, c.neg, 0)
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dlog
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/internal/keygen"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKangarooZp(t *testing.T) {
+	key, err := keygen.NewElGamal(128)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	bound := big.NewInt(1 << 24)
+
+	for _, neg := range []bool{false, true} {
+		xCheck, err := sample.NewUniformRange(new(big.Int).Neg(bound), bound).Sample()
+		if err != nil {
+			t.Fatalf("Error during random int generation: %v", err)
+		}
+		if !neg {
+			xCheck.Abs(xCheck)
+		}
+		h := new(big.Int).Exp(key.G, new(big.Int).Mod(xCheck, key.Q), key.P)
+
+		x, err := KangarooZp(context.Background(), h, key.G, key.P, bound, neg, 0)
+		if err != nil {
+			t.Fatalf("Error in kangaroo algorithm: %v", err)
+		}
+		assert.Equal(t, xCheck.Cmp(x), 0, "kangaroo result is wrong")
+	}
+}
+
+func TestKangarooBN256(t *testing.T) {
+	bound := big.NewInt(1 << 20)
+	xCheck, err := sample.NewUniformRange(new(big.Int).Neg(bound), bound).Sample()
+	if err != nil {
+		t.Fatalf("Error during random int generation: %v", err)
+	}
+	g := new(bn256.GT).ScalarBaseMult(big.NewInt(1))
+	h := new(bn256.GT).ScalarMult(g, new(big.Int).Mod(xCheck, bn256.Order))
+
+	x, err := KangarooBN256(context.Background(), h, g, bound, true, 4)
+	if err != nil {
+		t.Fatalf("Error in kangaroo algorithm: %v", err)
+	}
+	assert.Equal(t, xCheck.Cmp(x), 0, "kangaroo result is wrong")
+}
+
+func TestKangarooZp_Parallel(t *testing.T) {
+	key, err := keygen.NewElGamal(128)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), 32)
+	xCheck, err := sample.NewUniform(bound).Sample()
+	if err != nil {
+		t.Fatalf("Error during random int generation: %v", err)
+	}
+	h := new(big.Int).Exp(key.G, xCheck, key.P)
+
+	x, err := KangarooZp(context.Background(), h, key.G, key.P, bound, false, 4)
+	if err != nil {
+		t.Fatalf("Error in kangaroo algorithm: %v", err)
+	}
+	assert.Equal(t, xCheck.Cmp(x), 0, "kangaroo result is wrong")
+}
+
+func TestCalcBN256_SearchContext(t *testing.T) {
+	g := new(bn256.GT).ScalarBaseMult(big.NewInt(1))
+	h := new(bn256.GT).ScalarMult(g, big.NewInt(123456))
+	calc := NewCalc().InBN256().WithNeg()
+
+	x, err := calc.SearchContext(context.Background(), h, g, big.NewInt(1<<20))
+	if err != nil {
+		t.Fatalf("Error in search: %v", err)
+	}
+	assert.Equal(t, int64(123456), x.Int64())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = calc.SearchContext(ctx, h, g, new(big.Int).Lsh(MaxBound, 2))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestKangarooZp_Cancel(t *testing.T) {
+	key, err := keygen.NewElGamal(128)
+	if err != nil {
+		t.Fatalf("Error during parameters generation: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = KangarooZp(ctx, key.G, key.G, key.P, big.NewInt(1<<40), false, 2)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -17,6 +17,7 @@
 package dlog
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/big"
@@ -26,6 +27,13 @@ import (
 
 // Pollard's rho algorithm - simple, non-parallel version.
 func pollardRho(h, g, p, order *big.Int) (*big.Int, error) {
+	return PollardRho(context.Background(), h, g, p, order)
+}
+
+// PollardRho uses Pollard's rho algorithm to compute x in
+// [0, order), such that h = g^x mod p, where order is the order
+// of g. It stops and returns the error of ctx as soon as ctx is done.
+func PollardRho(ctx context.Context, h, g, p, order *big.Int) (*big.Int, error) {
 	n := new(big.Int).Set(order)
 
 	// a, b random from [1, n]
@@ -66,6 +74,11 @@ func pollardRho(h, g, p, order *big.Int) (*big.Int, error) {
 	one := big.NewInt(1)
 
 	for i := 0; i < iterations; i++ {
+		if i%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		iterate(x1, a1, b1)
 		iterate(x2, a2, b2)
 		iterate(x2, a2, b2)
@@ -123,12 +136,22 @@ func process(x, a, b *big.Int, iterate func(x, a, b *big.Int), c chan<- triple,
 			a: new(big.Int).Set(a),
 			b: new(big.Int).Set(b),
 		}
-		c <- xab
+		select {
+		case c <- xab:
+		case <-quit:
+			return
+		}
 	}
 }
 
 // Parallelized Pollard's rho algorithm.
 func pollardRhoParallel(h, g, p, order *big.Int) (*big.Int, error) {
+	return PollardRhoParallel(context.Background(), h, g, p, order)
+}
+
+// PollardRhoParallel is like PollardRho, but runs several
+// walks concurrently.
+func PollardRhoParallel(ctx context.Context, h, g, p, order *big.Int) (*big.Int, error) {
 	n := new(big.Int).Set(order)
 
 	iterate := func(x, a, b *big.Int) {
@@ -172,7 +195,13 @@ func pollardRhoParallel(h, g, p, order *big.Int) (*big.Int, error) {
 	}
 
 	for {
-		triple := <-c
+		var triple triple
+		select {
+		case triple = <-c:
+		case <-ctx.Done():
+			close(quit)
+			return nil, ctx.Err()
+		}
 		str := string(triple.x.Bytes())
 
 		if el, ok := triples[str]; ok {
@@ -212,6 +241,8 @@ func pollardRhoParallel(h, g, p, order *big.Int) (*big.Int, error) {
 				q.Add(q, nDivD)
 			}
 
+			// the walks were stopped, so there is nothing more to wait for
+			return nil, fmt.Errorf("error in Pollard rho: failed to find discrete logarithm")
 		} else {
 			triples[str] = triple
 		}
@@ -0,0 +1,397 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dlog
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+// Groups in which precomputation tables can be used.
+const (
+	GroupZp    = "Zp"
+	GroupBN256 = "bn256.GT"
+)
+
+// tableMagic starts every file holding a precomputation table.
+const tableMagic = "GOFEDLOG"
+
+const (
+	// keyLen is the length of the truncated SHA-1 hash of a group
+	// element, which identifies the element in a table.
+	keyLen = 10
+	// valLen is the length of the exponent of an element of a table.
+	valLen = 4
+	// entryLen is the length of an entry of a table.
+	entryLen = keyLen + valLen
+)
+
+// Table is a precomputed table of the small steps of the baby-step
+// giant-step method, i.e. of the powers g^i for 0 <= i < 2^bits
+// of a generator g. Building a table for big bounds takes long,
+// so tables can be saved to a file and loaded in other processes.
+//
+// A table consists of entries that map truncated hashes of the
+// powers to exponents, sorted by the hashes. When saved to a file,
+// the entries are preceded by a header, holding the group, the
+// modulus (if the group is Zp), the generator and the bound 2^bits,
+// and followed by a SHA-256 checksum of the file. Loaded tables are
+// memory-mapped where possible, so that they are not copied into
+// the memory of the process.
+type Table struct {
+	group string
+	p     *big.Int // modulus of Zp, nil in bn256.GT
+	g     []byte   // encoding of the generator
+	bits  int
+
+	entries []byte
+	mapped  []byte // memory-mapped file, if any
+}
+
+// NewTableZp builds a table of powers g^i mod p for 0 <= i < 2^bits.
+func NewTableZp(p, g *big.Int, bits int) (*Table, error) {
+	if p == nil || g == nil {
+		return nil, fmt.Errorf("modulus and generator cannot be nil")
+	}
+	if err := checkTableBits(bits); err != nil {
+		return nil, err
+	}
+	t := &Table{group: GroupZp, p: p, g: g.Bytes(), bits: bits}
+	n := 1 << uint(bits)
+	t.entries = make([]byte, 0, n*entryLen)
+	x := big.NewInt(1)
+	for i := 0; i < n; i++ {
+		t.entries = appendEntry(t.entries, keyZp(x), i)
+		x.Mul(x, g)
+		x.Mod(x, p)
+	}
+	sort.Sort(entries(t.entries))
+
+	return t, nil
+}
+
+// maxBoundTableBits limits the size of the tables built
+// by NewTableZpForBound.
+const maxBoundTableBits = 16
+
+// NewTableZpForBound builds a table of powers of g mod p for the
+// computation of many discrete logarithms within bound with
+// CalcZp.WithTable. The table holds about sqrt(bound) entries,
+// so that the baby steps need not be computed for each logarithm,
+// but at most 2^16 of them.
+func NewTableZpForBound(p, g, bound *big.Int) (*Table, error) {
+	bits := bound.BitLen()/2 + 1
+	if bits > maxBoundTableBits {
+		bits = maxBoundTableBits
+	}
+	return NewTableZp(p, g, bits)
+}
+
+// NewTableBN256 builds a table of powers g^i for 0 <= i < 2^bits of
+// the generator g of the BN256.GT group.
+func NewTableBN256(bits int) (*Table, error) {
+	if err := checkTableBits(bits); err != nil {
+		return nil, err
+	}
+	g := new(bn256.GT).ScalarBaseMult(big.NewInt(1))
+	t := &Table{group: GroupBN256, g: g.Marshal(), bits: bits}
+	n := 1 << uint(bits)
+	t.entries = make([]byte, 0, n*entryLen)
+	x := bn256.GetGTOne()
+	for i := 0; i < n; i++ {
+		t.entries = appendEntry(t.entries, keyBN256(x), i)
+		x = new(bn256.GT).Add(x, g)
+	}
+	sort.Sort(entries(t.entries))
+
+	return t, nil
+}
+
+func checkTableBits(bits int) error {
+	if bits < 1 || bits > 8*valLen-1 {
+		return fmt.Errorf("bits of a table should be between 1 and %d", 8*valLen-1)
+	}
+	return nil
+}
+
+// keyZp returns the key of x in a table over Zp.
+func keyZp(x *big.Int) []byte {
+	h := sha1.Sum(x.Bytes())
+	return h[:keyLen]
+}
+
+// keyBN256 returns the key of x in a table over BN256.GT,
+// which matches the keys of CalcBN256.Precomp.
+func keyBN256(x *bn256.GT) []byte {
+	h := sha1.Sum([]byte(x.String()))
+	return h[:keyLen]
+}
+
+func appendEntry(b, key []byte, i int) []byte {
+	var v [valLen]byte
+	binary.BigEndian.PutUint32(v[:], uint32(i))
+	b = append(b, key...)
+	return append(b, v[:]...)
+}
+
+// entries sorts the entries of a table by their keys.
+type entries []byte
+
+func (e entries) Len() int { return len(e) / entryLen }
+
+func (e entries) Less(i, j int) bool {
+	return bytes.Compare(e[i*entryLen:i*entryLen+keyLen], e[j*entryLen:j*entryLen+keyLen]) < 0
+}
+
+func (e entries) Swap(i, j int) {
+	var tmp [entryLen]byte
+	copy(tmp[:], e[i*entryLen:(i+1)*entryLen])
+	copy(e[i*entryLen:(i+1)*entryLen], e[j*entryLen:(j+1)*entryLen])
+	copy(e[j*entryLen:(j+1)*entryLen], tmp[:])
+}
+
+// lookup returns the exponent of the element with the given key.
+func (t *Table) lookup(key []byte) (*big.Int, bool) {
+	n := len(t.entries) / entryLen
+	i := sort.Search(n, func(i int) bool {
+		return bytes.Compare(t.entries[i*entryLen:i*entryLen+keyLen], key) >= 0
+	})
+	if i == n || !bytes.Equal(t.entries[i*entryLen:i*entryLen+keyLen], key) {
+		return nil, false
+	}
+	v := binary.BigEndian.Uint32(t.entries[i*entryLen+keyLen : (i+1)*entryLen])
+
+	return new(big.Int).SetUint64(uint64(v)), true
+}
+
+// IsZp reports whether t is a table of the powers of g mod p.
+func (t *Table) IsZp(p, g *big.Int) bool {
+	return t.group == GroupZp && t.p.Cmp(p) == 0 && t.generatorZp().Cmp(g) == 0
+}
+
+// IsBN256 reports whether t is a table of the powers of g
+// in the BN256.GT group.
+func (t *Table) IsBN256(g *bn256.GT) bool {
+	return t.group == GroupBN256 && bytes.Equal(t.g, g.Marshal())
+}
+
+// LookupZp returns x in [0, 2^bits) such that h = g^x mod p,
+// where g and p are those of a table over Zp, if there is one.
+func (t *Table) LookupZp(h *big.Int) (*big.Int, bool) {
+	if t.group != GroupZp {
+		return nil, false
+	}
+	return t.lookup(keyZp(h))
+}
+
+// LookupBN256 returns x in [0, 2^bits) such that h = g^x,
+// where g is the generator of a table over BN256.GT, if
+// there is one.
+func (t *Table) LookupBN256(h *bn256.GT) (*big.Int, bool) {
+	if t.group != GroupBN256 {
+		return nil, false
+	}
+	return t.lookup(keyBN256(h))
+}
+
+// Group returns the group of the table, i.e. GroupZp or GroupBN256.
+func (t *Table) Group() string {
+	return t.group
+}
+
+// Bits returns the number of bits of the bound 2^bits on the exponents
+// in the table.
+func (t *Table) Bits() int {
+	return t.bits
+}
+
+// generatorZp returns the generator of a table over Zp.
+func (t *Table) generatorZp() *big.Int {
+	return new(big.Int).SetBytes(t.g)
+}
+
+// header returns the encoded header of the table.
+func (t *Table) header() ([]byte, error) {
+	e := serial.NewEncoder("dlog.Table")
+	e.String(t.group)
+	e.BigInt(t.p)
+	e.Bytes(t.g)
+	e.BigInt(new(big.Int).Lsh(big.NewInt(1), uint(t.bits)))
+
+	return e.Data()
+}
+
+// WriteTo writes the table to w in the file format described
+// at Table.
+func (t *Table) WriteTo(w io.Writer) (int64, error) {
+	header, err := t.header()
+	if err != nil {
+		return 0, err
+	}
+	h := sha256.New()
+	mw := io.MultiWriter(w, h)
+
+	var n int64
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(header)))
+	for _, b := range [][]byte{[]byte(tableMagic), length[:], header, t.entries} {
+		k, err := mw.Write(b)
+		n += int64(k)
+		if err != nil {
+			return n, err
+		}
+	}
+	k, err := w.Write(h.Sum(nil))
+
+	return n + int64(k), err
+}
+
+// Save writes the table to a file at path.
+func (t *Table) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := t.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// LoadTable loads a table saved with Save from a file at path.
+// It checks the integrity of the file and returns an error if the
+// file is corrupted. The table should be closed with Close when
+// it is no longer needed.
+func LoadTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	b, mapped, err := mmapFile(f, fi.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := parseTable(b)
+	if err != nil {
+		if mapped {
+			_ = munmap(b)
+		}
+		return nil, fmt.Errorf("cannot load table %s: %v", path, err)
+	}
+	if mapped {
+		t.mapped = b
+	}
+
+	return t, nil
+}
+
+// ReadTable reads a table written with WriteTo from r into memory.
+func ReadTable(r io.Reader) (*Table, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+
+	return parseTable(buf.Bytes())
+}
+
+// parseTable parses and checks a table in the file format
+// described at Table. The entries of the table point into b.
+func parseTable(b []byte) (*Table, error) {
+	minLen := len(tableMagic) + 4 + sha256.Size
+	if len(b) < minLen || string(b[:len(tableMagic)]) != tableMagic {
+		return nil, fmt.Errorf("not a table of discrete logarithms")
+	}
+	body, sum := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+	if h := sha256.Sum256(body); !bytes.Equal(h[:], sum) {
+		return nil, fmt.Errorf("checksum mismatch")
+	}
+
+	headerLen := int(binary.BigEndian.Uint32(body[len(tableMagic):]))
+	body = body[len(tableMagic)+4:]
+	if headerLen > len(body) {
+		return nil, fmt.Errorf("truncated header")
+	}
+	d := serial.NewDecoder(body[:headerLen], "dlog.Table")
+	t := &Table{
+		group: d.String(),
+		p:     d.BigInt(),
+	}
+	t.g = d.Bytes()
+	bound := d.BigInt()
+	if err := d.Finish(); err != nil {
+		return nil, err
+	}
+
+	switch t.group {
+	case GroupZp:
+		if t.p == nil || t.p.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid modulus")
+		}
+	case GroupBN256:
+		g := new(bn256.GT).ScalarBaseMult(big.NewInt(1))
+		if t.p != nil || !bytes.Equal(t.g, g.Marshal()) {
+			return nil, fmt.Errorf("invalid generator")
+		}
+	default:
+		return nil, fmt.Errorf("unknown group %q", t.group)
+	}
+	if bound == nil || bound.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid bound")
+	}
+	t.bits = bound.BitLen() - 1
+	if err := checkTableBits(t.bits); err != nil || bound.Cmp(new(big.Int).Lsh(big.NewInt(1), uint(t.bits))) != 0 {
+		return nil, fmt.Errorf("invalid bound %s", bound)
+	}
+
+	t.entries = body[headerLen:]
+	if len(t.entries) != entryLen<<uint(t.bits) {
+		return nil, fmt.Errorf("expected %d entries", 1<<uint(t.bits))
+	}
+
+	return t, nil
+}
+
+// Close releases the memory of a table loaded with LoadTable.
+// The table cannot be used after it is closed.
+func (t *Table) Close() error {
+	t.entries = nil
+	if t.mapped == nil {
+		return nil
+	}
+	b := t.mapped
+	t.mapped = nil
+
+	return munmap(b)
+}
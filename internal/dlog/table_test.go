@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package dlog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/internal"
+	"github.com/fentec-project/gofe/internal/keygen"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable_Zp(t *testing.T) {
+	key, err := keygen.NewElGamal(128)
+	if err != nil {
+		t.Fatalf("Error in ElGamal key generation: %v", err)
+	}
+
+	table, err := NewTableZp(key.P, key.G, 10)
+	if err != nil {
+		t.Fatalf("Error during table creation: %v", err)
+	}
+	dir, err := ioutil.TempDir("", "dlog")
+	if err != nil {
+		t.Fatalf("Error during creation of a directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "zp.table")
+	if err := table.Save(path); err != nil {
+		t.Fatalf("Error during saving of the table: %v", err)
+	}
+	loaded, err := LoadTable(path)
+	if err != nil {
+		t.Fatalf("Error during loading of the table: %v", err)
+	}
+	defer loaded.Close()
+	assert.Equal(t, GroupZp, loaded.Group())
+	assert.Equal(t, 10, loaded.Bits())
+
+	calc, err := NewCalc().InZp(key.P, nil)
+	if err != nil {
+		t.Fatal("Error in creation of new CalcZp:", err)
+	}
+	calc, err = calc.WithBound(big.NewInt(100000000)).WithNeg().WithTable(loaded)
+	if err != nil {
+		t.Fatalf("Error when setting the table: %v", err)
+	}
+
+	sampler := sample.NewUniformRange(big.NewInt(-100000000), big.NewInt(100000000))
+	for _, xCheck := range []*big.Int{big.NewInt(0), big.NewInt(1023), big.NewInt(-1024), nil} {
+		if xCheck == nil {
+			if xCheck, err = sampler.Sample(); err != nil {
+				t.Fatalf("Error during random int generation: %v", err)
+			}
+		}
+		h := internal.ModExp(key.G, xCheck, key.P)
+		x, err := calc.BabyStepGiantStep(h, key.G)
+		if err != nil {
+			t.Fatalf("Error in baby step - giant step algorithm: %v", err)
+		}
+		assert.Equal(t, 0, xCheck.Cmp(x), "BabyStepGiantStep result is wrong")
+	}
+
+	// a table of another group is rejected
+	other, err := NewTableBN256(2)
+	if err != nil {
+		t.Fatalf("Error during table creation: %v", err)
+	}
+	_, err = calc.WithTable(other)
+	assert.Error(t, err)
+}
+
+func TestTable_BN256(t *testing.T) {
+	table, err := NewTableBN256(8)
+	if err != nil {
+		t.Fatalf("Error during table creation: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := table.WriteTo(&buf); err != nil {
+		t.Fatalf("Error during writing of the table: %v", err)
+	}
+	read, err := ReadTable(&buf)
+	if err != nil {
+		t.Fatalf("Error during reading of the table: %v", err)
+	}
+	assert.Equal(t, GroupBN256, read.Group())
+
+	bound := big.NewInt(1000000)
+	calc, err := NewCalc().InBN256().WithBound(bound).WithNeg().WithTable(read)
+	if err != nil {
+		t.Fatalf("Error when setting the table: %v", err)
+	}
+
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+	xCheck, err := sampler.Sample()
+	if err != nil {
+		t.Fatalf("Error during random int generation: %v", err)
+	}
+	g := new(bn256.GT).ScalarBaseMult(big.NewInt(1))
+	h := new(bn256.GT).ScalarMult(g, new(big.Int).Abs(xCheck))
+	if xCheck.Sign() < 0 {
+		h.Neg(h)
+	}
+	x, err := calc.BabyStepGiantStep(h, g)
+	if err != nil {
+		t.Fatalf("Error in baby step - giant step algorithm: %v", err)
+	}
+	assert.Equal(t, 0, xCheck.Cmp(x), "BabyStepGiantStep in BN256 returns wrong dlog")
+}
+
+func TestTable_Corrupted(t *testing.T) {
+	table, err := NewTableBN256(4)
+	if err != nil {
+		t.Fatalf("Error during table creation: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := table.WriteTo(&buf); err != nil {
+		t.Fatalf("Error during writing of the table: %v", err)
+	}
+	b := buf.Bytes()
+
+	// any flipped bit breaks the checksum
+	for _, i := range []int{0, len(tableMagic) + 5, len(b) / 2, len(b) - 1} {
+		corrupted := append([]byte(nil), b...)
+		corrupted[i] ^= 1
+		_, err := ReadTable(bytes.NewReader(corrupted))
+		assert.Error(t, err)
+	}
+	_, err = ReadTable(bytes.NewReader(b[:len(b)-1]))
+	assert.Error(t, err)
+	_, err = ReadTable(bytes.NewReader(nil))
+	assert.Error(t, err)
+
+	_, err = NewTableBN256(0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package internal
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// FixedBase holds a table of precomputed powers of a fixed base g
+// in Z_m*, which speeds up the computation of powers of g when many
+// of them are needed.
+//
+// The exponent is split into windows of w bits, and the table holds
+// g^(d * 2^(w*j)) for every digit d of a window and every window j,
+// so that g^x is a product of one table entry per window, with no
+// squarings needed. The table holds ceil(bits/w) * 2^w elements.
+type FixedBase struct {
+	g, m   *big.Int
+	w      uint
+	bits   int
+	powers [][]*big.Int
+}
+
+// fixedBaseWindow is the number of bits of the exponent
+// covered by one table entry.
+const fixedBaseWindow = 4
+
+// fixedBaseMinUses is the number of powers of a base from which
+// precomputing its table pays off. For fewer powers computing them
+// without a table is faster.
+const fixedBaseMinUses = 16
+
+// fixedBaseMaxMemory bounds the memory in bytes taken by the tables
+// built by NewFixedBases. A table for a 2048-bit modulus takes about
+// 2 MB, so the bound allows for about 64 of them.
+var fixedBaseMaxMemory = 128 << 20
+
+// fixedBaseSize returns the approximate memory in bytes taken by the
+// table for a modulus m and exponents of at most bits bits.
+func fixedBaseSize(m *big.Int, bits int) int {
+	windows := (bits + fixedBaseWindow - 1) / fixedBaseWindow
+	return (windows << fixedBaseWindow) * (m.BitLen()/8 + 1)
+}
+
+// NewFixedBase precomputes powers of g in Z_m* for exponents of
+// at most bits bits.
+func NewFixedBase(g, m *big.Int, bits int) *FixedBase {
+	w := uint(fixedBaseWindow)
+	windows := (bits + int(w) - 1) / int(w)
+	powers := make([][]*big.Int, windows)
+	base := new(big.Int).Mod(g, m)
+	for j := range powers {
+		row := make([]*big.Int, 1<<w)
+		row[0] = big.NewInt(1)
+		for d := 1; d < len(row); d++ {
+			row[d] = new(big.Int).Mul(row[d-1], base)
+			row[d].Mod(row[d], m)
+		}
+		powers[j] = row
+		// the base of the next window is g^(2^(w*(j+1)))
+		base = new(big.Int).Mul(row[len(row)-1], base)
+		base.Mod(base, m)
+	}
+
+	return &FixedBase{
+		g:      g,
+		m:      m,
+		w:      w,
+		bits:   windows * int(w),
+		powers: powers,
+	}
+}
+
+// Exp calculates g^x in Z_m*, even if x < 0. If there is no table
+// or x has more bits than the table covers, the power is computed
+// without the table.
+func (f *FixedBase) Exp(x *big.Int) *big.Int {
+	if f.powers == nil || x.BitLen() > f.bits {
+		return ModExp(f.g, x, f.m)
+	}
+
+	// Bit returns the bits of the two's complement of a negative
+	// number, hence the absolute value
+	xAbs := new(big.Int).Abs(x)
+	ret := big.NewInt(1)
+	for j := range f.powers {
+		d := uint(0)
+		for k := uint(0); k < f.w; k++ {
+			d |= xAbs.Bit(j*int(f.w)+int(k)) << k
+		}
+		if d != 0 {
+			ret.Mul(ret, f.powers[j][d])
+			ret.Mod(ret, f.m)
+		}
+	}
+	if x.Sign() == -1 {
+		ret.ModInverse(ret, f.m)
+	}
+
+	return ret
+}
+
+// NewFixedBases precomputes concurrently tables for the bases in gs,
+// of which at least uses powers will be computed each. The tables are
+// only built if they pay off for the given number of uses, and only
+// for as many of the leading bases in gs as fit into the memory bound
+// fixedBaseMaxMemory, so the bases used the most should come first.
+// The powers of the other bases are computed without a table.
+func NewFixedBases(gs []*big.Int, m *big.Int, bits, uses int) []*FixedBase {
+	n := 0
+	if uses >= fixedBaseMinUses {
+		n = fixedBaseMaxMemory / fixedBaseSize(m, bits)
+	}
+	if n > len(gs) {
+		n = len(gs)
+	}
+
+	tables := make([]*FixedBase, len(gs))
+	for i := n; i < len(gs); i++ {
+		tables[i] = &FixedBase{g: gs[i], m: m}
+	}
+	_ = Parallel(n, func(i int) error {
+		tables[i] = NewFixedBase(gs[i], m, bits)
+		return nil
+	})
+
+	return tables
+}
+
+// Parallel calls f(i) for every i in [0, n) on a pool of as many
+// workers as there are CPUs available. It returns the first error
+// returned by f; after an error the remaining calls are skipped.
+func Parallel(n int, f func(i int) error) error {
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for k := 0; k < workers; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mu.Lock()
+				failed := firstErr != nil
+				mu.Unlock()
+				if failed {
+					continue
+				}
+				if err := f(i); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
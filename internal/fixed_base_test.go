@@ -0,0 +1,94 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package internal
+
+import (
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedBase(t *testing.T) {
+	m := big.NewInt(1000003)
+	g := big.NewInt(2)
+	f := NewFixedBase(g, m, 20)
+
+	for _, x := range []int64{0, 1, 2, 15, 16, 17, 12345, 1<<20 - 1, -1, -12345,
+		// exponents not covered by the table
+		1 << 20, 1 << 40, -(1 << 40)} {
+		e := big.NewInt(x)
+		assert.Equal(t, 0, ModExp(g, e, m).Cmp(f.Exp(e)), "power of the base should match for %d", x)
+	}
+}
+
+func TestNewFixedBases(t *testing.T) {
+	m := big.NewInt(1000003)
+	gs := []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(5)}
+	check := func(tables []*FixedBase) {
+		for i, f := range tables {
+			for _, x := range []int64{0, 1, 12345, -12345} {
+				e := big.NewInt(x)
+				assert.Equal(t, 0, ModExp(gs[i], e, m).Cmp(f.Exp(e)), "power of the base should match for %d", x)
+			}
+		}
+	}
+
+	// tables are built only if they pay off
+	tables := NewFixedBases(gs, m, 20, fixedBaseMinUses)
+	for _, f := range tables {
+		assert.NotNil(t, f.powers)
+	}
+	check(tables)
+	for _, f := range NewFixedBases(gs, m, 20, 1) {
+		assert.Nil(t, f.powers)
+	}
+	assert.Empty(t, NewFixedBases(nil, m, 20, 0))
+
+	// and only as many of them as fit into memory
+	defer func(max int) { fixedBaseMaxMemory = max }(fixedBaseMaxMemory)
+	fixedBaseMaxMemory = 2 * fixedBaseSize(m, 20)
+	tables = NewFixedBases(gs, m, 20, fixedBaseMinUses)
+	assert.NotNil(t, tables[0].powers)
+	assert.NotNil(t, tables[1].powers)
+	assert.Nil(t, tables[2].powers)
+	check(tables)
+}
+
+func TestParallel(t *testing.T) {
+	n := 100
+	squares := make([]int, n)
+	err := Parallel(n, func(i int) error {
+		squares[i] = i * i
+		return nil
+	})
+	assert.NoError(t, err)
+	for i, s := range squares {
+		assert.Equal(t, i*i, s)
+	}
+
+	var calls int32
+	err = Parallel(n, func(i int) error {
+		atomic.AddInt32(&calls, 1)
+		return fmt.Errorf("call %d failed", i)
+	})
+	assert.Error(t, err)
+	assert.True(t, atomic.LoadInt32(&calls) <= int32(n))
+
+	assert.NoError(t, Parallel(0, func(i int) error { return nil }))
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package internal
+
+import (
+	"math/big"
+)
+
+// MultiExp holds tables of small powers of fixed bases g_1, ..., g_n
+// in Z_m*, which speed up the computation of products
+// g_1^x_1 * ... * g_n^x_n when many of them are needed.
+//
+// Products are computed with Straus' method: the exponents are
+// processed together in windows of w bits from the most significant
+// one, so that the squarings are shared among all the bases and
+// only one multiplication per base and window is needed. The tables
+// hold 2^w elements per base.
+type MultiExp struct {
+	m      *big.Int
+	w      uint
+	powers [][]*big.Int
+}
+
+// multiExpWindow is the number of bits of the exponents
+// processed at once.
+const multiExpWindow = 4
+
+// NewMultiExp precomputes the tables for bases gs in Z_m*.
+func NewMultiExp(gs []*big.Int, m *big.Int) *MultiExp {
+	w := uint(multiExpWindow)
+	powers := make([][]*big.Int, len(gs))
+	for i, g := range gs {
+		base := new(big.Int).Mod(g, m)
+		row := make([]*big.Int, 1<<w)
+		row[0] = big.NewInt(1)
+		for d := 1; d < len(row); d++ {
+			row[d] = new(big.Int).Mul(row[d-1], base)
+			row[d].Mod(row[d], m)
+		}
+		powers[i] = row
+	}
+
+	return &MultiExp{
+		m:      m,
+		w:      w,
+		powers: powers,
+	}
+}
+
+// Exp calculates the product of g_i^x_i in Z_m* for the bases g_i
+// of the tables, even if some x_i < 0. It panics if xs does not
+// have as many elements as there are bases.
+func (e *MultiExp) Exp(xs []*big.Int) *big.Int {
+	if len(xs) != len(e.powers) {
+		panic("internal: number of exponents and bases differ")
+	}
+
+	// Bit returns the bits of the two's complement of a negative
+	// number, hence the absolute values; powers with negative
+	// exponents are accumulated separately and inverted at the end
+	xAbs := make([]*big.Int, len(xs))
+	bits := 0
+	for i, x := range xs {
+		xAbs[i] = new(big.Int).Abs(x)
+		if xAbs[i].BitLen() > bits {
+			bits = xAbs[i].BitLen()
+		}
+	}
+	windows := (bits + int(e.w) - 1) / int(e.w)
+
+	pos := big.NewInt(1)
+	neg := big.NewInt(1)
+	for j := windows - 1; j >= 0; j-- {
+		if j != windows-1 {
+			for k := uint(0); k < e.w; k++ {
+				pos.Mul(pos, pos)
+				pos.Mod(pos, e.m)
+				neg.Mul(neg, neg)
+				neg.Mod(neg, e.m)
+			}
+		}
+		for i, x := range xAbs {
+			d := uint(0)
+			for k := uint(0); k < e.w; k++ {
+				d |= x.Bit(j*int(e.w)+int(k)) << k
+			}
+			if d == 0 {
+				continue
+			}
+			acc := pos
+			if xs[i].Sign() == -1 {
+				acc = neg
+			}
+			acc.Mul(acc, e.powers[i][d])
+			acc.Mod(acc, e.m)
+		}
+	}
+	if neg.Cmp(big.NewInt(1)) != 0 {
+		neg.ModInverse(neg, e.m)
+		pos.Mul(pos, neg)
+		pos.Mod(pos, e.m)
+	}
+
+	return pos
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package internal
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiExp(t *testing.T) {
+	m := big.NewInt(1000003)
+	gs := []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(5)}
+	e := NewMultiExp(gs, m)
+
+	for _, xs := range [][]int64{
+		{0, 0, 0},
+		{1, 2, 3},
+		{15, 16, 17},
+		{-1, 12345, 0},
+		{1 << 40, -(1 << 20), -7},
+	} {
+		exps := make([]*big.Int, len(xs))
+		prod := big.NewInt(1)
+		for i, x := range xs {
+			exps[i] = big.NewInt(x)
+			prod.Mul(prod, ModExp(gs[i], exps[i], m))
+			prod.Mod(prod, m)
+		}
+		assert.Equal(t, 0, prod.Cmp(e.Exp(exps)), "product of powers should match for %v", xs)
+	}
+}
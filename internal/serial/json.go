@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serial
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/fentec-project/bn256"
+)
+
+// The JSON encoding of an object is a JSON object holding a "type"
+// and a "version" member, identifying the type (for example
+// "abe.FAMECipher") and the version of the encoding, and a member
+// for each of the fields of the object. Big integers are encoded as
+// decimal strings (hexadecimal strings with a 0x prefix are accepted
+// when decoding), elements of bn256 groups as base64 strings of their
+// Marshal representation, and nil values as null.
+
+// Header identifies the type and the version of the encoding of
+// a JSON object. It is meant to be embedded in the structs that
+// are used for the JSON encoding.
+type Header struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+// NewHeader returns a Header for the type identified by tag.
+func NewHeader(tag string) Header {
+	return Header{Type: tag, Version: int(Version)}
+}
+
+// Check returns an error if h does not match the current version
+// and the type identified by tag.
+func (h Header) Check(tag string) error {
+	if h.Type != tag {
+		return fmt.Errorf("cannot decode %s: data holds %q", tag, h.Type)
+	}
+	if h.Version != int(Version) {
+		return fmt.Errorf("cannot decode %s: unsupported encoding version %d", tag, h.Version)
+	}
+
+	return nil
+}
+
+// BigInt is a big.Int with a JSON encoding. Pointers to big.Int
+// can be converted to pointers to BigInt and back.
+type BigInt big.Int
+
+// MarshalJSON encodes x as a decimal string.
+func (x *BigInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*big.Int)(x).String())
+}
+
+// UnmarshalJSON decodes a decimal or a 0x prefixed hexadecimal
+// string into x.
+func (x *BigInt) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	digits, base := s, 10
+	neg := strings.HasPrefix(digits, "-")
+	if neg {
+		digits = digits[1:]
+	}
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		digits, base = digits[2:], 16
+	}
+	// SetString would also accept a sign, which was already removed
+	if digits == "" || digits[0] == '+' || digits[0] == '-' {
+		return fmt.Errorf("malformed big integer %q", s)
+	}
+	v, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return fmt.Errorf("malformed big integer %q", s)
+	}
+	if neg {
+		v.Neg(v)
+	}
+	*x = BigInt(*v)
+
+	return nil
+}
+
+// G1 is a bn256.G1 element with a JSON encoding. Pointers to
+// bn256.G1 can be converted to pointers to G1 and back.
+type G1 bn256.G1
+
+// MarshalJSON encodes p as a base64 string.
+func (p *G1) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*bn256.G1)(p).Marshal())
+}
+
+// UnmarshalJSON decodes a base64 string into p.
+func (p *G1) UnmarshalJSON(b []byte) error {
+	data, err := decodeBase64(b)
+	if err != nil {
+		return err
+	}
+	if rest, err := (*bn256.G1)(p).Unmarshal(data); err != nil || len(rest) != 0 {
+		return fmt.Errorf("malformed encoding of G1 element")
+	}
+
+	return nil
+}
+
+// G2 is a bn256.G2 element with a JSON encoding. Pointers to
+// bn256.G2 can be converted to pointers to G2 and back.
+type G2 bn256.G2
+
+// MarshalJSON encodes p as a base64 string.
+func (p *G2) MarshalJSON() ([]byte, error) {
+	return json.Marshal(marshalG2((*bn256.G2)(p)))
+}
+
+// UnmarshalJSON decodes a base64 string into p.
+func (p *G2) UnmarshalJSON(b []byte) error {
+	data, err := decodeBase64(b)
+	if err != nil {
+		return err
+	}
+	if rest, err := (*bn256.G2)(p).Unmarshal(data); err != nil || len(rest) != 0 {
+		return fmt.Errorf("malformed encoding of G2 element")
+	}
+
+	return nil
+}
+
+// GT is a bn256.GT element with a JSON encoding. Pointers to
+// bn256.GT can be converted to pointers to GT and back.
+type GT bn256.GT
+
+// MarshalJSON encodes p as a base64 string.
+func (p *GT) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*bn256.GT)(p).Marshal())
+}
+
+// UnmarshalJSON decodes a base64 string into p.
+func (p *GT) UnmarshalJSON(b []byte) error {
+	data, err := decodeBase64(b)
+	if err != nil {
+		return err
+	}
+	if rest, err := (*bn256.GT)(p).Unmarshal(data); err != nil || len(rest) != 0 {
+		return fmt.Errorf("malformed encoding of GT element")
+	}
+
+	return nil
+}
+
+func decodeBase64(b []byte) ([]byte, error) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(s)
+}
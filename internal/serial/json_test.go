@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package serial
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBigIntJSON(t *testing.T) {
+	x, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	b, err := json.Marshal((*BigInt)(x))
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	assert.Equal(t, `"-123456789012345678901234567890"`, string(b))
+
+	var dec BigInt
+	if err := json.Unmarshal(b, &dec); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	assert.Equal(t, 0, x.Cmp((*big.Int)(&dec)))
+
+	// hexadecimal strings are accepted as well
+	if err := json.Unmarshal([]byte(`"-0xff"`), &dec); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	assert.Equal(t, big.NewInt(-255), (*big.Int)(&dec))
+
+	for _, s := range []string{`""`, `"-"`, `"0x"`, `"--1"`, `"0x-1"`, `"12a"`, `12`} {
+		assert.Error(t, json.Unmarshal([]byte(s), &dec), s)
+	}
+}
+
+func TestGroupElementsJSON(t *testing.T) {
+	g1 := new(bn256.G1).ScalarBaseMult(big.NewInt(3))
+	g2 := new(bn256.G2).ScalarBaseMult(big.NewInt(4))
+	gt := new(bn256.GT).ScalarBaseMult(big.NewInt(5))
+	b, err := json.Marshal([]interface{}{(*G1)(g1), (*G2)(g2), (*GT)(gt)})
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+
+	var dec struct {
+		G1 *G1
+		G2 *G2
+		GT *GT
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	assert.NoError(t, json.Unmarshal(raw[0], &dec.G1))
+	assert.NoError(t, json.Unmarshal(raw[1], &dec.G2))
+	assert.NoError(t, json.Unmarshal(raw[2], &dec.GT))
+	assert.Equal(t, g1.String(), (*bn256.G1)(dec.G1).String())
+	assert.Equal(t, g2.String(), (*bn256.G2)(dec.G2).String())
+	assert.Equal(t, gt.String(), (*bn256.GT)(dec.GT).String())
+
+	// an element of one group cannot be decoded as an element of another
+	assert.Error(t, json.Unmarshal(raw[0], &dec.G2))
+	assert.Error(t, json.Unmarshal([]byte(`"not base64"`), &dec.G1))
+}
+
+func TestGroupElementsJSON_Identity(t *testing.T) {
+	g2 := new(bn256.G2).ScalarBaseMult(big.NewInt(0))
+	b, err := json.Marshal((*G2)(g2))
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	var dec *G2
+	if err := json.Unmarshal(b, &dec); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+	assert.Equal(t, g2.String(), (*bn256.G2)(dec).String())
+}
+
+func TestHeaderCheck(t *testing.T) {
+	h := NewHeader("test")
+	assert.NoError(t, h.Check("test"))
+	assert.Error(t, h.Check("other"))
+	h.Version++
+	assert.Error(t, h.Check("test"))
+}
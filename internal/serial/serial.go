@@ -0,0 +1,626 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package serial implements the canonical binary encoding shared by
+// all the keys, ciphertexts and parameters of the library.
+//
+// Every encoded object starts with a header consisting of a version
+// byte followed by a type tag (for example "simple.DDHParams"), so that
+// a decoder can reject data that belongs to a different type or to
+// an unsupported version of the format. The header is followed by the
+// fields of the object in a fixed order:
+//
+// - integers are encoded as zig-zag varints,
+// - lengths are encoded as unsigned varints,
+// - big integers are encoded with a sign byte (0 for nil, 1 for
+// non-negative, 2 for negative values) followed by the length
+// prefixed big-endian absolute value,
+// - big floats are encoded with their GobEncode representation,
+// - elements of bn256 groups are encoded with their Marshal
+// representation and length prefixed (length 0 for nil),
+// - maps are encoded as the number of entries followed by the
+// entries ordered by their keys,
+// - nested objects are length prefixed and carry their own header.
+package serial
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/fentec-project/bn256"
+)
+
+// Version is the current version of the binary encoding.
+const Version byte = 1
+
+// maxLen limits the lengths read from encoded data, so that malformed
+// inputs cannot trigger huge allocations.
+const maxLen = 1 << 30
+
+// Encoder builds a binary encoding of an object. The first error
+// that occurs while encoding is remembered and returned by Data.
+type Encoder struct {
+	buf []byte
+	err error
+}
+
+// NewEncoder returns an Encoder with a header for the type
+// identified by tag already written.
+func NewEncoder(tag string) *Encoder {
+	e := &Encoder{buf: []byte{Version}}
+	e.String(tag)
+
+	return e
+}
+
+// Data returns the encoded bytes, or the first error that
+// occurred while encoding.
+func (e *Encoder) Data() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return e.buf, nil
+}
+
+// Int writes an integer.
+func (e *Encoder) Int(x int) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], int64(x))
+	e.buf = append(e.buf, tmp[:n]...)
+}
+
+// OptionalInt writes an integer that is omitted if it is 0. It can only
+// be used for the last field of an object, so that the field can be
+// added to a type without changing the encoding of the objects that
+// were encoded before.
+func (e *Encoder) OptionalInt(x int) {
+	if x != 0 {
+		e.Int(x)
+	}
+}
+
+// Len writes a non-negative length.
+func (e *Encoder) Len(n int) {
+	var tmp [binary.MaxVarintLen64]byte
+	k := binary.PutUvarint(tmp[:], uint64(n))
+	e.buf = append(e.buf, tmp[:k]...)
+}
+
+// Bool writes a boolean value.
+func (e *Encoder) Bool(b bool) {
+	if b {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+// Bytes writes a length prefixed slice of bytes.
+func (e *Encoder) Bytes(b []byte) {
+	e.Len(len(b))
+	e.buf = append(e.buf, b...)
+}
+
+// String writes a length prefixed string.
+func (e *Encoder) String(s string) {
+	e.Bytes([]byte(s))
+}
+
+// Strings writes a slice of strings.
+func (e *Encoder) Strings(s []string) {
+	e.Len(len(s))
+	for _, v := range s {
+		e.String(v)
+	}
+}
+
+// Ints writes a slice of integers.
+func (e *Encoder) Ints(s []int) {
+	e.Len(len(s))
+	for _, v := range s {
+		e.Int(v)
+	}
+}
+
+// StringIntMap writes a map from strings to integers. The entries
+// are written ordered by their keys, so that the encoding is canonical.
+func (e *Encoder) StringIntMap(m map[string]int) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	e.Len(len(keys))
+	for _, k := range keys {
+		e.String(k)
+		e.Int(m[k])
+	}
+}
+
+// IntIntMap writes a map from integers to integers. The entries
+// are written ordered by their keys, so that the encoding is canonical.
+func (e *Encoder) IntIntMap(m map[int]int) {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	e.Len(len(keys))
+	for _, k := range keys {
+		e.Int(k)
+		e.Int(m[k])
+	}
+}
+
+// BigInt writes a big integer, which might be nil.
+func (e *Encoder) BigInt(x *big.Int) {
+	switch {
+	case x == nil:
+		e.buf = append(e.buf, 0)
+		return
+	case x.Sign() < 0:
+		e.buf = append(e.buf, 2)
+	default:
+		e.buf = append(e.buf, 1)
+	}
+	e.Bytes(x.Bytes())
+}
+
+// BigInts writes a slice of big integers.
+func (e *Encoder) BigInts(s []*big.Int) {
+	e.Len(len(s))
+	for _, v := range s {
+		e.BigInt(v)
+	}
+}
+
+// BigFloat writes a big float, which might be nil.
+func (e *Encoder) BigFloat(x *big.Float) {
+	if x == nil {
+		e.Bytes(nil)
+		return
+	}
+	b, err := x.GobEncode()
+	if err != nil && e.err == nil {
+		e.err = err
+	}
+	e.Bytes(b)
+}
+
+// G1 writes an element of the bn256.G1 group, which might be nil.
+func (e *Encoder) G1(p *bn256.G1) {
+	if p == nil {
+		e.Bytes(nil)
+		return
+	}
+	e.Bytes(p.Marshal())
+}
+
+// G2 writes an element of the bn256.G2 group, which might be nil.
+func (e *Encoder) G2(p *bn256.G2) {
+	if p == nil {
+		e.Bytes(nil)
+		return
+	}
+	e.Bytes(marshalG2(p))
+}
+
+// marshalG2 returns the Marshal representation of p. The identity
+// element is marshaled by bn256 into a single byte that its own
+// Unmarshal rejects, so it is written as the all-zero encoding
+// that Unmarshal decodes back into the identity.
+func marshalG2(p *bn256.G2) []byte {
+	b := p.Marshal()
+	if len(b) < 4*32 {
+		return make([]byte, 4*32)
+	}
+	return b
+}
+
+// GT writes an element of the bn256.GT group, which might be nil.
+func (e *Encoder) GT(p *bn256.GT) {
+	if p == nil {
+		e.Bytes(nil)
+		return
+	}
+	e.Bytes(p.Marshal())
+}
+
+// Value writes a nested object. If present is false, an empty
+// value is written, which is decoded as nil.
+func (e *Encoder) Value(m encoding.BinaryMarshaler, present bool) {
+	if !present {
+		e.Bytes(nil)
+		return
+	}
+	b, err := m.MarshalBinary()
+	if err != nil && e.err == nil {
+		e.err = err
+	}
+	e.Bytes(b)
+}
+
+// Decoder reads a binary encoding produced by Encoder. The first
+// error that occurs is remembered and all subsequent reads return
+// zero values, so that the error only needs to be checked once
+// by calling Finish.
+type Decoder struct {
+	data []byte
+	err  error
+}
+
+// NewDecoder returns a Decoder for data, after checking that the
+// header matches the version and the type identified by tag.
+func NewDecoder(data []byte, tag string) *Decoder {
+	d := &Decoder{data: data}
+	if len(data) == 0 {
+		d.err = fmt.Errorf("cannot decode %s: empty input", tag)
+		return d
+	}
+	if data[0] != Version {
+		d.err = fmt.Errorf("cannot decode %s: unsupported encoding version %d", tag, data[0])
+		return d
+	}
+	d.data = data[1:]
+	if got := d.String(); d.err == nil && got != tag {
+		d.err = fmt.Errorf("cannot decode %s: data holds %s", tag, got)
+	}
+
+	return d
+}
+
+// Finish returns the first error that occurred while decoding, or an
+// error if not all of the data was consumed.
+func (d *Decoder) Finish() error {
+	if d.err == nil && len(d.data) != 0 {
+		d.err = fmt.Errorf("%d trailing bytes after the encoded value", len(d.data))
+	}
+
+	return d.err
+}
+
+func (d *Decoder) fail(what string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("malformed encoding of %s", what)
+	}
+}
+
+// capacity bounds the capacity preallocated for n elements by
+// the number of remaining bytes.
+func (d *Decoder) capacity(n int) int {
+	if n > len(d.data) {
+		return len(d.data)
+	}
+
+	return n
+}
+
+// Int reads an integer.
+func (d *Decoder) Int() int {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Varint(d.data)
+	if n <= 0 || int64(int(x)) != x {
+		d.fail("integer")
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return int(x)
+}
+
+// OptionalInt reads an integer written by Encoder.OptionalInt, which
+// is 0 if all of the data was consumed. An encoded 0 is rejected,
+// since it is not canonical.
+func (d *Decoder) OptionalInt() int {
+	if d.err != nil || len(d.data) == 0 {
+		return 0
+	}
+	x := d.Int()
+	if d.err == nil && x == 0 {
+		d.fail("optional integer")
+	}
+
+	return x
+}
+
+// Len reads a length.
+func (d *Decoder) Len() int {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.data)
+	if n <= 0 || x > maxLen {
+		d.fail("length")
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return int(x)
+}
+
+// Bool reads a boolean value.
+func (d *Decoder) Bool() bool {
+	if d.err != nil {
+		return false
+	}
+	if len(d.data) == 0 || d.data[0] > 1 {
+		d.fail("boolean")
+		return false
+	}
+	b := d.data[0] == 1
+	d.data = d.data[1:]
+
+	return b
+}
+
+// Bytes reads a length prefixed slice of bytes.
+func (d *Decoder) Bytes() []byte {
+	n := d.Len()
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data) {
+		d.fail("bytes")
+		return nil
+	}
+	b := make([]byte, n)
+	copy(b, d.data[:n])
+	d.data = d.data[n:]
+
+	return b
+}
+
+// String reads a length prefixed string.
+func (d *Decoder) String() string {
+	return string(d.Bytes())
+}
+
+// Strings reads a slice of strings.
+func (d *Decoder) Strings() []string {
+	n := d.Len()
+	if d.err != nil {
+		return nil
+	}
+	s := make([]string, 0, d.capacity(n))
+	for i := 0; i < n && d.err == nil; i++ {
+		s = append(s, d.String())
+	}
+
+	return s
+}
+
+// Ints reads a slice of integers.
+func (d *Decoder) Ints() []int {
+	n := d.Len()
+	if d.err != nil {
+		return nil
+	}
+	s := make([]int, 0, d.capacity(n))
+	for i := 0; i < n && d.err == nil; i++ {
+		s = append(s, d.Int())
+	}
+
+	return s
+}
+
+// StringIntMap reads a map from strings to integers. Entries that
+// are not strictly ordered by their keys are rejected.
+func (d *Decoder) StringIntMap() map[string]int {
+	n := d.Len()
+	if d.err != nil {
+		return nil
+	}
+	m := make(map[string]int, d.capacity(n))
+	prev := ""
+	for i := 0; i < n && d.err == nil; i++ {
+		k := d.String()
+		if i > 0 && k <= prev {
+			d.fail("map")
+			return nil
+		}
+		m[k] = d.Int()
+		prev = k
+	}
+
+	return m
+}
+
+// IntIntMap reads a map from integers to integers. Entries that
+// are not strictly ordered by their keys are rejected.
+func (d *Decoder) IntIntMap() map[int]int {
+	n := d.Len()
+	if d.err != nil {
+		return nil
+	}
+	m := make(map[int]int, d.capacity(n))
+	prev := 0
+	for i := 0; i < n && d.err == nil; i++ {
+		k := d.Int()
+		if i > 0 && k <= prev {
+			d.fail("map")
+			return nil
+		}
+		m[k] = d.Int()
+		prev = k
+	}
+
+	return m
+}
+
+// BigInt reads a big integer, which might be nil.
+func (d *Decoder) BigInt() *big.Int {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) == 0 || d.data[0] > 2 {
+		d.fail("big integer")
+		return nil
+	}
+	sign := d.data[0]
+	d.data = d.data[1:]
+	if sign == 0 {
+		return nil
+	}
+	b := d.Bytes()
+	if d.err != nil {
+		return nil
+	}
+	// the encoding is canonical, hence leading zeros and
+	// a negative zero are rejected
+	if (len(b) > 0 && b[0] == 0) || (sign == 2 && len(b) == 0) {
+		d.fail("big integer")
+		return nil
+	}
+	x := new(big.Int).SetBytes(b)
+	if sign == 2 {
+		x.Neg(x)
+	}
+
+	return x
+}
+
+// BigInts reads a slice of big integers.
+func (d *Decoder) BigInts() []*big.Int {
+	n := d.Len()
+	if d.err != nil {
+		return nil
+	}
+	s := make([]*big.Int, 0, d.capacity(n))
+	for i := 0; i < n && d.err == nil; i++ {
+		s = append(s, d.BigInt())
+	}
+
+	return s
+}
+
+// BigFloat reads a big float, which might be nil.
+func (d *Decoder) BigFloat() *big.Float {
+	b := d.Bytes()
+	if d.err != nil || len(b) == 0 {
+		return nil
+	}
+	x := new(big.Float)
+	if err := x.GobDecode(b); err != nil {
+		d.fail("big float")
+		return nil
+	}
+
+	return x
+}
+
+// G1 reads an element of the bn256.G1 group. An element written
+// as nil is rejected, see OptionalG1.
+func (d *Decoder) G1() *bn256.G1 {
+	p := d.OptionalG1()
+	if d.err == nil && p == nil {
+		d.fail("G1 element")
+	}
+
+	return p
+}
+
+// OptionalG1 reads an element of the bn256.G1 group, which might be nil.
+func (d *Decoder) OptionalG1() *bn256.G1 {
+	b := d.Bytes()
+	if d.err != nil || len(b) == 0 {
+		return nil
+	}
+	p := new(bn256.G1)
+	if rest, err := p.Unmarshal(b); err != nil || len(rest) != 0 {
+		d.fail("G1 element")
+		return nil
+	}
+
+	return p
+}
+
+// G2 reads an element of the bn256.G2 group. An element written
+// as nil is rejected, see OptionalG2.
+func (d *Decoder) G2() *bn256.G2 {
+	p := d.OptionalG2()
+	if d.err == nil && p == nil {
+		d.fail("G2 element")
+	}
+
+	return p
+}
+
+// OptionalG2 reads an element of the bn256.G2 group, which might be nil.
+func (d *Decoder) OptionalG2() *bn256.G2 {
+	b := d.Bytes()
+	if d.err != nil || len(b) == 0 {
+		return nil
+	}
+	p := new(bn256.G2)
+	if rest, err := p.Unmarshal(b); err != nil || len(rest) != 0 {
+		d.fail("G2 element")
+		return nil
+	}
+
+	return p
+}
+
+// GT reads an element of the bn256.GT group. An element written
+// as nil is rejected, see OptionalGT.
+func (d *Decoder) GT() *bn256.GT {
+	p := d.OptionalGT()
+	if d.err == nil && p == nil {
+		d.fail("GT element")
+	}
+
+	return p
+}
+
+// OptionalGT reads an element of the bn256.GT group, which might be nil.
+func (d *Decoder) OptionalGT() *bn256.GT {
+	b := d.Bytes()
+	if d.err != nil || len(b) == 0 {
+		return nil
+	}
+	p := new(bn256.GT)
+	if rest, err := p.Unmarshal(b); err != nil || len(rest) != 0 {
+		d.fail("GT element")
+		return nil
+	}
+
+	return p
+}
+
+// Value reads a nested object into u. It returns false if the
+// value was encoded as absent, in which case u is left untouched.
+func (d *Decoder) Value(u encoding.BinaryUnmarshaler) bool {
+	b := d.Bytes()
+	if d.err != nil || len(b) == 0 {
+		return false
+	}
+	if err := u.UnmarshalBinary(b); err != nil {
+		d.err = err
+		return false
+	}
+
+	return true
+}
+
+// Failed reports whether an error occurred while decoding.
+func (d *Decoder) Failed() bool {
+	return d.err != nil
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package serial
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/bn256"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderDecoder(t *testing.T) {
+	f := big.NewFloat(3.25)
+	g1 := new(bn256.G1).ScalarBaseMult(big.NewInt(5))
+	g2 := new(bn256.G2).ScalarBaseMult(big.NewInt(6))
+	gt := new(bn256.GT).ScalarBaseMult(big.NewInt(7))
+
+	e := NewEncoder("test")
+	e.Int(-42)
+	e.Len(7)
+	e.Bool(true)
+	e.Bytes([]byte{1, 2, 3})
+	e.Strings([]string{"a", "bc"})
+	e.Ints([]int{-1, 0, 1})
+	e.StringIntMap(map[string]int{"y": 2, "x": 1})
+	e.IntIntMap(map[int]int{3: 0, -5: 1})
+	e.BigInts([]*big.Int{big.NewInt(-300), big.NewInt(0), nil, big.NewInt(300)})
+	e.BigFloat(f)
+	e.BigFloat(nil)
+	e.G1(g1)
+	e.G2(g2)
+	e.GT(gt)
+	e.G1(nil)
+	b, err := e.Data()
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+
+	d := NewDecoder(b, "test")
+	assert.Equal(t, -42, d.Int())
+	assert.Equal(t, 7, d.Len())
+	assert.True(t, d.Bool())
+	assert.Equal(t, []byte{1, 2, 3}, d.Bytes())
+	assert.Equal(t, []string{"a", "bc"}, d.Strings())
+	assert.Equal(t, []int{-1, 0, 1}, d.Ints())
+	assert.Equal(t, map[string]int{"x": 1, "y": 2}, d.StringIntMap())
+	assert.Equal(t, map[int]int{-5: 1, 3: 0}, d.IntIntMap())
+	assert.Equal(t, []*big.Int{big.NewInt(-300), big.NewInt(0), nil, big.NewInt(300)}, d.BigInts())
+	assert.Equal(t, 0, f.Cmp(d.BigFloat()))
+	assert.Nil(t, d.BigFloat())
+	assert.Equal(t, g1.String(), d.G1().String())
+	assert.Equal(t, g2.String(), d.G2().String())
+	assert.Equal(t, gt.String(), d.GT().String())
+	assert.Nil(t, d.OptionalG1())
+	if err := d.Finish(); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+}
+
+func TestEncoderDecoder_Identity(t *testing.T) {
+	g1 := new(bn256.G1).ScalarBaseMult(big.NewInt(0))
+	g2 := new(bn256.G2).ScalarBaseMult(big.NewInt(0))
+
+	e := NewEncoder("test")
+	e.G1(g1)
+	e.G2(g2)
+	b, err := e.Data()
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+
+	d := NewDecoder(b, "test")
+	assert.Equal(t, g1.String(), d.G1().String())
+	assert.Equal(t, g2.String(), d.G2().String())
+	if err := d.Finish(); err != nil {
+		t.Fatalf("Error during decoding: %v", err)
+	}
+}
+
+func TestDecoderRejectsMalformed(t *testing.T) {
+	e := NewEncoder("test")
+	e.BigInt(big.NewInt(1))
+	b, err := e.Data()
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+
+	// wrong tag
+	d := NewDecoder(b, "other")
+	d.BigInt()
+	assert.Error(t, d.Finish())
+
+	// unsupported version
+	wrongVersion := append([]byte{Version + 1}, b[1:]...)
+	d = NewDecoder(wrongVersion, "test")
+	d.BigInt()
+	assert.Error(t, d.Finish())
+
+	// trailing bytes
+	d = NewDecoder(append(b, 0), "test")
+	d.BigInt()
+	assert.Error(t, d.Finish())
+
+	// truncated input
+	d = NewDecoder(b[:len(b)-1], "test")
+	d.BigInt()
+	assert.Error(t, d.Finish())
+
+	// big integers with leading zeros are not canonical
+	nonCanonical := append(append([]byte{}, b[:len(b)-2]...), 2, 0, 1)
+	d = NewDecoder(nonCanonical, "test")
+	d.BigInt()
+	assert.Error(t, d.Finish())
+
+	// an optional integer is omitted only if it is 0
+	for _, x := range []int{0, 3} {
+		e = NewEncoder("test")
+		e.Int(1)
+		e.OptionalInt(x)
+		b, err = e.Data()
+		if err != nil {
+			t.Fatalf("Error during encoding: %v", err)
+		}
+		d = NewDecoder(b, "test")
+		assert.Equal(t, 1, d.Int())
+		assert.Equal(t, x, d.OptionalInt())
+		assert.NoError(t, d.Finish())
+	}
+	d = NewDecoder(append(b[:len(b)-1], 0), "test")
+	d.Int()
+	d.OptionalInt()
+	assert.Error(t, d.Finish())
+
+	// map keys must be strictly increasing
+	e = NewEncoder("test")
+	e.Len(2)
+	e.Int(2)
+	e.Int(0)
+	e.Int(1)
+	e.Int(0)
+	b, err = e.Data()
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	d = NewDecoder(b, "test")
+	d.IntIntMap()
+	assert.Error(t, d.Finish())
+
+	// a group element written as nil is only accepted
+	// where it is optional
+	e = NewEncoder("test")
+	e.G1(nil)
+	e.G2(nil)
+	e.GT(nil)
+	b, err = e.Data()
+	if err != nil {
+		t.Fatalf("Error during encoding: %v", err)
+	}
+	d = NewDecoder(b, "test")
+	assert.Nil(t, d.OptionalG1())
+	assert.Nil(t, d.OptionalG2())
+	assert.Nil(t, d.OptionalGT())
+	assert.NoError(t, d.Finish())
+	d = NewDecoder(b, "test")
+	d.G1()
+	assert.Error(t, d.Finish())
+	d = NewDecoder(b, "test")
+	d.OptionalG1()
+	d.G2()
+	assert.Error(t, d.Finish())
+	d = NewDecoder(b, "test")
+	d.OptionalG1()
+	d.OptionalG2()
+	d.GT()
+	assert.Error(t, d.Finish())
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package quadratic
+
+import (
+	"encoding/json"
+
+	"github.com/fentec-project/bn256"
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod/fullysec"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+type quadPubKeyJSON struct {
+	serial.Header
+	Ua     data.VectorG1             `json:"ua"`
+	VB     data.MatrixG2             `json:"vb"`
+	PubIPE *fullysec.PartFHIPEPubKey `json:"pubIPE"`
+}
+
+// MarshalJSON encodes the public key as a JSON object.
+func (k *QuadPubKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(quadPubKeyJSON{
+		Header: serial.NewHeader("quadratic.QuadPubKey"),
+		Ua:     k.Ua,
+		VB:     k.VB,
+		PubIPE: k.PubIPE,
+	})
+}
+
+// UnmarshalJSON decodes the public key encoded with MarshalJSON into k.
+func (k *QuadPubKey) UnmarshalJSON(b []byte) error {
+	var enc quadPubKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("quadratic.QuadPubKey"); err != nil {
+		return err
+	}
+	*k = QuadPubKey{
+		Ua:     enc.Ua,
+		VB:     enc.VB,
+		PubIPE: enc.PubIPE,
+	}
+
+	return nil
+}
+
+type quadSecKeyJSON struct {
+	serial.Header
+	U      data.Matrix               `json:"u"`
+	V      data.Matrix               `json:"v"`
+	SecIPE *fullysec.PartFHIPESecKey `json:"secIPE"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *QuadSecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(quadSecKeyJSON{
+		Header: serial.NewHeader("quadratic.QuadSecKey"),
+		U:      k.U,
+		V:      k.V,
+		SecIPE: k.SecIPE,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *QuadSecKey) UnmarshalJSON(b []byte) error {
+	var enc quadSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("quadratic.QuadSecKey"); err != nil {
+		return err
+	}
+	*k = QuadSecKey{
+		U:      enc.U,
+		V:      enc.V,
+		SecIPE: enc.SecIPE,
+	}
+
+	return nil
+}
+
+type quadCipherJSON struct {
+	serial.Header
+	Cx   data.VectorG1 `json:"cx"`
+	Cy   data.VectorG2 `json:"cy"`
+	CIPE data.VectorG1 `json:"cipe"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *QuadCipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(quadCipherJSON{
+		Header: serial.NewHeader("quadratic.QuadCipher"),
+		Cx:     c.Cx,
+		Cy:     c.Cy,
+		CIPE:   c.CIPE,
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *QuadCipher) UnmarshalJSON(b []byte) error {
+	var enc quadCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("quadratic.QuadCipher"); err != nil {
+		return err
+	}
+	*c = QuadCipher{
+		Cx:   enc.Cx,
+		Cy:   enc.Cy,
+		CIPE: enc.CIPE,
+	}
+
+	return nil
+}
+
+type sgpSecKeyJSON struct {
+	serial.Header
+	S data.Vector `json:"s"`
+	T data.Vector `json:"t"`
+}
+
+// MarshalJSON encodes the secret key as a JSON object.
+func (k *SGPSecKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sgpSecKeyJSON{
+		Header: serial.NewHeader("quadratic.SGPSecKey"),
+		S:      k.S,
+		T:      k.T,
+	})
+}
+
+// UnmarshalJSON decodes the secret key encoded with MarshalJSON into k.
+func (k *SGPSecKey) UnmarshalJSON(b []byte) error {
+	var enc sgpSecKeyJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("quadratic.SGPSecKey"); err != nil {
+		return err
+	}
+	*k = SGPSecKey{
+		S: enc.S,
+		T: enc.T,
+	}
+
+	return nil
+}
+
+type sgpCipherJSON struct {
+	serial.Header
+	G1MulGamma *serial.G1      `json:"g1MulGamma"`
+	AMulG1     []data.VectorG1 `json:"aMulG1"`
+	BMulG2     []data.VectorG2 `json:"bMulG2"`
+}
+
+// MarshalJSON encodes the ciphertext as a JSON object.
+func (c *SGPCipher) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sgpCipherJSON{
+		Header:     serial.NewHeader("quadratic.SGPCipher"),
+		G1MulGamma: (*serial.G1)(c.G1MulGamma),
+		AMulG1:     c.AMulG1,
+		BMulG2:     c.BMulG2,
+	})
+}
+
+// UnmarshalJSON decodes the ciphertext encoded with MarshalJSON into c.
+func (c *SGPCipher) UnmarshalJSON(b []byte) error {
+	var enc sgpCipherJSON
+	if err := json.Unmarshal(b, &enc); err != nil {
+		return err
+	}
+	if err := enc.Check("quadratic.SGPCipher"); err != nil {
+		return err
+	}
+	*c = SGPCipher{
+		G1MulGamma: (*bn256.G1)(enc.G1MulGamma),
+		AMulG1:     enc.AMulG1,
+		BMulG2:     enc.BMulG2,
+	}
+
+	return nil
+}
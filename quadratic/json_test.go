@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package quadratic_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/quadratic"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuad_JSON(t *testing.T) {
+	n := 2
+	m := 2
+	bound := big.NewInt(50)
+	q, err := quadratic.NewQuad(n, m, bound)
+	if err != nil {
+		t.Fatalf("error when creating scheme: %v", err)
+	}
+	pubKey, secKey, err := q.GenerateKeys()
+	if err != nil {
+		t.Fatalf("error when generating keys: %v", err)
+	}
+
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+	x, err := data.NewRandomVector(n, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random vector: %v", err)
+	}
+	y, err := data.NewRandomVector(m, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random vector: %v", err)
+	}
+	f, err := data.NewRandomMatrix(n, m, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random matrix: %v", err)
+	}
+
+	pubKeyJSON, err := json.Marshal(pubKey)
+	if err != nil {
+		t.Fatalf("error when encoding: %v", err)
+	}
+	var decPubKey quadratic.QuadPubKey
+	if err := json.Unmarshal(pubKeyJSON, &decPubKey); err != nil {
+		t.Fatalf("error when decoding: %v", err)
+	}
+	c, err := q.Encrypt(x, y, &decPubKey)
+	if err != nil {
+		t.Fatalf("error when encrypting: %v", err)
+	}
+
+	secKeyJSON, err := json.Marshal(secKey)
+	if err != nil {
+		t.Fatalf("error when encoding: %v", err)
+	}
+	var decSecKey quadratic.QuadSecKey
+	if err := json.Unmarshal(secKeyJSON, &decSecKey); err != nil {
+		t.Fatalf("error when decoding: %v", err)
+	}
+	assert.Equal(t, secKey, &decSecKey)
+	feKey, err := q.DeriveKey(&decSecKey, f)
+	if err != nil {
+		t.Fatalf("error when deriving key: %v", err)
+	}
+
+	cJSON, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("error when encoding: %v", err)
+	}
+	var decC quadratic.QuadCipher
+	if err := json.Unmarshal(cJSON, &decC); err != nil {
+		t.Fatalf("error when decoding: %v", err)
+	}
+	dec, err := q.Decrypt(&decC, feKey, f)
+	if err != nil {
+		t.Fatalf("error when decrypting: %v", err)
+	}
+	check, err := f.MulXMatY(x, y)
+	if err != nil {
+		t.Fatalf("error when computing x*F*y: %v", err)
+	}
+	assert.Equal(t, check, dec)
+}
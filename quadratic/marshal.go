@@ -0,0 +1,202 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package quadratic
+
+import (
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/innerprod/fullysec"
+	"github.com/fentec-project/gofe/internal/serial"
+)
+
+// MarshalBinary encodes the parameters into a canonical binary form.
+// The parameters of the underlying partially function hiding scheme
+// are encoded as well.
+func (p *QuadParams) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("quadratic.QuadParams")
+	var ipeParams *fullysec.PartFHIPEParams
+	if p.PartFHIPE != nil {
+		ipeParams = p.PartFHIPE.Params
+	}
+	e.Value(ipeParams, ipeParams != nil)
+	e.Int(p.N)
+	e.Int(p.M)
+	e.BigInt(p.Bound)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the parameters encoded with MarshalBinary into p.
+func (p *QuadParams) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "quadratic.QuadParams")
+	var params QuadParams
+	ipeParams := new(fullysec.PartFHIPEParams)
+	if d.Value(ipeParams) {
+		params.PartFHIPE = fullysec.NewPartFHIPEFromParams(ipeParams)
+	}
+	params.N = d.Int()
+	params.M = d.Int()
+	params.Bound = d.BigInt()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*p = params
+
+	return nil
+}
+
+// MarshalBinary encodes the public key into a canonical binary form.
+func (k *QuadPubKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("quadratic.QuadPubKey")
+	e.Value(k.Ua, k.Ua != nil)
+	e.Value(k.VB, k.VB != nil)
+	e.Value(k.PubIPE, k.PubIPE != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the public key encoded with MarshalBinary into k.
+func (k *QuadPubKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "quadratic.QuadPubKey")
+	var key QuadPubKey
+	d.Value(&key.Ua)
+	d.Value(&key.VB)
+	key.PubIPE = new(fullysec.PartFHIPEPubKey)
+	if !d.Value(key.PubIPE) {
+		key.PubIPE = nil
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *QuadSecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("quadratic.QuadSecKey")
+	e.Value(k.U, k.U != nil)
+	e.Value(k.V, k.V != nil)
+	e.Value(k.SecIPE, k.SecIPE != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *QuadSecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "quadratic.QuadSecKey")
+	var key QuadSecKey
+	d.Value(&key.U)
+	d.Value(&key.V)
+	key.SecIPE = new(fullysec.PartFHIPESecKey)
+	if !d.Value(key.SecIPE) {
+		key.SecIPE = nil
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *QuadCipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("quadratic.QuadCipher")
+	e.Value(c.Cx, c.Cx != nil)
+	e.Value(c.Cy, c.Cy != nil)
+	e.Value(c.CIPE, c.CIPE != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *QuadCipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "quadratic.QuadCipher")
+	var cipher QuadCipher
+	d.Value(&cipher.Cx)
+	d.Value(&cipher.Cy)
+	d.Value(&cipher.CIPE)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
+
+// MarshalBinary encodes the secret key into a canonical binary form.
+func (k *SGPSecKey) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("quadratic.SGPSecKey")
+	e.Value(k.S, k.S != nil)
+	e.Value(k.T, k.T != nil)
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the secret key encoded with MarshalBinary into k.
+func (k *SGPSecKey) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "quadratic.SGPSecKey")
+	var key SGPSecKey
+	d.Value(&key.S)
+	d.Value(&key.T)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = key
+
+	return nil
+}
+
+// MarshalBinary encodes the ciphertext into a canonical binary form.
+func (c *SGPCipher) MarshalBinary() ([]byte, error) {
+	e := serial.NewEncoder("quadratic.SGPCipher")
+	e.G1(c.G1MulGamma)
+	e.Len(len(c.AMulG1))
+	for _, v := range c.AMulG1 {
+		e.Value(v, v != nil)
+	}
+	e.Len(len(c.BMulG2))
+	for _, v := range c.BMulG2 {
+		e.Value(v, v != nil)
+	}
+
+	return e.Data()
+}
+
+// UnmarshalBinary decodes the ciphertext encoded with MarshalBinary into c.
+func (c *SGPCipher) UnmarshalBinary(b []byte) error {
+	d := serial.NewDecoder(b, "quadratic.SGPCipher")
+	var cipher SGPCipher
+	cipher.G1MulGamma = d.G1()
+	n := d.Len()
+	cipher.AMulG1 = make([]data.VectorG1, n)
+	for i := 0; i < n && !d.Failed(); i++ {
+		d.Value(&cipher.AMulG1[i])
+	}
+	n = d.Len()
+	cipher.BMulG2 = make([]data.VectorG2, n)
+	for i := 0; i < n && !d.Failed(); i++ {
+		d.Value(&cipher.BMulG2[i])
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*c = cipher
+
+	return nil
+}
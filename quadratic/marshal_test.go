@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2018 XLAB d.o.o
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package quadratic_test
+
+import (
+	"encoding"
+	"math/big"
+	"testing"
+
+	"github.com/fentec-project/gofe/data"
+	"github.com/fentec-project/gofe/quadratic"
+	"github.com/fentec-project/gofe/sample"
+	"github.com/stretchr/testify/assert"
+)
+
+// roundTrip encodes in and decodes the result into out.
+func roundTrip(t *testing.T, in encoding.BinaryMarshaler, out encoding.BinaryUnmarshaler) {
+	b, err := in.MarshalBinary()
+	if err != nil {
+		t.Fatalf("error when marshaling: %v", err)
+	}
+	if err := out.UnmarshalBinary(b); err != nil {
+		t.Fatalf("error when unmarshaling: %v", err)
+	}
+}
+
+func TestQuad_MarshalBinary(t *testing.T) {
+	n := 3
+	m := 2
+	bound := big.NewInt(100)
+	q, err := quadratic.NewQuad(n, m, bound)
+	if err != nil {
+		t.Fatalf("error when creating scheme: %v", err)
+	}
+	pubKey, secKey, err := q.GenerateKeys()
+	if err != nil {
+		t.Fatalf("error when generating keys: %v", err)
+	}
+
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+	x, err := data.NewRandomVector(n, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random vector: %v", err)
+	}
+	y, err := data.NewRandomVector(m, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random vector: %v", err)
+	}
+	f, err := data.NewRandomMatrix(n, m, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random matrix: %v", err)
+	}
+
+	// the encryptor only receives encoded parameters and public key
+	params := new(quadratic.QuadParams)
+	roundTrip(t, q.Params, params)
+	decodedPubKey := new(quadratic.QuadPubKey)
+	roundTrip(t, pubKey, decodedPubKey)
+	c, err := quadratic.NewQuadFromParams(params).Encrypt(x, y, decodedPubKey)
+	if err != nil {
+		t.Fatalf("error when encrypting: %v", err)
+	}
+
+	// the key is derived from a decoded secret key
+	decodedSecKey := new(quadratic.QuadSecKey)
+	roundTrip(t, secKey, decodedSecKey)
+	feKey, err := q.DeriveKey(decodedSecKey, f)
+	if err != nil {
+		t.Fatalf("error when deriving key: %v", err)
+	}
+
+	decodedCipher := new(quadratic.QuadCipher)
+	roundTrip(t, c, decodedCipher)
+	decodedKey := new(data.VectorG2)
+	roundTrip(t, feKey, decodedKey)
+	dec, err := q.Decrypt(decodedCipher, *decodedKey, f)
+	if err != nil {
+		t.Fatalf("error when decrypting: %v", err)
+	}
+	check, err := f.MulXMatY(x, y)
+	if err != nil {
+		t.Fatalf("error when computing x*F*y: %v", err)
+	}
+	assert.Equal(t, check, dec)
+}
+
+func TestSGP_MarshalBinary(t *testing.T) {
+	n := 2
+	bound := big.NewInt(10)
+	sgp := quadratic.NewSGP(n, bound)
+	msk, err := sgp.GenerateMasterKey()
+	if err != nil {
+		t.Fatalf("error when generating master keys: %v", err)
+	}
+
+	sampler := sample.NewUniformRange(new(big.Int).Neg(bound), bound)
+	x, err := data.NewRandomVector(n, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random vector: %v", err)
+	}
+	y, err := data.NewRandomVector(n, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random vector: %v", err)
+	}
+	f, err := data.NewRandomMatrix(n, n, sampler)
+	if err != nil {
+		t.Fatalf("error when generating random matrix: %v", err)
+	}
+
+	decodedMsk := new(quadratic.SGPSecKey)
+	roundTrip(t, msk, decodedMsk)
+	assert.Equal(t, msk, decodedMsk)
+	c, err := sgp.Encrypt(x, y, decodedMsk)
+	if err != nil {
+		t.Fatalf("error when encrypting: %v", err)
+	}
+	key, err := sgp.DeriveKey(msk, f)
+	if err != nil {
+		t.Fatalf("error when deriving key: %v", err)
+	}
+
+	decodedCipher := new(quadratic.SGPCipher)
+	roundTrip(t, c, decodedCipher)
+	dec, err := sgp.Decrypt(decodedCipher, key, f)
+	if err != nil {
+		t.Fatalf("error when decrypting: %v", err)
+	}
+	check, err := f.MulXMatY(x, y)
+	if err != nil {
+		t.Fatalf("error when computing x*F*y: %v", err)
+	}
+	assert.Equal(t, check, dec)
+}
@@ -17,6 +17,7 @@
 package quadratic
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -286,6 +287,12 @@ func (q *Quad) DeriveKey(secKey *QuadSecKey, F data.Matrix) (data.VectorG2, erro
 // Decrypt decrypts the ciphertext c with the derived functional
 // encryption key key in order to obtain function x^T * F * y.
 func (q *Quad) Decrypt(c *QuadCipher, feKey data.VectorG2, F data.Matrix) (*big.Int, error) {
+	return q.DecryptContext(context.Background(), c, feKey, F)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (q *Quad) DecryptContext(ctx context.Context, c *QuadCipher, feKey data.VectorG2, F data.Matrix) (*big.Int, error) {
 	if len(feKey) != q.Params.PartFHIPE.Params.L+4 {
 		return nil, fmt.Errorf("dimensions of the given FE key are incorrect")
 	}
@@ -314,9 +321,9 @@ func (q *Quad) Decrypt(c *QuadCipher, feKey data.VectorG2, F data.Matrix) (*big.
 	// get upper bounds
 	b3 := new(big.Int).Exp(q.Params.Bound, big.NewInt(3), nil)
 	b := new(big.Int).Mul(b3, big.NewInt(int64(q.Params.N*q.Params.M)))
-	calc := dlog.NewCalc().InBN256().WithBound(b).WithNeg()
+	calc := dlog.NewCalc().InBN256().WithNeg()
 
-	res, err := calc.BabyStepGiantStep(dec, new(bn256.GT).ScalarBaseMult(big.NewInt(1)))
+	res, err := calc.SearchContext(ctx, dec, new(bn256.GT).ScalarBaseMult(big.NewInt(1)), b)
 
 	return res, err
 }
@@ -17,6 +17,7 @@
 package quadratic
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/fentec-project/bn256"
@@ -194,6 +195,12 @@ func (q *SGP) DeriveKey(msk *SGPSecKey, F data.Matrix) (*bn256.G2, error) {
 // Decrypt decrypts the ciphertext c with the derived functional
 // encryption key key in order to obtain function x^T * F * y.
 func (q *SGP) Decrypt(c *SGPCipher, key *bn256.G2, F data.Matrix) (*big.Int, error) {
+	return q.DecryptContext(context.Background(), c, key, F)
+}
+
+// DecryptContext is like Decrypt, but aborts the computation of the discrete
+// logarithm and returns the error of ctx as soon as ctx is done.
+func (q *SGP) DecryptContext(ctx context.Context, c *SGPCipher, key *bn256.G2, F data.Matrix) (*big.Int, error) {
 	prod := bn256.Pair(c.G1MulGamma, key)
 
 	for i, row := range F {
@@ -224,5 +231,5 @@ func (q *SGP) Decrypt(c *SGPCipher, key *bn256.G2, F data.Matrix) (*big.Int, err
 	n2 := new(big.Int).Exp(big.NewInt(int64(q.N)), big.NewInt(2), nil)
 	b := new(big.Int).Mul(n2, b3)
 
-	return q.GCalc.WithBound(b).WithNeg().BabyStepGiantStep(prod, g)
+	return q.GCalc.WithNeg().SearchContext(ctx, prod, g, b)
 }